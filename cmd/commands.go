@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/repository"
+	"cosmos-tracker/internal/services"
+	"cosmos-tracker/pkg/db"
+)
+
+const usage = `usage:
+  cosmos-tracker                                          start the collector and API server
+  cosmos-tracker aggregate -from YYYY-MM-DD -to YYYY-MM-DD  re-aggregate daily delegations
+  cosmos-tracker migrate up [-steps N]                    apply pending schema migrations (all by default)
+  cosmos-tracker migrate down [-steps N]                  roll back the latest migrations (one by default)
+  cosmos-tracker migrate status                           list migrations and whether they are applied
+`
+
+// runs a one-off maintenance command instead of the server
+func runCommand(name string, args []string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch name {
+	case "aggregate":
+		err = aggregateCommand(ctx, args)
+	case "migrate":
+		err = migrateCommand(ctx, args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usage)
+		os.Exit(2)
+	}
+
+	if closeErr := db.Close(); closeErr != nil {
+		log.Printf("⚠️ Failed to close database: %v", closeErr)
+	}
+	if err != nil {
+		log.Fatalf("❌ %s failed: %v", name, err)
+	}
+}
+
+// re-aggregates daily delegation rows for an explicit date range
+func aggregateCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("aggregate", flag.ExitOnError)
+	yesterday := time.Now().In(config.AggregationLocation()).AddDate(0, 0, -1).Format(services.DateLayout)
+	from := flags.String("from", yesterday, "first day to re-aggregate (YYYY-MM-DD)")
+	to := flags.String("to", yesterday, "last day to re-aggregate (YYYY-MM-DD)")
+	flags.Parse(args)
+
+	start, end, err := services.ParseDateRange(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	db.ConnectDB()
+	if err := db.CheckSchema(); err != nil {
+		return err
+	}
+
+	result, err := services.NewAggregator(repository.NewGorm(db.DB)).ReaggregateDailyDelegations(ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("✅ Re-aggregated %d days for %d entries (%d daily rows)", result.Days, result.Entries, result.Rows)
+	return nil
+}
+
+// applies, rolls back or lists versioned schema migrations
+func migrateCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	action := args[0]
+
+	flags := flag.NewFlagSet("migrate "+action, flag.ExitOnError)
+	defaultSteps := 0
+	if action == "down" {
+		defaultSteps = 1
+	}
+	steps := flags.Int("steps", defaultSteps, "number of migrations to apply or roll back (0 applies all)")
+	flags.Parse(args[1:])
+
+	db.ConnectDB()
+
+	switch action {
+	case "up":
+		count, err := db.MigrateUp(ctx, *steps)
+		if err != nil {
+			return err
+		}
+		log.Printf("✅ Applied %d migration(s)", count)
+	case "down":
+		if *steps < 1 {
+			return fmt.Errorf("-steps must be at least 1 when rolling back")
+		}
+		count, err := db.MigrateDown(ctx, *steps)
+		if err != nil {
+			return err
+		}
+		log.Printf("✅ Rolled back %d migration(s)", count)
+	case "status":
+		statuses, err := db.MigrationStatuses(db.DB)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			switch {
+			case s.Unknown:
+				state = "applied, unknown to this build"
+			case s.Modified:
+				state = "applied, script changed since"
+			case s.Applied:
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-40s %s  %s\n", s.Version, s.Name, s.Checksum[:12], state)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate action %q\n\n%s", action, usage)
+		os.Exit(2)
+	}
+	return nil
+}
@@ -1,33 +1,157 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"cosmos-tracker/config"
 	api "cosmos-tracker/internal/api"
+	"cosmos-tracker/internal/api/handlers"
+	"cosmos-tracker/internal/repository"
 	"cosmos-tracker/internal/services"
 	"cosmos-tracker/pkg/db"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func main() {
-	// Connect to the database
+	// Maintenance commands such as "aggregate" run once instead of starting the server
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
+	// Root context is cancelled on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Connect to the database and refuse to run against a schema this build doesn't match
 	db.ConnectDB()
+	if err := prepareSchema(ctx); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	app, err := newApp(ctx, db.DB)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	var workers sync.WaitGroup
 
 	// Start delegation tracking in background
-	go services.StartCollector()
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		app.collector.Start(ctx)
+	}()
 
 	// Start daily aggregation in background
-	go services.ScheduleDailyAggregation()
-
-	// Initialize Gin router
-	r := api.SetupRouter()
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		app.aggregator.ScheduleDailyAggregation(ctx)
+	}()
 
 	// Initialize configurations
 	server := config.ServerConfig()
 	log.Println("🚀 Server starting on", server)
 
-	// Use Gin's Run method to start the server
-	if err := r.Run(server); err != nil {
+	srv := &http.Server{
+		Addr:    server,
+		Handler: app.router,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
 		log.Fatal("❌ Error starting server:", err)
+	case <-ctx.Done():
+	}
+
+	// Drain in-flight requests and background work within the deadline
+	timeout := config.ShutdownTimeout()
+	log.Printf("🛑 Shutdown signal received, draining for up to %v...", timeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️ HTTP server shutdown: %v", err)
 	}
+
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("✅ Background workers stopped")
+	case <-shutdownCtx.Done():
+		log.Println("⚠️ Shutdown deadline exceeded, in-flight work was cancelled")
+	}
+
+	if err := db.Close(); err != nil {
+		log.Printf("⚠️ Failed to close database: %v", err)
+	}
+
+	log.Println("👋 Shutdown complete")
+}
+
+// app is everything the server runs: the collector, the aggregation scheduler and the API router
+type app struct {
+	collector  *services.Collector
+	aggregator *services.Aggregator
+	router     *gin.Engine
+}
+
+// applies pending migrations when configured to, then verifies the schema matches this build
+func prepareSchema(ctx context.Context) error {
+	if db.MigrateOnStart() {
+		applied, err := db.MigrateUp(ctx, 0)
+		if err != nil {
+			return err
+		}
+		log.Printf("🗃️ Applied %d migration(s) at startup", applied)
+	}
+	return db.CheckSchema()
+}
+
+// wires repositories, services and handlers to a database
+func newApp(ctx context.Context, database *gorm.DB) (*app, error) {
+	repos := repository.NewGorm(database)
+	aggregator := services.NewAggregator(repos)
+
+	// Snapshots can't be written for a month without a partition, so create them before collecting
+	if err := aggregator.EnsureHourlyPartitions(ctx); err != nil {
+		return nil, err
+	}
+
+	return &app{
+		collector:  services.NewCollector(repos),
+		aggregator: aggregator,
+		router: api.SetupRouter(api.Handlers{
+			Delegations:   handlers.NewDelegationHandler(services.NewDelegationService(repos.Delegations)),
+			Watchlist:     handlers.NewWatchlistHandler(services.NewWatchlistService(repos.Watchlist)),
+			Unbondings:    handlers.NewUnbondingHandler(services.NewUnbondingService(repos.Unbondings)),
+			Redelegations: handlers.NewRedelegationHandler(services.NewRedelegationService(repos.Redelegations)),
+			Validators:    handlers.NewValidatorHandler(services.NewValidatorService(repos.Validators)),
+			Health:        handlers.NewHealthHandler(services.NewHealthService(repos.Health)),
+			Admin:         handlers.NewAdminHandler(aggregator, services.NewMigrationService(repos.Migrations), config.AdminConfig().Token),
+		}),
+	}, nil
 }
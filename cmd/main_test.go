@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func TestServerBootsOnInMemorySQLite(t *testing.T) {
+	t.Setenv("DB_DRIVER", db.DriverSQLite)
+	t.Setenv("SQLITE_PATH", ":memory:")
+	t.Setenv("MIGRATE_ON_START", "")
+	t.Setenv("ADMIN_TOKEN", "s3cret")
+	ctx := context.Background()
+
+	database, err := db.Open()
+	require.NoError(t, err)
+	database.Logger = logger.Discard
+	previous := db.DB
+	db.DB = database
+	t.Cleanup(func() {
+		db.DB = previous
+		if sqlDB, err := database.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	// A fresh in-memory database is migrated without MIGRATE_ON_START
+	require.True(t, db.MigrateOnStart())
+	require.NoError(t, prepareSchema(ctx))
+
+	app, err := newApp(ctx, database)
+	require.NoError(t, err)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		app.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodPost, "/api/v1/watchlist", `{"validator_address":"cosmosvaloper1watched"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = serve(http.MethodGet, "/api/v1/watchlist", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var entries []dto.WatchlistEntry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "cosmoshub-4", entries[0].ChainID)
+
+	w = serve(http.MethodGet, "/api/v1/validators/cosmosvaloper1watched/delegations/hourly", "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = serve(http.MethodGet, "/api/v1/admin/migrations", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var migrations struct {
+		Data dto.MigrationsDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &migrations))
+	assert.True(t, migrations.Data.UpToDate)
+}
+
+func TestMigrateOnStartNeedsOptInForFiles(t *testing.T) {
+	t.Setenv("DB_DRIVER", db.DriverSQLite)
+	t.Setenv("SQLITE_PATH", "tracker.db")
+	t.Setenv("MIGRATE_ON_START", "")
+	assert.False(t, db.MigrateOnStart())
+
+	t.Setenv("MIGRATE_ON_START", "true")
+	assert.True(t, db.MigrateOnStart())
+}
@@ -0,0 +1,17 @@
+package config
+
+import "os"
+
+// holds settings for the maintenance endpoints
+type AdminConfiguration struct {
+	Token                string // bearer token required by every admin endpoint; empty disables them
+	MaxReaggregationDays int    // longest date range a single re-aggregation may cover
+}
+
+// reads admin settings from the environment with sensible defaults
+func AdminConfig() AdminConfiguration {
+	return AdminConfiguration{
+		Token:                os.Getenv("ADMIN_TOKEN"),
+		MaxReaggregationDays: envInt("REAGGREGATION_MAX_DAYS", 31),
+	}
+}
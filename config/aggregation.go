@@ -0,0 +1,33 @@
+package config
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+	_ "time/tzdata" // containers often ship without a zoneinfo database
+)
+
+var (
+	aggregationLocation *time.Location
+	aggregationOnce     sync.Once
+)
+
+// returns the timezone whose midnights bound daily aggregation, read from
+// AGGREGATION_TIMEZONE (an IANA name such as "Europe/Berlin", default UTC)
+func AggregationLocation() *time.Location {
+	aggregationOnce.Do(func() {
+		aggregationLocation = time.UTC
+		name := os.Getenv("AGGREGATION_TIMEZONE")
+		if name == "" {
+			return
+		}
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			log.Printf("⚠️ Invalid AGGREGATION_TIMEZONE %q, using UTC: %v", name, err)
+			return
+		}
+		aggregationLocation = location
+	})
+	return aggregationLocation
+}
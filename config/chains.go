@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// describes a Cosmos SDK chain the tracker can collect from
+type ChainConfig struct {
+	ChainID         string   `json:"chain_id"`
+	Name            string   `json:"name"`
+	AccountPrefix   string   `json:"bech32_account_prefix"`
+	ValidatorPrefix string   `json:"bech32_validator_prefix"`
+	StakingDenom    string   `json:"staking_denom"`
+	DenomExponent   int      `json:"denom_exponent"`
+	PowerReduction  int64    `json:"power_reduction"` // base units per unit of consensus power
+	LCDEndpoints    []string `json:"lcd_endpoints"`
+}
+
+// power reduction of chains built on the SDK default staking params
+const DefaultPowerReduction int64 = 1_000_000
+
+// chain used for entries and routes that don't specify one
+const DefaultChainID = "cosmoshub-4"
+
+// built-in registry of supported chains
+var defaultChains = []ChainConfig{
+	{
+		ChainID:         "cosmoshub-4",
+		Name:            "cosmoshub",
+		AccountPrefix:   "cosmos",
+		ValidatorPrefix: "cosmosvaloper",
+		StakingDenom:    "uatom",
+		DenomExponent:   6,
+		PowerReduction:  DefaultPowerReduction,
+		LCDEndpoints:    []string{"https://cosmos-api.polkachu.com"},
+	},
+	{
+		ChainID:         "osmosis-1",
+		Name:            "osmosis",
+		AccountPrefix:   "osmo",
+		ValidatorPrefix: "osmovaloper",
+		StakingDenom:    "uosmo",
+		DenomExponent:   6,
+		PowerReduction:  DefaultPowerReduction,
+		LCDEndpoints:    []string{"https://osmosis-api.polkachu.com"},
+	},
+	{
+		ChainID:         "juno-1",
+		Name:            "juno",
+		AccountPrefix:   "juno",
+		ValidatorPrefix: "junovaloper",
+		StakingDenom:    "ujuno",
+		DenomExponent:   6,
+		PowerReduction:  DefaultPowerReduction,
+		LCDEndpoints:    []string{"https://juno-api.polkachu.com"},
+	},
+	{
+		ChainID:         "akashnet-2",
+		Name:            "akash",
+		AccountPrefix:   "akash",
+		ValidatorPrefix: "akashvaloper",
+		StakingDenom:    "uakt",
+		DenomExponent:   6,
+		PowerReduction:  DefaultPowerReduction,
+		LCDEndpoints:    []string{"https://akash-api.polkachu.com"},
+	},
+}
+
+var (
+	chainRegistry map[string]ChainConfig
+	chainOnce     sync.Once
+)
+
+// builds the registry, applying <NAME>_LCD_ENDPOINTS overrides from the environment
+func loadChains() {
+	chainRegistry = make(map[string]ChainConfig, len(defaultChains))
+	for _, chain := range defaultChains {
+		envKey := strings.ToUpper(chain.Name) + "_LCD_ENDPOINTS"
+		if endpoints := splitList(os.Getenv(envKey)); len(endpoints) > 0 {
+			chain.LCDEndpoints = endpoints
+		}
+		chainRegistry[chain.ChainID] = chain
+	}
+}
+
+// returns the chain's power reduction, falling back to the SDK default when unset
+func (c ChainConfig) ConsensusPowerReduction() int64 {
+	if c.PowerReduction > 0 {
+		return c.PowerReduction
+	}
+	return DefaultPowerReduction
+}
+
+// splits a comma separated env value, dropping blanks and trailing slashes
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimRight(strings.TrimSpace(item), "/")
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// returns every registered chain ordered by chain ID
+func Chains() []ChainConfig {
+	chainOnce.Do(loadChains)
+
+	chains := make([]ChainConfig, 0, len(chainRegistry))
+	for _, chain := range chainRegistry {
+		chains = append(chains, chain)
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i].ChainID < chains[j].ChainID })
+	return chains
+}
+
+// looks up a chain by chain ID or registry name
+func GetChain(idOrName string) (ChainConfig, bool) {
+	chainOnce.Do(loadChains)
+
+	if chain, ok := chainRegistry[idOrName]; ok {
+		return chain, true
+	}
+	for _, chain := range chainRegistry {
+		if strings.EqualFold(chain.Name, idOrName) {
+			return chain, true
+		}
+	}
+	return ChainConfig{}, false
+}
+
+// finds the chain whose validator prefix matches the given operator address
+func ChainForValidator(validatorAddress string) (ChainConfig, bool) {
+	for _, chain := range Chains() {
+		if chain.OwnsValidator(validatorAddress) {
+			return chain, true
+		}
+	}
+	return ChainConfig{}, false
+}
+
+// reports whether the operator address uses this chain's bech32 validator prefix
+func (c ChainConfig) OwnsValidator(validatorAddress string) bool {
+	return strings.HasPrefix(validatorAddress, c.ValidatorPrefix+"1")
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"log"
+	"os"
+)
+
+// snapshot storage modes selected by SNAPSHOT_STORAGE_MODE
+const (
+	StorageModeFull    = "full"    // one row per delegator every run
+	StorageModeChanges = "changes" // rows only for delegators whose amount or shares changed
+)
+
+// holds tuning knobs for the delegation collector
+type CollectorConfiguration struct {
+	Concurrency       int     // number of watchlist entries collected in parallel
+	RequestsPerSecond float64 // sustained request rate allowed per upstream host
+	Burst             int     // requests allowed in a burst per upstream host
+	StorageMode       string  // StorageModeFull or StorageModeChanges
+}
+
+// reads collector settings from the environment with sensible defaults
+func CollectorConfig() CollectorConfiguration {
+	return CollectorConfiguration{
+		Concurrency:       envInt("COLLECTOR_CONCURRENCY", 8),
+		RequestsPerSecond: envFloat("COLLECTOR_RATE_LIMIT", 5),
+		Burst:             envInt("COLLECTOR_RATE_BURST", 10),
+		StorageMode:       storageMode(),
+	}
+}
+
+// reports whether runs skip rows for delegators that did not change
+func (c CollectorConfiguration) ChangesOnly() bool {
+	return c.StorageMode == StorageModeChanges
+}
+
+// reads SNAPSHOT_STORAGE_MODE, falling back to full storage
+func storageMode() string {
+	switch value := os.Getenv("SNAPSHOT_STORAGE_MODE"); value {
+	case "", StorageModeFull:
+		return StorageModeFull
+	case StorageModeChanges:
+		return StorageModeChanges
+	default:
+		log.Printf("⚠️ Invalid SNAPSHOT_STORAGE_MODE=%q, using %s", value, StorageModeFull)
+		return StorageModeFull
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// reads a positive integer env value, falling back to def
+func envInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 1 {
+		log.Printf("⚠️ Invalid %s=%q, using default %d", key, value, def)
+		return def
+	}
+	return parsed
+}
+
+// reads a positive float env value, falling back to def
+func envFloat(key string, def float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed <= 0 {
+		log.Printf("⚠️ Invalid %s=%q, using default %g", key, value, def)
+		return def
+	}
+	return parsed
+}
+
+// reads a positive duration env value such as "30s", falling back to def
+func envDuration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("⚠️ Invalid %s=%q, using default %v", key, value, def)
+		return def
+	}
+	return parsed
+}
@@ -0,0 +1,7 @@
+package config
+
+// returns how many months of hourly_delegations partitions are kept created ahead of the
+// current one, read from HOURLY_PARTITION_MONTHS_AHEAD (default 3); only Postgres partitions
+func HourlyPartitionMonthsAhead() int {
+	return envInt("HOURLY_PARTITION_MONTHS_AHEAD", 3)
+}
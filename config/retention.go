@@ -0,0 +1,15 @@
+package config
+
+// holds how long raw data is kept before only its rollups remain
+type RetentionConfiguration struct {
+	HourlyDays int // days of hourly snapshots and stats to keep; 0 keeps them forever
+	BatchSize  int // rows deleted per statement so pruning never holds long locks
+}
+
+// reads retention settings from the environment; pruning is off unless RETENTION_HOURLY_DAYS is set
+func RetentionConfig() RetentionConfiguration {
+	return RetentionConfiguration{
+		HourlyDays: envInt("RETENTION_HOURLY_DAYS", 0),
+		BatchSize:  envInt("RETENTION_BATCH_SIZE", 5000),
+	}
+}
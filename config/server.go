@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 )
 
 type ServerConfiguration struct {
@@ -24,3 +25,8 @@ func ServerConfig() string {
 	log.Print("Server Running at :", appServer)
 	return appServer
 }
+
+// returns how long in-flight work may drain after SIGINT/SIGTERM
+func ShutdownTimeout() time.Duration {
+	return envDuration("SHUTDOWN_TIMEOUT", 30*time.Second)
+}
@@ -0,0 +1,16 @@
+package routers
+
+import (
+	"cosmos-tracker/internal/api/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRoute registers maintenance endpoints, all behind the admin token
+func AdminRoute(route *gin.Engine, apiVersion string, admin *handlers.AdminHandler) {
+	groupRoutes := route.Group(apiVersion+"/admin", admin.RequireToken)
+
+	groupRoutes.POST("/aggregations/daily", admin.ReaggregateDaily)
+	groupRoutes.GET("/retention/runs", admin.GetRetentionRuns)
+	groupRoutes.GET("/migrations", admin.GetMigrations)
+}
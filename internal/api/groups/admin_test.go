@@ -0,0 +1,30 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cosmos-tracker/internal/api/handlers"
+	"cosmos-tracker/internal/repository"
+	"cosmos-tracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEveryAdminRouteNeedsTheToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	route := gin.New()
+	repos := repository.NewMemoryStore().Repositories()
+	AdminRoute(route, "/api/v1", handlers.NewAdminHandler(services.NewAggregator(repos), services.NewMigrationService(repos.Migrations), "s3cret"))
+
+	for _, r := range route.Routes() {
+		req := httptest.NewRequest(r.Method, r.Path, nil)
+		req.Header.Set("Authorization", "Bearer wrong")
+		w := httptest.NewRecorder()
+		route.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "%s %s", r.Method, r.Path)
+	}
+	assert.Len(t, route.Routes(), 3)
+}
@@ -0,0 +1,15 @@
+package routers
+
+import (
+	"cosmos-tracker/internal/api/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChainRoute registers chain registry endpoints
+func ChainRoute(route *gin.Engine, apiVersion string) {
+	groupRoutes := route.Group(apiVersion)
+
+	groupRoutes.GET("/chains", handlers.ListChains)
+	groupRoutes.GET("/chains/:chain", handlers.GetChain)
+}
@@ -6,10 +6,24 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func DelegationRoute(route *gin.Engine, apiVersion string) {
+func DelegationRoute(route *gin.Engine, apiVersion string, delegations *handlers.DelegationHandler) {
 	groupRoutes := route.Group(apiVersion)
 
-	groupRoutes.GET("/validators/:validator/delegations/hourly", handlers.GetHourlyDelegations)
-	groupRoutes.GET("/validators/:validator/delegations/daily", handlers.GetDailyDelegations)
-	groupRoutes.GET("/validators/:validator/delegator/:delegator/history", handlers.GetDelegatorHistory)
+	groupRoutes.GET("/validators/:validator/delegations/hourly", delegations.GetHourlyDelegations)
+	groupRoutes.GET("/validators/:validator/delegations/daily", delegations.GetDailyDelegations)
+	groupRoutes.GET("/validators/:validator/delegations/weekly", delegations.GetWeeklyDelegations)
+	groupRoutes.GET("/validators/:validator/delegations/monthly", delegations.GetMonthlyDelegations)
+	groupRoutes.GET("/validators/:validator/delegator/:delegator/history", delegations.GetDelegatorHistory)
+	groupRoutes.GET("/validators/:validator/delegators", delegations.GetCurrentDelegators)
+	groupRoutes.GET("/validators/:validator/delegators/:delegator", delegations.GetCurrentDelegation)
+
+	// Chain-scoped variants
+	chainRoutes := groupRoutes.Group("/chains/:chain")
+	chainRoutes.GET("/validators/:validator/delegations/hourly", delegations.GetHourlyDelegations)
+	chainRoutes.GET("/validators/:validator/delegations/daily", delegations.GetDailyDelegations)
+	chainRoutes.GET("/validators/:validator/delegations/weekly", delegations.GetWeeklyDelegations)
+	chainRoutes.GET("/validators/:validator/delegations/monthly", delegations.GetMonthlyDelegations)
+	chainRoutes.GET("/validators/:validator/delegator/:delegator/history", delegations.GetDelegatorHistory)
+	chainRoutes.GET("/validators/:validator/delegators", delegations.GetCurrentDelegators)
+	chainRoutes.GET("/validators/:validator/delegators/:delegator", delegations.GetCurrentDelegation)
 }
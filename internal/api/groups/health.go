@@ -7,10 +7,11 @@ import (
 )
 
 // HealthRoute registers health check endpoints
-func HealthRoute(route *gin.Engine, apiVersion string) {
+func HealthRoute(route *gin.Engine, apiVersion string, health *handlers.HealthHandler) {
 	healthGroup := route.Group(apiVersion)
 
 	// Basic system health
-	healthGroup.GET("/health", handlers.HealthCheck)
-	healthGroup.GET("/health/data", handlers.DataHealth)
+	healthGroup.GET("/health", health.HealthCheck)
+	healthGroup.GET("/health/data", health.DataHealth)
+	healthGroup.GET("/health/endpoints", health.EndpointHealth)
 }
@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"cosmos-tracker/internal/api/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RedelegationRoute registers redelegation endpoints
+func RedelegationRoute(route *gin.Engine, apiVersion string, redelegations *handlers.RedelegationHandler) {
+	groupRoutes := route.Group(apiVersion)
+
+	groupRoutes.GET("/validators/:validator/redelegations", redelegations.GetRedelegations)
+	groupRoutes.GET("/validators/:validator/redelegations/summary", redelegations.GetRedelegationSummary)
+
+	// Chain-scoped variants
+	chainRoutes := groupRoutes.Group("/chains/:chain")
+	chainRoutes.GET("/validators/:validator/redelegations", redelegations.GetRedelegations)
+	chainRoutes.GET("/validators/:validator/redelegations/summary", redelegations.GetRedelegationSummary)
+}
@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"cosmos-tracker/internal/api/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UnbondingRoute registers unbonding queue endpoints
+func UnbondingRoute(route *gin.Engine, apiVersion string, unbondings *handlers.UnbondingHandler) {
+	groupRoutes := route.Group(apiVersion)
+
+	groupRoutes.GET("/validators/:validator/unbondings", unbondings.GetUnbondings)
+	groupRoutes.GET("/validators/:validator/unbondings/forecast", unbondings.GetUnbondingForecast)
+
+	// Chain-scoped variants
+	chainRoutes := groupRoutes.Group("/chains/:chain")
+	chainRoutes.GET("/validators/:validator/unbondings", unbondings.GetUnbondings)
+	chainRoutes.GET("/validators/:validator/unbondings/forecast", unbondings.GetUnbondingForecast)
+}
@@ -0,0 +1,24 @@
+package routers
+
+import (
+	"cosmos-tracker/internal/api/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValidatorRoute registers validator metadata endpoints
+func ValidatorRoute(route *gin.Engine, apiVersion string, validators *handlers.ValidatorHandler) {
+	groupRoutes := route.Group(apiVersion)
+
+	groupRoutes.GET("/validators/:validator/snapshots", validators.GetValidatorSnapshots)
+	groupRoutes.GET("/validators/:validator/current", validators.GetCurrentValidator)
+	groupRoutes.GET("/validators/:validator/stats/hourly", validators.GetHourlyValidatorStats)
+	groupRoutes.GET("/validators/:validator/stats/daily", validators.GetDailyValidatorStats)
+
+	// Chain-scoped variants
+	chainRoutes := groupRoutes.Group("/chains/:chain")
+	chainRoutes.GET("/validators/:validator/snapshots", validators.GetValidatorSnapshots)
+	chainRoutes.GET("/validators/:validator/current", validators.GetCurrentValidator)
+	chainRoutes.GET("/validators/:validator/stats/hourly", validators.GetHourlyValidatorStats)
+	chainRoutes.GET("/validators/:validator/stats/daily", validators.GetDailyValidatorStats)
+}
@@ -6,10 +6,13 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func WatchlistRoute(route *gin.Engine, apiVersion string) {
+func WatchlistRoute(route *gin.Engine, apiVersion string, watchlist *handlers.WatchlistHandler) {
 	groupRoutes := route.Group(apiVersion)
 
-	groupRoutes.POST("/watchlist", handlers.AddToWatchlist)
-	groupRoutes.GET("/watchlist", handlers.GetWatchlist)
-	groupRoutes.DELETE("/watchlist/:id", handlers.RemoveFromWatchlist)
+	groupRoutes.POST("/watchlist", watchlist.AddToWatchlist)
+	groupRoutes.GET("/watchlist", watchlist.GetWatchlist)
+	groupRoutes.DELETE("/watchlist/:id", watchlist.RemoveFromWatchlist)
+
+	groupRoutes.POST("/chains/:chain/watchlist", watchlist.AddToWatchlist)
+	groupRoutes.GET("/chains/:chain/watchlist", watchlist.GetWatchlist)
 }
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/services"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler serves maintenance endpoints for aggregation, retention and migrations
+type AdminHandler struct {
+	aggregator *services.Aggregator
+	migrations *services.MigrationService
+	token      string
+}
+
+// creates an admin handler on top of the aggregator and migration service; mutating endpoints
+// require the token as a bearer token and are disabled when it is empty
+func NewAdminHandler(aggregator *services.Aggregator, migrations *services.MigrationService, token string) *AdminHandler {
+	return &AdminHandler{aggregator: aggregator, migrations: migrations, token: token}
+}
+
+// rejects requests that don't carry the admin token
+func (h *AdminHandler) RequireToken(c *gin.Context) {
+	if h.token == "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin endpoints are disabled; set ADMIN_TOKEN to enable them"})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte("Bearer "+h.token)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing admin token"})
+		return
+	}
+	c.Next()
+}
+
+// queues a rebuild of daily delegation rows for an explicit date range; safe to repeat
+func (h *AdminHandler) ReaggregateDaily(c *gin.Context) {
+	var request dto.ReaggregationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, to, err := services.ParseDateRange(request.From, request.To)
+	if err != nil {
+		respondWithError(c, err, "Invalid date range")
+		return
+	}
+
+	queued, err := h.aggregator.QueueReaggregation(from, to)
+	if err != nil {
+		respondWithError(c, err, "Failed to queue re-aggregation")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.DelegationResponse{Message: "Daily delegation re-aggregation queued", Data: queued})
+}
+
+// lists what the hourly retention job has pruned, newest first
+func (h *AdminHandler) GetRetentionRuns(c *gin.Context) {
+	page, limit := getPaginationParams(c)
+
+	data, total, err := h.aggregator.FetchRetentionRunsWithPagination(c.Request.Context(), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data"})
+		return
+	}
+
+	totalPages := (total + int64(limit) - 1) / int64(limit)
+
+	response := dto.DelegationResponse{
+		Pagination: dto.Pagination{
+			Page:       page,
+			PerPage:    limit,
+			TotalPages: int(totalPages),
+			TotalData:  int(total),
+		},
+		Data: data,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// reports which schema migrations are applied, with the latest migration events
+func (h *AdminHandler) GetMigrations(c *gin.Context) {
+	result, err := h.migrations.FetchMigrations(c.Request.Context(), 50)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read migrations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DelegationResponse{Data: result})
+}
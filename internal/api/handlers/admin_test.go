@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/repository"
+	"cosmos-tracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaggregateDailyNeedsTokenAndRunsInBackground(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("REAGGREGATION_MAX_DAYS", "7")
+
+	// Queueing only validates the range, so nothing is stored
+	repos := repository.NewMemoryStore().Repositories()
+	route := func(token string) *gin.Engine {
+		admin := NewAdminHandler(services.NewAggregator(repos), services.NewMigrationService(repos.Migrations), token)
+		r := gin.New()
+		r.POST("/admin/aggregations/daily", admin.RequireToken, admin.ReaggregateDaily)
+		return r
+	}
+	post := func(r *gin.Engine, authorization string, from, to time.Time) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"from": %q, "to": %q}`, from.Format(services.DateLayout), to.Format(services.DateLayout))
+		req := httptest.NewRequest(http.MethodPost, "/admin/aggregations/daily", strings.NewReader(body))
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	yesterday := time.Now().AddDate(0, 0, -1)
+	from := yesterday.AddDate(0, 0, -2)
+
+	// Without a configured token the endpoint is disabled
+	assert.Equal(t, http.StatusForbidden, post(route(""), "Bearer ", from, yesterday).Code)
+
+	r := route("s3cret")
+	assert.Equal(t, http.StatusUnauthorized, post(r, "", from, yesterday).Code)
+	assert.Equal(t, http.StatusUnauthorized, post(r, "Bearer wrong", from, yesterday).Code)
+
+	// The range limit is checked before anything is queued
+	w := post(r, "Bearer s3cret", yesterday.AddDate(0, 0, -7), yesterday)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "the maximum is 7")
+
+	w = post(r, "Bearer s3cret", from, yesterday)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var response struct {
+		Data dto.QueuedAggregationDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.Data.Days)
+
+	// Only one re-aggregation waits at a time
+	assert.Equal(t, http.StatusConflict, post(r, "Bearer s3cret", from, yesterday).Code)
+}
+
+func TestGetMigrationsReadsTheRepository(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repos := repository.NewMemoryStore().Repositories()
+	admin := NewAdminHandler(services.NewAggregator(repos), services.NewMigrationService(repos.Migrations), "s3cret")
+	r := gin.New()
+	r.GET("/admin/migrations", admin.GetMigrations)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/migrations", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Memory storage has no schema, so nothing is pending
+	var response struct {
+		Data dto.MigrationsDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Data.UpToDate)
+	assert.Empty(t, response.Data.Migrations)
+	assert.Empty(t, response.Data.History)
+}
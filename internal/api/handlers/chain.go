@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/errors"
+	"cosmos-tracker/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lists every chain in the registry
+func ListChains(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": config.Chains()})
+}
+
+// returns a single chain from the registry
+func GetChain(c *gin.Context) {
+	chain, ok := config.GetChain(c.Param("chain"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": chain})
+}
+
+// resolves the chain for the request from the :chain param or the validator prefix,
+// writing an error response and returning false when it cannot
+func resolveChain(c *gin.Context) (config.ChainConfig, bool) {
+	chain, err := services.ResolveChain(c.Param("chain"), c.Param("validator"))
+	if err != nil {
+		respondWithError(c, err, "Failed to resolve chain")
+		return config.ChainConfig{}, false
+	}
+	return chain, true
+}
+
+// writes an AppError with its own status code, or a generic 500 for anything else
+func respondWithError(c *gin.Context, err error, fallback string) {
+	var appErr *errors.AppError
+	if stderrors.As(err, &appErr) && appErr.Code != http.StatusInternalServerError {
+		c.JSON(appErr.Code, gin.H{"error": appErr.Message})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
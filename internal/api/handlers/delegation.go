@@ -2,11 +2,19 @@ package handlers
 
 import (
 	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/models"
 	"cosmos-tracker/internal/services"
+	"cosmos-tracker/pkg/numeric"
+	stderrors "errors"
+	"fmt"
 	"net/http"
+	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 )
 
 // extracts pagination parameters from the request
@@ -25,14 +33,143 @@ func getPaginationParams(c *gin.Context) (int, int) {
 	return page, limit
 }
 
+// extracts optional delegation filters, writing a 400 and returning false when invalid
+func getDelegationFilter(c *gin.Context) (dto.DelegationFilter, bool) {
+	var params dto.DelegationFilterParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": bindingErrorMessage(err, params)})
+		return dto.DelegationFilter{}, false
+	}
+
+	filter, err := parseDelegationFilter(params)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return dto.DelegationFilter{}, false
+	}
+	return filter, true
+}
+
+// converts bound query parameters into a filter, checking what the binding tags cannot
+func parseDelegationFilter(params dto.DelegationFilterParams) (dto.DelegationFilter, error) {
+	filter := dto.DelegationFilter{
+		Ascending: params.Order == "asc",
+		Change:    params.Change,
+	}
+
+	if params.Height != "" {
+		height, err := strconv.ParseInt(params.Height, 10, 64)
+		if err != nil || height < 1 {
+			return filter, stderrors.New("height must be a positive integer")
+		}
+		filter.Height = height
+	}
+
+	if params.Dense != "" {
+		filter.Dense, _ = strconv.ParseBool(params.Dense)
+	}
+
+	var err error
+	if filter.FromTime, filter.FromHeight, err = parseRangeBound("from", params.From); err != nil {
+		return filter, err
+	}
+	if filter.ToTime, filter.ToHeight, err = parseRangeBound("to", params.To); err != nil {
+		return filter, err
+	}
+	if (!filter.FromTime.IsZero() && !filter.ToTime.IsZero() && filter.FromTime.After(filter.ToTime)) ||
+		(filter.FromHeight > 0 && filter.ToHeight > 0 && filter.FromHeight > filter.ToHeight) {
+		return filter, stderrors.New("from must not be after to")
+	}
+
+	if params.MinAmount != "" {
+		amount, err := numeric.ParseInt(params.MinAmount)
+		if err != nil {
+			return filter, stderrors.New("min_amount must be a non-negative integer")
+		}
+		filter.MinAmount = &amount
+	}
+
+	if params.MinChange != "" {
+		change, err := numeric.ParseInt(params.MinChange)
+		if err != nil || change.Sign() < 1 {
+			return filter, stderrors.New("min_change must be a positive integer")
+		}
+		filter.MinChange = &change
+	}
+
+	return filter, nil
+}
+
+// parses one end of a range, which is either a block height or an RFC3339 time
+func parseRangeBound(name, raw string) (time.Time, int64, error) {
+	if raw == "" {
+		return time.Time{}, 0, nil
+	}
+	if strings.Trim(raw, "0123456789") == "" {
+		height, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || height < 1 {
+			return time.Time{}, 0, fmt.Errorf("%s must be a positive block height", name)
+		}
+		return time.Time{}, height, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("%s must be an RFC3339 time or a block height", name)
+	}
+	return t, 0, nil
+}
+
+// describes a query binding failure by the parameter name rather than the struct field
+func bindingErrorMessage(err error, params interface{}) string {
+	var fieldErrors validator.ValidationErrors
+	if !stderrors.As(err, &fieldErrors) || len(fieldErrors) == 0 {
+		return err.Error()
+	}
+
+	fieldErr := fieldErrors[0]
+	name := fieldErr.Field()
+	if field, ok := reflect.TypeOf(params).FieldByName(fieldErr.StructField()); ok {
+		name = field.Tag.Get("form")
+	}
+
+	switch fieldErr.Tag() {
+	case "number":
+		return name + " must be a non-negative integer"
+	case "boolean":
+		return name + " must be true or false"
+	case "oneof":
+		return name + " must be " + strings.Join(strings.Fields(fieldErr.Param()), " or ")
+	}
+	return err.Error()
+}
+
+// DelegationHandler serves stored delegation history and current positions
+type DelegationHandler struct {
+	delegations *services.DelegationService
+}
+
+// creates the delegation handlers on top of a service
+func NewDelegationHandler(delegations *services.DelegationService) *DelegationHandler {
+	return &DelegationHandler{delegations: delegations}
+}
+
 // fetches hourly delegation changes for a validator with pagination
-func GetHourlyDelegations(c *gin.Context) {
+func (h *DelegationHandler) GetHourlyDelegations(c *gin.Context) {
 	validator := c.Param("validator")
 	page, limit := getPaginationParams(c)
 
-	data, total, err := services.FetchHourlyDelegationsWithPagination(validator, page, limit)
+	chain, ok := resolveChain(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := getDelegationFilter(c)
+	if !ok {
+		return
+	}
+
+	data, total, err := h.delegations.FetchHourlyDelegationsWithPagination(c.Request.Context(), chain.ChainID, validator, page, limit, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data"})
+		respondWithError(c, err, "Failed to retrieve data")
 		return
 	}
 
@@ -52,13 +189,23 @@ func GetHourlyDelegations(c *gin.Context) {
 }
 
 // fetches daily delegation changes for a validator with pagination
-func GetDailyDelegations(c *gin.Context) {
+func (h *DelegationHandler) GetDailyDelegations(c *gin.Context) {
 	validator := c.Param("validator")
 	page, limit := getPaginationParams(c)
 
-	data, total, err := services.FetchDailyDelegationsWithPagination(validator, page, limit)
+	chain, ok := resolveChain(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := getDelegationFilter(c)
+	if !ok {
+		return
+	}
+
+	data, total, err := h.delegations.FetchDailyDelegationsWithPagination(c.Request.Context(), chain.ChainID, validator, page, limit, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data"})
+		respondWithError(c, err, "Failed to retrieve data")
 		return
 	}
 
@@ -78,12 +225,113 @@ func GetDailyDelegations(c *gin.Context) {
 }
 
 // fetches delegation history for a specific delegator with pagination
-func GetDelegatorHistory(c *gin.Context) {
+func (h *DelegationHandler) GetDelegatorHistory(c *gin.Context) {
+	validator := c.Param("validator")
+	delegator := c.Param("delegator")
+	page, limit := getPaginationParams(c)
+
+	chain, ok := resolveChain(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := getDelegationFilter(c)
+	if !ok {
+		return
+	}
+
+	data, total, err := h.delegations.FetchDelegatorHistoryWithPagination(c.Request.Context(), chain.ChainID, validator, delegator, page, limit, filter)
+	if err != nil {
+		respondWithError(c, err, "Failed to retrieve data")
+		return
+	}
+
+	totalPages := (total + int64(limit) - 1) / int64(limit)
+
+	response := dto.DelegationResponse{
+		Pagination: dto.Pagination{
+			Page:       page,
+			PerPage:    limit,
+			TotalPages: int(totalPages),
+			TotalData:  int(total),
+		},
+		Data: data,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// lists a validator's current delegators by amount, with the delegator count and total stake
+func (h *DelegationHandler) GetCurrentDelegators(c *gin.Context) {
+	validator := c.Param("validator")
+	page, limit := getPaginationParams(c)
+
+	chain, ok := resolveChain(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.delegations.FetchCurrentDelegatorsWithPagination(c.Request.Context(), chain.ChainID, validator, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data"})
+		return
+	}
+
+	totalPages := (data.TotalDelegators + int64(limit) - 1) / int64(limit)
+
+	response := dto.DelegationResponse{
+		Pagination: dto.Pagination{
+			Page:       page,
+			PerPage:    limit,
+			TotalPages: int(totalPages),
+			TotalData:  int(data.TotalDelegators),
+		},
+		Data: data,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// returns a delegator's current position with a validator
+func (h *DelegationHandler) GetCurrentDelegation(c *gin.Context) {
 	validator := c.Param("validator")
 	delegator := c.Param("delegator")
+
+	chain, ok := resolveChain(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.delegations.FetchCurrentDelegation(c.Request.Context(), chain.ChainID, validator, delegator)
+	if err != nil {
+		respondWithError(c, err, "Failed to retrieve data")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DelegationResponse{Data: data})
+}
+
+// fetches ISO-week OHLC rollups for a validator with pagination
+func (h *DelegationHandler) GetWeeklyDelegations(c *gin.Context) {
+	h.getDelegationRollups(c, models.PeriodWeek)
+}
+
+// fetches calendar-month OHLC rollups for a validator with pagination
+func (h *DelegationHandler) GetMonthlyDelegations(c *gin.Context) {
+	h.getDelegationRollups(c, models.PeriodMonth)
+}
+
+// serves the rollups of one period in the standard pagination envelope
+func (h *DelegationHandler) getDelegationRollups(c *gin.Context, period string) {
+	validator := c.Param("validator")
 	page, limit := getPaginationParams(c)
 
-	data, total, err := services.FetchDelegatorHistoryWithPagination(validator, delegator, page, limit)
+	chain, ok := resolveChain(c)
+	if !ok {
+		return
+	}
+
+	data, total, err := h.delegations.FetchDelegationRollupsWithPagination(c.Request.Context(), period, chain.ChainID, validator, page, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data"})
 		return
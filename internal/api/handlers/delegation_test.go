@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/internal/repository"
+	"cosmos-tracker/internal/services"
+	"cosmos-tracker/pkg/numeric"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testValidator = "cosmosvaloper1watched"
+
+// serves the delegation and watchlist routes from an in-memory store
+func testRouter() (*gin.Engine, repository.Repositories) {
+	gin.SetMode(gin.TestMode)
+	repos := repository.NewMemoryStore().Repositories()
+
+	delegations := NewDelegationHandler(services.NewDelegationService(repos.Delegations))
+	watchlist := NewWatchlistHandler(services.NewWatchlistService(repos.Watchlist))
+
+	r := gin.New()
+	r.GET("/validators/:validator/delegations/hourly", delegations.GetHourlyDelegations)
+	r.GET("/validators/:validator/delegations/daily", delegations.GetDailyDelegations)
+	r.GET("/validators/:validator/delegators/:delegator", delegations.GetCurrentDelegation)
+	r.POST("/watchlist", watchlist.AddToWatchlist)
+	r.GET("/watchlist", watchlist.GetWatchlist)
+	r.DELETE("/watchlist/:id", watchlist.RemoveFromWatchlist)
+	return r, repos
+}
+
+// performs a request and returns the recorded response
+func serve(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return w
+}
+
+func TestDelegationHandlersServeRepositoryData(t *testing.T) {
+	r, repos := testRouter()
+
+	now := time.Now()
+	rows := make([]models.HourlyDelegation, 3)
+	for i, delegator := range []string{"cosmos1alice", "cosmos1bob", "cosmos1carol"} {
+		rows[i] = models.HourlyDelegation{
+			ChainID:          "cosmoshub-4",
+			ValidatorAddress: testValidator,
+			DelegatorAddress: delegator,
+			DelegationAmount: numeric.NewInt(int64(100 * (i + 1))),
+			BlockHeight:      100,
+			Timestamp:        now,
+		}
+	}
+	require.NoError(t, repos.Snapshots.SaveSnapshot(context.Background(), repository.Snapshot{Delegations: rows}))
+
+	w := serve(r, http.MethodGet, "/validators/"+testValidator+"/delegations/hourly?limit=2", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var page struct {
+		Data       []dto.HourlyDelegationDTO `json:"data"`
+		Pagination dto.Pagination            `json:"pagination"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Len(t, page.Data, 2)
+	assert.Equal(t, 3, page.Pagination.TotalData)
+	assert.Equal(t, 2, page.Pagination.TotalPages)
+
+	w = serve(r, http.MethodGet, "/validators/"+testValidator+"/delegations/hourly?height=abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(r, http.MethodGet, "/validators/"+testValidator+"/delegators/cosmos1bob", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"delegation_amount":"200"`)
+
+	w = serve(r, http.MethodGet, "/validators/"+testValidator+"/delegators/cosmos1dave", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDelegationFilterParams(t *testing.T) {
+	r, repos := testRouter()
+
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	// Alice delegates 100, adds 200, then takes 100 back
+	for i, change := range []int64{100, 200, -100} {
+		amount := []int64{100, 300, 200}[i]
+		require.NoError(t, repos.Snapshots.SaveSnapshot(context.Background(), repository.Snapshot{
+			Delegations: []models.HourlyDelegation{{
+				ChainID:          "cosmoshub-4",
+				ValidatorAddress: testValidator,
+				DelegatorAddress: "cosmos1alice",
+				DelegationAmount: numeric.NewInt(amount),
+				ChangeAmount:     numeric.NewInt(change),
+				BlockHeight:      int64(100 + i),
+				Timestamp:        t0.Add(time.Duration(i) * time.Hour),
+			}},
+			Heartbeat: models.CollectionHeartbeat{ChainID: "cosmoshub-4", ValidatorAddress: testValidator, RunID: fmt.Sprint(i)},
+		}))
+	}
+
+	heights := func(query string) []int64 {
+		w := serve(r, http.MethodGet, "/validators/"+testValidator+"/delegations/hourly?"+query, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var page struct {
+			Data []dto.HourlyDelegationDTO `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		result := make([]int64, len(page.Data))
+		for i, d := range page.Data {
+			result[i] = d.BlockHeight
+		}
+		return result
+	}
+
+	assert.Equal(t, []int64{102, 101, 100}, heights(""))
+	assert.Equal(t, []int64{101, 102}, heights("from=101&order=asc"))
+	assert.Equal(t, []int64{101, 100}, heights("to="+url.QueryEscape(t0.Add(time.Hour).Format(time.RFC3339))))
+	assert.Equal(t, []int64{102, 101}, heights("min_amount=200"))
+	assert.Equal(t, []int64{101, 100}, heights("min_change=100&change=positive"))
+	assert.Equal(t, []int64{102}, heights("change=negative"))
+
+	for query, message := range map[string]string{
+		"order=up":         "order must be asc or desc",
+		"change=flat":      "change must be positive or negative",
+		"min_amount=-5":    "min_amount must be a non-negative integer",
+		"min_change=0":     "min_change must be a positive integer",
+		"dense=maybe":      "dense must be true or false",
+		"height=0":         "height must be a positive integer",
+		"from=yesterday":   "from must be an RFC3339 time or a block height",
+		"from=102&to=101":  "from must not be after to",
+		"to=2024-03-01":    "to must be an RFC3339 time or a block height",
+		"from=0&order=asc": "from must be a positive block height",
+	} {
+		w := serve(r, http.MethodGet, "/validators/"+testValidator+"/delegations/hourly?"+query, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.JSONEq(t, `{"error":"`+message+`"}`, w.Body.String(), query)
+	}
+
+	// Daily rows carry no change
+	w := serve(r, http.MethodGet, "/validators/"+testValidator+"/delegations/daily?change=positive", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(r, http.MethodGet, "/validators/"+testValidator+"/delegations/daily?from=100&order=asc", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWatchlistHandlers(t *testing.T) {
+	r, _ := testRouter()
+
+	w := serve(r, http.MethodPost, "/watchlist", `{"validator_address":"`+testValidator+`","validator_name":"Watched"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = serve(r, http.MethodGet, "/watchlist", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var entries []dto.WatchlistEntry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "cosmoshub-4", entries[0].ChainID)
+
+	w = serve(r, http.MethodDelete, "/watchlist/abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(r, http.MethodDelete, "/watchlist/1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	w = serve(r, http.MethodGet, "/watchlist", "")
+	assert.JSONEq(t, "[]", w.Body.String())
+}
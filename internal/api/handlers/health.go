@@ -1,38 +1,47 @@
 package handlers
 
 import (
-	"cosmos-tracker/internal/models"
 	"cosmos-tracker/internal/services"
-	"cosmos-tracker/pkg/db"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
+// HealthHandler serves health checks of the database, chains and collected data
+type HealthHandler struct {
+	health *services.HealthService
+}
+
+// creates a health handler on top of a service
+func NewHealthHandler(health *services.HealthService) *HealthHandler {
+	return &HealthHandler{health: health}
+}
+
 // provides a status overview of all system components
-func HealthCheck(c *gin.Context) {
+func (h *HealthHandler) HealthCheck(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	// Check database connection
-	sqlDB, err := db.DB.DB()
 	dbStatus := "ok"
-	if err != nil {
-		dbStatus = "error: failed to get DB connection"
-	} else if err := sqlDB.Ping(); err != nil {
+	if err := h.health.PingDatabase(ctx); err != nil {
 		dbStatus = "error: database not responding"
 	}
 
-	// Check API connection
+	// Check API connection for every registered chain
 	apiStatus := "ok"
-	if !services.IsHealthy() {
-		apiStatus = "error: cannot connect to Cosmos API"
+	chainStatus := gin.H{}
+	for chainID, healthy := range services.ChainHealth(ctx) {
+		if healthy {
+			chainStatus[chainID] = "ok"
+		} else {
+			chainStatus[chainID] = "error: cannot connect to LCD endpoint"
+			apiStatus = "error: cannot connect to Cosmos API"
+		}
 	}
 
 	// Get basic statistics
-	var watchlistCount int64
-	var delegationCount int64
-	db.DB.Model(&models.Watchlist{}).Count(&watchlistCount)
-	db.DB.Model(&models.HourlyDelegation{}).Count(&delegationCount)
+	counts, _ := h.health.RecordCounts(ctx)
 
 	// Return comprehensive health information
 	c.JSON(http.StatusOK, gin.H{
@@ -41,32 +50,45 @@ func HealthCheck(c *gin.Context) {
 		"components": gin.H{
 			"database":   dbStatus,
 			"cosmos_api": apiStatus,
+			"chains":     chainStatus,
 		},
 		"stats": gin.H{
-			"watchlist_entries":    watchlistCount,
-			"delegations_recorded": delegationCount,
+			"watchlist_entries":    counts.WatchlistEntries,
+			"delegations_recorded": counts.HourlyDelegations,
 		},
 		"version": "1.0.0",
 	})
 }
 
+// reports the rolling health score and last error of every LCD endpoint
+func (h *HealthHandler) EndpointHealth(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"timestamp": time.Now(),
+		"data":      services.EndpointHealth(),
+	})
+}
+
 // reports on data freshness and statistics
-func DataHealth(c *gin.Context) {
-	// Check how recent our data is
-	var latestDelegation models.HourlyDelegation
-	result := db.DB.Order("timestamp DESC").First(&latestDelegation)
+func (h *HealthHandler) DataHealth(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Check how recent our data is; every run leaves a heartbeat even when no position changed
+	latestRun, found, err := h.health.LatestRun(ctx)
 
 	dataStatus := "ok"
 	freshness := "unknown"
+	var latestHeight int64
 
-	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+	if err != nil {
 		dataStatus = "error: cannot query data"
-	} else if result.Error == gorm.ErrRecordNotFound {
+	} else if !found {
 		dataStatus = "warning: no data recorded yet"
 	} else {
-		// Check if data is stale (older than 2 hours)
-		timeSinceUpdate := time.Since(latestDelegation.Timestamp)
+		// Check if data is stale (older than 2 hours); the run's timestamp is the start of its
+		// interval, so the block it was pinned to tells when it actually ran
+		timeSinceUpdate := time.Since(latestRun.BlockTime)
 		freshness = timeSinceUpdate.String()
+		latestHeight = latestRun.BlockHeight
 
 		if timeSinceUpdate > 2*time.Hour {
 			dataStatus = "warning: data may be stale"
@@ -74,17 +96,15 @@ func DataHealth(c *gin.Context) {
 	}
 
 	// Get data statistics
-	var dailyDelegationCount int64
-	var hourlyDelegationCount int64
-	db.DB.Model(&models.HourlyDelegation{}).Count(&hourlyDelegationCount)
-	db.DB.Model(&models.DailyDelegation{}).Count(&dailyDelegationCount)
+	counts, _ := h.health.RecordCounts(ctx)
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":         dataStatus,
 		"data_freshness": freshness,
+		"latest_height":  latestHeight,
 		"statistics": gin.H{
-			"hourly_records": hourlyDelegationCount,
-			"daily_records":  dailyDelegationCount,
+			"hourly_records": counts.HourlyDelegations,
+			"daily_records":  counts.DailyDelegations,
 		},
 	})
 }
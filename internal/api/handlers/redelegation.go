@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// extracts the redelegation direction, writing a 400 and returning false when invalid
+func getDirectionParam(c *gin.Context, allowBoth bool) (string, bool) {
+	direction := c.Query("direction")
+	switch direction {
+	case services.RedelegationIn, services.RedelegationOut:
+		return direction, true
+	case "":
+		if allowBoth {
+			return "", true
+		}
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{"error": "direction must be 'in' or 'out'"})
+	return "", false
+}
+
+// RedelegationHandler serves redelegations into and out of watched validators
+type RedelegationHandler struct {
+	redelegations *services.RedelegationService
+}
+
+// creates a redelegation handler on top of a service
+func NewRedelegationHandler(redelegations *services.RedelegationService) *RedelegationHandler {
+	return &RedelegationHandler{redelegations: redelegations}
+}
+
+// lists redelegations into or out of a validator with pagination
+func (h *RedelegationHandler) GetRedelegations(c *gin.Context) {
+	validator := c.Param("validator")
+	page, limit := getPaginationParams(c)
+
+	chain, ok := resolveChain(c)
+	if !ok {
+		return
+	}
+
+	direction, ok := getDirectionParam(c, true)
+	if !ok {
+		return
+	}
+
+	data, total, err := h.redelegations.FetchRedelegationsWithPagination(c.Request.Context(), chain.ChainID, validator, direction, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data"})
+		return
+	}
+
+	totalPages := (total + int64(limit) - 1) / int64(limit)
+
+	response := dto.DelegationResponse{
+		Pagination: dto.Pagination{
+			Page:       page,
+			PerPage:    limit,
+			TotalPages: int(totalPages),
+			TotalData:  int(total),
+		},
+		Data: data,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// totals redelegated stake per counterpart validator for one direction
+func (h *RedelegationHandler) GetRedelegationSummary(c *gin.Context) {
+	validator := c.Param("validator")
+
+	chain, ok := resolveChain(c)
+	if !ok {
+		return
+	}
+
+	direction, ok := getDirectionParam(c, false)
+	if !ok {
+		return
+	}
+
+	data, err := h.redelegations.SummarizeRedelegations(c.Request.Context(), chain.ChainID, validator, direction)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DelegationResponse{Data: data})
+}
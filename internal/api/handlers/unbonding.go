@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// extracts the optional forecast window in days, writing a 400 and returning false when invalid
+func getDaysParam(c *gin.Context) (int, bool) {
+	raw := c.Query("days")
+	if raw == "" {
+		return 0, true
+	}
+
+	days, err := strconv.Atoi(raw)
+	if err != nil || days < 0 || days > 365 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be an integer between 0 and 365"})
+		return 0, false
+	}
+	return days, true
+}
+
+// UnbondingHandler serves the unbonding queues of watched validators
+type UnbondingHandler struct {
+	unbondings *services.UnbondingService
+}
+
+// creates an unbonding handler on top of a service
+func NewUnbondingHandler(unbondings *services.UnbondingService) *UnbondingHandler {
+	return &UnbondingHandler{unbondings: unbondings}
+}
+
+// lists a validator's pending unbondings ordered by completion date
+func (h *UnbondingHandler) GetUnbondings(c *gin.Context) {
+	validator := c.Param("validator")
+	page, limit := getPaginationParams(c)
+
+	chain, ok := resolveChain(c)
+	if !ok {
+		return
+	}
+
+	days, ok := getDaysParam(c)
+	if !ok {
+		return
+	}
+
+	data, total, err := h.unbondings.FetchUnbondingsWithPagination(c.Request.Context(), chain.ChainID, validator, days, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data"})
+		return
+	}
+
+	totalPages := (total + int64(limit) - 1) / int64(limit)
+
+	response := dto.DelegationResponse{
+		Pagination: dto.Pagination{
+			Page:       page,
+			PerPage:    limit,
+			TotalPages: int(totalPages),
+			TotalData:  int(total),
+		},
+		Data: data,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// forecasts stake leaving a validator per day over the next N days
+func (h *UnbondingHandler) GetUnbondingForecast(c *gin.Context) {
+	validator := c.Param("validator")
+
+	chain, ok := resolveChain(c)
+	if !ok {
+		return
+	}
+
+	days, ok := getDaysParam(c)
+	if !ok {
+		return
+	}
+	if days == 0 {
+		days = 21
+	}
+
+	forecast, err := h.unbondings.ForecastUnbondings(c.Request.Context(), chain.ChainID, validator, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DelegationResponse{Data: forecast})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValidatorHandler serves validator snapshots and stats
+type ValidatorHandler struct {
+	validators *services.ValidatorService
+}
+
+// creates a validator handler on top of a service
+func NewValidatorHandler(validators *services.ValidatorService) *ValidatorHandler {
+	return &ValidatorHandler{validators: validators}
+}
+
+// lists a validator's metadata snapshots with pagination
+func (h *ValidatorHandler) GetValidatorSnapshots(c *gin.Context) {
+	validator := c.Param("validator")
+	page, limit := getPaginationParams(c)
+
+	chain, ok := resolveChain(c)
+	if !ok {
+		return
+	}
+
+	data, total, err := h.validators.FetchValidatorSnapshotsWithPagination(c.Request.Context(), chain.ChainID, validator, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data"})
+		return
+	}
+
+	totalPages := (total + int64(limit) - 1) / int64(limit)
+
+	response := dto.DelegationResponse{
+		Pagination: dto.Pagination{
+			Page:       page,
+			PerPage:    limit,
+			TotalPages: int(totalPages),
+			TotalData:  int(total),
+		},
+		Data: data,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// returns the latest known state of a validator
+func (h *ValidatorHandler) GetCurrentValidator(c *gin.Context) {
+	validator := c.Param("validator")
+
+	chain, ok := resolveChain(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.validators.FetchCurrentValidator(c.Request.Context(), chain.ChainID, validator)
+	if err != nil {
+		respondWithError(c, err, "Failed to retrieve data")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DelegationResponse{Data: data})
+}
+
+// fetches per-run validator totals and flows with pagination
+func (h *ValidatorHandler) GetHourlyValidatorStats(c *gin.Context) {
+	getValidatorStats(c, h.validators.FetchHourlyValidatorStatsWithPagination)
+}
+
+// fetches daily validator totals and flows with pagination
+func (h *ValidatorHandler) GetDailyValidatorStats(c *gin.Context) {
+	getValidatorStats(c, h.validators.FetchDailyValidatorStatsWithPagination)
+}
+
+// serves one validator stats series in the standard pagination envelope
+func getValidatorStats(c *gin.Context, fetch func(ctx context.Context, chainID, validatorAddress string, page, limit int) ([]dto.ValidatorStatsDTO, int64, error)) {
+	validator := c.Param("validator")
+	page, limit := getPaginationParams(c)
+
+	chain, ok := resolveChain(c)
+	if !ok {
+		return
+	}
+
+	data, total, err := fetch(c.Request.Context(), chain.ChainID, validator, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data"})
+		return
+	}
+
+	totalPages := (total + int64(limit) - 1) / int64(limit)
+
+	response := dto.DelegationResponse{
+		Pagination: dto.Pagination{
+			Page:       page,
+			PerPage:    limit,
+			TotalPages: int(totalPages),
+			TotalData:  int(total),
+		},
+		Data: data,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -8,25 +8,49 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// WatchlistHandler manages the validators being tracked
+type WatchlistHandler struct {
+	watchlist *services.WatchlistService
+}
+
+// creates the watchlist handlers on top of a service
+func NewWatchlistHandler(watchlist *services.WatchlistService) *WatchlistHandler {
+	return &WatchlistHandler{watchlist: watchlist}
+}
+
 // Add new validator + delegator to watchlist
-func AddToWatchlist(c *gin.Context) {
+func (h *WatchlistHandler) AddToWatchlist(c *gin.Context) {
 	var entry dto.WatchlistEntry
 	if err := c.ShouldBindJSON(&entry); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := services.AddWatchlistEntry(entry); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add entry"})
+	// Chain-scoped routes take the chain from the path
+	if chainParam := c.Param("chain"); chainParam != "" {
+		entry.ChainID = chainParam
+	}
+
+	if err := h.watchlist.AddWatchlistEntry(c.Request.Context(), entry); err != nil {
+		respondWithError(c, err, "Failed to add entry")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Added to watchlist"})
 }
 
-// Get all watchlist entries
-func GetWatchlist(c *gin.Context) {
-	entries, err := services.GetWatchlist()
+// Get all watchlist entries, scoped to a chain when the route has one
+func (h *WatchlistHandler) GetWatchlist(c *gin.Context) {
+	chainID := ""
+	if chainParam := c.Param("chain"); chainParam != "" {
+		chain, ok := resolveChain(c)
+		if !ok {
+			return
+		}
+		chainID = chain.ChainID
+	}
+
+	entries, err := h.watchlist.GetWatchlist(c.Request.Context(), chainID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve watchlist"})
 		return
@@ -35,11 +59,11 @@ func GetWatchlist(c *gin.Context) {
 }
 
 // Remove entry from watchlist
-func RemoveFromWatchlist(c *gin.Context) {
+func (h *WatchlistHandler) RemoveFromWatchlist(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := services.RemoveWatchlistEntry(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove entry"})
+	if err := h.watchlist.RemoveWatchlistEntry(c.Request.Context(), id); err != nil {
+		respondWithError(c, err, "Failed to remove entry")
 		return
 	}
 
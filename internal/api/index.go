@@ -2,13 +2,25 @@ package routers
 
 import (
 	routersGroup "cosmos-tracker/internal/api/groups"
+	"cosmos-tracker/internal/api/handlers"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Handlers are the request handlers built on injected services
+type Handlers struct {
+	Delegations   *handlers.DelegationHandler
+	Watchlist     *handlers.WatchlistHandler
+	Unbondings    *handlers.UnbondingHandler
+	Redelegations *handlers.RedelegationHandler
+	Validators    *handlers.ValidatorHandler
+	Health        *handlers.HealthHandler
+	Admin         *handlers.AdminHandler
+}
+
 // RegisterRoutes registers all API routes
-func RegisterRoutes(route *gin.Engine) {
+func RegisterRoutes(route *gin.Engine, h Handlers) {
 	// Handle 404 Not Found
 	route.NoRoute(func(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, gin.H{
@@ -22,7 +34,12 @@ func RegisterRoutes(route *gin.Engine) {
 	apiVersion := "/api/v1"
 
 	// Register all route groups
-	routersGroup.DelegationRoute(route, apiVersion)
-	routersGroup.WatchlistRoute(route, apiVersion)
-	routersGroup.HealthRoute(route, apiVersion)
+	routersGroup.ChainRoute(route, apiVersion)
+	routersGroup.DelegationRoute(route, apiVersion, h.Delegations)
+	routersGroup.UnbondingRoute(route, apiVersion, h.Unbondings)
+	routersGroup.RedelegationRoute(route, apiVersion, h.Redelegations)
+	routersGroup.ValidatorRoute(route, apiVersion, h.Validators)
+	routersGroup.WatchlistRoute(route, apiVersion, h.Watchlist)
+	routersGroup.HealthRoute(route, apiVersion, h.Health)
+	routersGroup.AdminRoute(route, apiVersion, h.Admin)
 }
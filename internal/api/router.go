@@ -6,7 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter() *gin.Engine {
+func SetupRouter(h Handlers) *gin.Engine {
 
 	debug := os.Getenv("DEBUG")
 	if debug == "true" {
@@ -22,7 +22,7 @@ func SetupRouter() *gin.Engine {
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 
-	RegisterRoutes(r) //routes register
+	RegisterRoutes(r, h) //routes register
 
 	return r
 }
@@ -1,26 +1,96 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"cosmos-tracker/pkg/numeric"
+)
 
 // represents hourly delegation metrics retrieved from the Cosmos network
 type HourlyDelegationDTO struct {
-	ID               uint      `json:"id"`
-	ValidatorAddress string    `json:"validator_address"`
-	DelegatorAddress string    `json:"delegator_address"`
-	DelegationAmount int64     `json:"delegation_amount"`
-	ChangeAmount     int64     `json:"change_amount"`
-	Shares           float64   `json:"shares,omitempty"`
-	Timestamp        time.Time `json:"timestamp"`
+	ID               uint        `json:"id"`
+	ChainID          string      `json:"chain_id"`
+	ValidatorAddress string      `json:"validator_address"`
+	DelegatorAddress string      `json:"delegator_address"`
+	DelegationAmount numeric.Int `json:"delegation_amount"`
+	ChangeAmount     numeric.Int `json:"change_amount"`
+	Shares           numeric.Dec `json:"shares"`
+	Exited           bool        `json:"exited"`
+	Returned         bool        `json:"returned"`
+	BlockHeight      int64       `json:"block_height"`
+	BlockTime        time.Time   `json:"block_time"`
+	Timestamp        time.Time   `json:"timestamp"`
+	CarriedForward   bool        `json:"carried_forward,omitempty"` // dense series only: unchanged since the row with this ID
 }
 
 // represents aggregated daily delegation metrics
 type DailyDelegationDTO struct {
-	ID               uint      `json:"id"`
-	ValidatorAddress string    `json:"validator_address"`
-	DelegatorAddress string    `json:"delegator_address"`
-	TotalDelegation  int64     `json:"total_delegation"`
-	TotalShares      float64   `json:"total_shares,omitempty"`
-	Date             time.Time `json:"date"`
+	ID               uint        `json:"id"`
+	ChainID          string      `json:"chain_id"`
+	ValidatorAddress string      `json:"validator_address"`
+	DelegatorAddress string      `json:"delegator_address"`
+	TotalDelegation  numeric.Int `json:"total_delegation"`
+	TotalShares      numeric.Dec `json:"total_shares"`
+	BlockHeight      int64       `json:"block_height"`
+	BlockTime        time.Time   `json:"block_time"`
+	Date             string      `json:"date"`     // YYYY-MM-DD
+	Timezone         string      `json:"timezone"` // timezone the day was aggregated in
+}
+
+// represents a delegator's current position with a validator
+type CurrentDelegationDTO struct {
+	ChainID          string      `json:"chain_id"`
+	ValidatorAddress string      `json:"validator_address"`
+	DelegatorAddress string      `json:"delegator_address"`
+	DelegationAmount numeric.Int `json:"delegation_amount"`
+	Shares           numeric.Dec `json:"shares"`
+	BlockHeight      int64       `json:"block_height"`
+	BlockTime        time.Time   `json:"block_time"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+}
+
+// lists a page of a validator's current delegators with totals across all of them
+type CurrentDelegatorsDTO struct {
+	ChainID          string                 `json:"chain_id"`
+	ValidatorAddress string                 `json:"validator_address"`
+	TotalDelegators  int64                  `json:"total_delegators"`
+	TotalStake       numeric.Int            `json:"total_stake"`
+	Delegators       []CurrentDelegationDTO `json:"delegators"`
+}
+
+// directions accepted by the change filter
+const (
+	ChangePositive = "positive"
+	ChangeNegative = "negative"
+)
+
+// query parameters accepted by the hourly, daily and delegator history endpoints
+type DelegationFilterParams struct {
+	Height    string `form:"height" binding:"omitempty,number"`
+	Dense     string `form:"dense" binding:"omitempty,boolean"`
+	From      string `form:"from"` // RFC3339 time or block height
+	To        string `form:"to"`   // RFC3339 time or block height
+	Order     string `form:"order" binding:"omitempty,oneof=asc desc"`
+	MinAmount string `form:"min_amount" binding:"omitempty,number"`
+	MinChange string `form:"min_change" binding:"omitempty,number"`
+	Change    string `form:"change" binding:"omitempty,oneof=positive negative"`
+}
+
+// narrows delegation queries beyond the validator and pagination
+type DelegationFilter struct {
+	Height int64 // only rows recorded at this block height when non-zero
+	Dense  bool  // carry each delegator's last row forward to every run, for change-only storage
+
+	// Inclusive range over times or block heights; each end is a time, a height or open
+	FromTime   time.Time
+	FromHeight int64
+	ToTime     time.Time
+	ToHeight   int64
+
+	Ascending bool         // oldest first instead of newest first
+	MinAmount *numeric.Int // only rows holding at least this much
+	MinChange *numeric.Int // only rows whose amount moved by at least this much either way
+	Change    string       // ChangePositive or ChangeNegative keeps only increases or decreases
 }
 
 // standardizes the API response format for all delegation endpoints
@@ -36,3 +106,57 @@ type ErrorResponse struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
+
+// requests a re-aggregation of daily rows for an inclusive date range (YYYY-MM-DD)
+type ReaggregationRequest struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+// summarizes a re-aggregation run
+type AggregationResultDTO struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Days    int    `json:"days"`
+	Entries int    `json:"entries"`
+	Rows    int    `json:"rows"`
+}
+
+// describes a re-aggregation accepted to run in the background
+type QueuedAggregationDTO struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Days int    `json:"days"`
+}
+
+// represents one retention pass that pruned hourly rows of a watchlist entry
+type RetentionRunDTO struct {
+	ChainID          string    `json:"chain_id"`
+	ValidatorAddress string    `json:"validator_address"`
+	Table            string    `json:"table"`
+	Cutoff           time.Time `json:"cutoff"` // rows strictly older than this were deleted
+	RowsDeleted      int64     `json:"rows_deleted"`
+	Error            string    `json:"error,omitempty"`
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+}
+
+// represents OHLC-style statistics of a delegator's stake over a day, ISO week or month
+type DelegationRollupDTO struct {
+	ChainID          string      `json:"chain_id"`
+	ValidatorAddress string      `json:"validator_address"`
+	DelegatorAddress string      `json:"delegator_address"`
+	Period           string      `json:"period"`
+	Label            string      `json:"label"`
+	PeriodStart      string      `json:"period_start"`
+	PeriodEnd        string      `json:"period_end"`
+	Timezone         string      `json:"timezone"`
+	Open             numeric.Int `json:"open"`
+	Close            numeric.Int `json:"close"`
+	Min              numeric.Int `json:"min"`
+	Max              numeric.Int `json:"max"`
+	NetChange        numeric.Int `json:"net_change"`
+	OpenHeight       int64       `json:"open_height"`
+	CloseHeight      int64       `json:"close_height"`
+	Snapshots        int64       `json:"snapshots"`
+}
@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+// reports the rolling health score of an LCD endpoint
+type EndpointHealthDTO struct {
+	ChainID       string     `json:"chain_id"`
+	URL           string     `json:"url"`
+	Score         float64    `json:"score"`
+	LatencyMs     float64    `json:"latency_ms"`
+	ErrorRate     float64    `json:"error_rate"`
+	ThrottleRate  float64    `json:"throttle_rate"`
+	Requests      int64      `json:"requests"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+}
+
+// reports one schema migration and whether the database has applied it
+type MigrationStatusDTO struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Checksum  string     `json:"checksum"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Modified  bool       `json:"modified"` // applied from a script that has changed since
+	Unknown   bool       `json:"unknown"`  // applied by a newer build
+}
+
+// represents one recorded migration event
+type MigrationHistoryDTO struct {
+	Version    int       `json:"version"`
+	Name       string    `json:"name"`
+	Direction  string    `json:"direction"`
+	Checksum   string    `json:"checksum"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Models     string    `json:"models,omitempty"` // legacy auto-migration events only
+	MigratedAt time.Time `json:"migrated_at"`
+}
+
+// represents the schema state and the most recent migration events
+type MigrationsDTO struct {
+	UpToDate   bool                  `json:"up_to_date"`
+	Migrations []MigrationStatusDTO  `json:"migrations"`
+	History    []MigrationHistoryDTO `json:"history"`
+}
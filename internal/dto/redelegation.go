@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"time"
+
+	"cosmos-tracker/pkg/numeric"
+)
+
+// represents stake moved between validators by a delegator
+type RedelegationDTO struct {
+	ID                  uint        `json:"id"`
+	ChainID             string      `json:"chain_id"`
+	TxHash              string      `json:"tx_hash"`
+	DelegatorAddress    string      `json:"delegator_address"`
+	SrcValidatorAddress string      `json:"src_validator_address"`
+	DstValidatorAddress string      `json:"dst_validator_address"`
+	Amount              numeric.Int `json:"amount"`
+	Denom               string      `json:"denom"`
+	CompletionTime      time.Time   `json:"completion_time"`
+	Height              int64       `json:"height"`
+	Timestamp           time.Time   `json:"timestamp"`
+}
+
+// totals redelegated stake exchanged with one counterpart validator
+type RedelegationCounterpartDTO struct {
+	ValidatorAddress string      `json:"validator_address"`
+	Amount           numeric.Int `json:"amount"`
+	Count            int64       `json:"count"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"time"
+
+	"cosmos-tracker/pkg/numeric"
+)
+
+// represents a pending or settled unbonding entry
+type UnbondingDelegationDTO struct {
+	ID               uint        `json:"id"`
+	ChainID          string      `json:"chain_id"`
+	ValidatorAddress string      `json:"validator_address"`
+	DelegatorAddress string      `json:"delegator_address"`
+	CreationHeight   int64       `json:"creation_height"`
+	CompletionTime   time.Time   `json:"completion_time"`
+	InitialBalance   numeric.Int `json:"initial_balance"`
+	Balance          numeric.Int `json:"balance"`
+	Status           string      `json:"status"`
+}
+
+// sums the stake leaving a validator on one day
+type UnbondingForecastDayDTO struct {
+	Date    string      `json:"date"`
+	Balance numeric.Int `json:"balance"`
+	Entries int         `json:"entries"`
+}
+
+// forecasts stake leaving a validator over the next N days
+type UnbondingForecastDTO struct {
+	ChainID          string                    `json:"chain_id"`
+	ValidatorAddress string                    `json:"validator_address"`
+	Days             int                       `json:"days"`
+	Timezone         string                    `json:"timezone"` // timezone whose midnights bound the days
+	TotalBalance     numeric.Int               `json:"total_balance"`
+	TotalEntries     int                       `json:"total_entries"`
+	Daily            []UnbondingForecastDayDTO `json:"daily"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"time"
+
+	"cosmos-tracker/pkg/numeric"
+)
+
+// represents validator-level state at one collection run
+type ValidatorSnapshotDTO struct {
+	ID                      uint        `json:"id"`
+	ChainID                 string      `json:"chain_id"`
+	ValidatorAddress        string      `json:"validator_address"`
+	Moniker                 string      `json:"moniker"`
+	Website                 string      `json:"website,omitempty"`
+	Tokens                  numeric.Int `json:"tokens"`
+	DelegatorShares         numeric.Dec `json:"delegator_shares"`
+	CommissionRate          numeric.Dec `json:"commission_rate"`
+	CommissionMaxRate       numeric.Dec `json:"commission_max_rate"`
+	CommissionMaxChangeRate numeric.Dec `json:"commission_max_change_rate"`
+	Jailed                  bool        `json:"jailed"`
+	Status                  string      `json:"status"`
+	VotingPower             int64       `json:"voting_power"`
+	VotingPowerShare        float64     `json:"voting_power_share"`
+	BlockHeight             int64       `json:"block_height"`
+	BlockTime               time.Time   `json:"block_time"`
+	Timestamp               time.Time   `json:"timestamp"`
+}
+
+// represents validator-level totals and flows for one collection run or one day
+type ValidatorStatsDTO struct {
+	ChainID          string      `json:"chain_id"`
+	ValidatorAddress string      `json:"validator_address"`
+	Timestamp        *time.Time  `json:"timestamp,omitempty"` // hourly series only
+	Date             string      `json:"date,omitempty"`      // daily series only, YYYY-MM-DD
+	Timezone         string      `json:"timezone,omitempty"`  // daily series only
+	BlockHeight      int64       `json:"block_height"`
+	TotalDelegated   numeric.Int `json:"total_delegated"`
+	DelegatorCount   int64       `json:"delegator_count"`
+	NewDelegators    int64       `json:"new_delegators"`
+	ExitedDelegators int64       `json:"exited_delegators"`
+	Inflow           numeric.Int `json:"inflow"`
+	Outflow          numeric.Int `json:"outflow"`
+	NetFlow          numeric.Int `json:"net_flow"`
+}
@@ -43,6 +43,15 @@ func NewBadRequestError(message string, err error) *AppError {
 	}
 }
 
+// creates an error for requests that clash with work already in progress
+func NewConflictError(message string, err error) *AppError {
+	return &AppError{
+		Code:    http.StatusConflict,
+		Message: message,
+		Err:     err,
+	}
+}
+
 // creates an error for unexpected server issues
 func NewInternalServerError(message string, err error) *AppError {
 	return &AppError{
@@ -1,26 +1,74 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"cosmos-tracker/pkg/numeric"
+)
 
 type HourlyDelegation struct {
 	ID               uint      `gorm:"primaryKey"`
 	WatchlistID      uint      `gorm:"index"`
 	Watchlist        Watchlist `gorm:"foreignKey:WatchlistID"`
-	ValidatorAddress string    `gorm:"index"` // safe column if not using watchlist
-	DelegatorAddress string    `gorm:"index"` // safe column if not using watchlist
-	DelegationAmount int64
-	ChangeAmount     int64
-	Shares           float64
-	Timestamp        time.Time `gorm:"autoCreateTime;index"`
+	ChainID          string    `gorm:"type:varchar(64);index:idx_hourly_delegations_validator_time,priority:1;index:idx_hourly_delegations_delegator_time,priority:1;uniqueIndex:idx_hourly_delegations_run,priority:1;default:'cosmoshub-4'"`
+	ValidatorAddress string    `gorm:"index:idx_hourly_delegations_validator_time,priority:2;index:idx_hourly_delegations_delegator_time,priority:2;uniqueIndex:idx_hourly_delegations_run,priority:2"` // safe column if not using watchlist
+	DelegatorAddress string    `gorm:"index:idx_hourly_delegations_delegator_time,priority:3;uniqueIndex:idx_hourly_delegations_run,priority:3"`                                                        // safe column if not using watchlist
+	RunID            string    `gorm:"type:varchar(32);uniqueIndex:idx_hourly_delegations_run,priority:4"`                                                                                              // collection run that wrote the row
+	DelegationAmount numeric.Int
+	ChangeAmount     numeric.Int
+	Shares           numeric.Dec
+	Exited           bool  `gorm:"default:false"` // delegator left the validator; amount is zero
+	Returned         bool  `gorm:"default:false"` // first snapshot after a previous exit
+	BlockHeight      int64 `gorm:"index"`         // height every page of the snapshot was queried at
+	BlockTime        time.Time
+	Timestamp        time.Time `gorm:"autoCreateTime;index:idx_hourly_delegations_validator_time,priority:3;index:idx_hourly_delegations_delegator_time,priority:4;uniqueIndex:idx_hourly_delegations_run,priority:5"` // start of the collection run; monthly partition key on Postgres
 }
 
 type DailyDelegation struct {
 	ID               uint      `gorm:"primaryKey"`
 	WatchlistID      uint      `gorm:"index"`
 	Watchlist        Watchlist `gorm:"foreignKey:WatchlistID"`
-	ValidatorAddress string    `gorm:"index"` // safe column if not using watchlist
-	DelegatorAddress string    `gorm:"index"` // safe column if not using watchlist
-	TotalDelegation  int64
-	TotalShares      float64
-	Date             time.Time `gorm:"autoCreateTime;index"`
+	ChainID          string    `gorm:"type:varchar(64);index;uniqueIndex:idx_daily_delegations_delegator_date,priority:1;default:'cosmoshub-4'"`
+	ValidatorAddress string    `gorm:"index;uniqueIndex:idx_daily_delegations_delegator_date,priority:2"` // safe column if not using watchlist
+	DelegatorAddress string    `gorm:"index;uniqueIndex:idx_daily_delegations_delegator_date,priority:3"` // safe column if not using watchlist
+	TotalDelegation  numeric.Int
+	TotalShares      numeric.Dec
+	BlockHeight      int64 `gorm:"index"` // height of the hourly snapshot the day closed on
+	BlockTime        time.Time
+	Date             time.Time `gorm:"type:date;index;uniqueIndex:idx_daily_delegations_delegator_date,priority:4"` // calendar day in the aggregation timezone
+	Timezone         string    `gorm:"type:varchar(64)"`                                                            // IANA timezone whose midnights bounded the day
+}
+
+// CollectionHeartbeat records that a collection run covered a validator, so a change-only
+// series can be made dense by carrying each delegator's last row forward to every run
+type CollectionHeartbeat struct {
+	ID               uint      `gorm:"primaryKey"`
+	WatchlistID      uint      `gorm:"index"`
+	ChainID          string    `gorm:"type:varchar(64);index:idx_collection_heartbeat,priority:1;uniqueIndex:idx_collection_heartbeats_run,priority:1"`
+	ValidatorAddress string    `gorm:"index:idx_collection_heartbeat,priority:2;uniqueIndex:idx_collection_heartbeats_run,priority:2"`
+	RunID            string    `gorm:"type:varchar(32);uniqueIndex:idx_collection_heartbeats_run,priority:3"` // writing a run twice is refused
+	Timestamp        time.Time `gorm:"index:idx_collection_heartbeat,priority:3"`                             // same as the run's snapshot rows
+	BlockHeight      int64
+	BlockTime        time.Time
+	StorageMode      string `gorm:"type:varchar(16)"`
+	Delegators       int64  // delegations returned by the chain
+	RowsWritten      int64  // snapshot rows written, including exits
+}
+
+// CurrentDelegation is the latest known position of a delegator with a validator,
+// upserted in the same transaction as every hourly snapshot
+type CurrentDelegation struct {
+	ID               uint        `gorm:"primaryKey"`
+	WatchlistID      uint        `gorm:"index"`
+	Watchlist        Watchlist   `gorm:"foreignKey:WatchlistID"`
+	ChainID          string      `gorm:"type:varchar(64);uniqueIndex:idx_current_delegation,priority:1;index:idx_current_delegation_amount,priority:1"`
+	ValidatorAddress string      `gorm:"uniqueIndex:idx_current_delegation,priority:2;index:idx_current_delegation_amount,priority:2"`
+	DelegatorAddress string      `gorm:"uniqueIndex:idx_current_delegation,priority:3;index"`
+	DelegationAmount numeric.Int `gorm:"index:idx_current_delegation_amount,priority:3"`
+	Shares           numeric.Dec
+	Exited           bool `gorm:"default:false"` // kept so a later return can be flagged
+	SnapshotID       uint // hourly_delegations row the position was last written from
+	BlockHeight      int64
+	BlockTime        time.Time
+	UpdatedAt        time.Time `gorm:"autoUpdateTime;index"`
 }
@@ -2,9 +2,14 @@ package models
 
 import "time"
 
-// MigrationHistory tracks database migration events
+// MigrationHistory tracks database migration events: every versioned migration applied or rolled
+// back. Rows written before versioned migrations existed have version 0 and list the auto-migrated models.
 type MigrationHistory struct {
 	ID           uint      `gorm:"primaryKey"`
+	Version      int       `gorm:"index;default:0"`
+	Name         string    `gorm:"type:varchar(255);default:''"`
+	Direction    string    `gorm:"type:varchar(10);default:''"` // up or down
+	Checksum     string    `gorm:"type:varchar(64);default:''"` // sha256 of the up script that was applied
 	MigratedAt   time.Time `gorm:"autoCreateTime;index"`
 	Models       string    `gorm:"type:text"`
 	Status       string    `gorm:"type:varchar(50);index"`
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"cosmos-tracker/pkg/numeric"
+)
+
+// Redelegation directions relative to a watched validator
+const (
+	RedelegationIn  = "in"
+	RedelegationOut = "out"
+)
+
+// Redelegation is a MsgBeginRedelegate where a watched validator is the source or destination
+type Redelegation struct {
+	ID                  uint   `gorm:"primaryKey"`
+	ChainID             string `gorm:"type:varchar(64);uniqueIndex:idx_redelegation_event;index:idx_redelegation_src,priority:1;index:idx_redelegation_dst,priority:1"`
+	TxHash              string `gorm:"type:varchar(64);uniqueIndex:idx_redelegation_event"`
+	EventIndex          int    `gorm:"uniqueIndex:idx_redelegation_event"` // position of the redelegate event within the tx
+	DelegatorAddress    string `gorm:"index"`
+	SrcValidatorAddress string `gorm:"index:idx_redelegation_src,priority:2"`
+	DstValidatorAddress string `gorm:"index:idx_redelegation_dst,priority:2"`
+	Amount              numeric.Int
+	Denom               string    `gorm:"type:varchar(128)"`
+	CompletionTime      time.Time `gorm:"index"`
+	Height              int64     `gorm:"index"`
+	Timestamp           time.Time // block time of the transaction
+	CreatedAt           time.Time `gorm:"autoCreateTime"`
+}
+
+// RedelegationScanCursor records the height up to which tx search has been fully scanned for a
+// watched validator's redelegations in one direction
+type RedelegationScanCursor struct {
+	ID               uint      `gorm:"primaryKey"`
+	ChainID          string    `gorm:"type:varchar(64);uniqueIndex:idx_redelegation_scan_cursor,priority:1"`
+	ValidatorAddress string    `gorm:"uniqueIndex:idx_redelegation_scan_cursor,priority:2"`
+	Direction        string    `gorm:"type:varchar(8);uniqueIndex:idx_redelegation_scan_cursor,priority:3"`
+	ScannedHeight    int64     // every matching tx at or below this height has been stored
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RetentionRun records how many rows one pruning pass deleted for one watchlist entry and table
+type RetentionRun struct {
+	ID               uint      `gorm:"primaryKey"`
+	ChainID          string    `gorm:"type:varchar(64);index"`
+	ValidatorAddress string    `gorm:"index"`
+	Table            string    `gorm:"type:varchar(64)"`
+	Cutoff           time.Time // rows strictly older than this were deleted
+	RowsDeleted      int64
+	ErrorMessage     string    `gorm:"type:text"`
+	StartedAt        time.Time `gorm:"index"`
+	FinishedAt       time.Time
+}
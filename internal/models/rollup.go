@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"cosmos-tracker/pkg/numeric"
+)
+
+// Rollup period granularities
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"  // ISO week, Monday to Sunday
+	PeriodMonth = "month" // calendar month
+)
+
+// DelegationRollup holds OHLC-style statistics of one delegator's stake with a validator over a period.
+// Days are built from hourly snapshots; weeks and months are built from the days they contain.
+type DelegationRollup struct {
+	ID               uint        `gorm:"primaryKey"`
+	WatchlistID      uint        `gorm:"index"`
+	ChainID          string      `gorm:"type:varchar(64);uniqueIndex:,composite:rollup_period,priority:1"`
+	ValidatorAddress string      `gorm:"uniqueIndex:,composite:rollup_period,priority:2"`
+	PeriodStart      time.Time   `gorm:"type:date;uniqueIndex:,composite:rollup_period,priority:3"` // first calendar day
+	DelegatorAddress string      `gorm:"uniqueIndex:,composite:rollup_period,priority:4;index"`
+	PeriodEnd        time.Time   `gorm:"type:date"`        // last calendar day, inclusive
+	Label            string      `gorm:"type:varchar(16)"` // 2024-05-01, 2024-W18 or 2024-05
+	Timezone         string      `gorm:"type:varchar(64)"` // timezone whose midnights bounded the period
+	OpenAmount       numeric.Int // first snapshot in the period
+	CloseAmount      numeric.Int // last snapshot in the period
+	MinAmount        numeric.Int
+	MaxAmount        numeric.Int
+	NetChange        numeric.Int // sum of snapshot changes, i.e. close minus the previous period's close
+	OpenHeight       int64
+	CloseHeight      int64
+	Snapshots        int64     // runs that observed the position, including those it was carried into unchanged
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+}
+
+// DailyDelegationRollup is a DelegationRollup per calendar day
+type DailyDelegationRollup struct {
+	DelegationRollup
+}
+
+// WeeklyDelegationRollup is a DelegationRollup per ISO week
+type WeeklyDelegationRollup struct {
+	DelegationRollup
+}
+
+// MonthlyDelegationRollup is a DelegationRollup per calendar month
+type MonthlyDelegationRollup struct {
+	DelegationRollup
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"cosmos-tracker/pkg/numeric"
+)
+
+// ValidatorStats aggregates every delegator of a validator over one collection run or one day
+type ValidatorStats struct {
+	ID               uint        `gorm:"primaryKey"`
+	WatchlistID      uint        `gorm:"index"`
+	ChainID          string      `gorm:"type:varchar(64);index:,composite:validator_stats,priority:1"`
+	ValidatorAddress string      `gorm:"index:,composite:validator_stats,priority:2"`
+	BlockHeight      int64       // height the period closed at
+	TotalDelegated   numeric.Int // stake delegated at the end of the period
+	DelegatorCount   int64       // delegators holding stake at the end of the period
+	NewDelegators    int64       // delegators that joined, including returning ones
+	ExitedDelegators int64       // delegators that fully undelegated
+	Inflow           numeric.Int // sum of increases
+	Outflow          numeric.Int // sum of decreases, as a positive amount
+	NetFlow          numeric.Int // inflow minus outflow
+}
+
+// HourlyValidatorStats is written by every collection run
+type HourlyValidatorStats struct {
+	ValidatorStats
+	Timestamp time.Time `gorm:"index:,composite:validator_stats,priority:3"` // when the run recorded the snapshot
+}
+
+// DailyValidatorStats is built from the day's hourly stats during daily aggregation
+type DailyValidatorStats struct {
+	ValidatorStats
+	Date     time.Time `gorm:"type:date;index:,composite:validator_stats,priority:3"` // calendar day in the aggregation timezone
+	Timezone string    `gorm:"type:varchar(64)"`                                      // timezone whose midnights bounded the day
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"cosmos-tracker/pkg/numeric"
+)
+
+// Unbonding entry lifecycle states
+const (
+	UnbondingPending   = "pending"
+	UnbondingCompleted = "completed"
+	UnbondingCancelled = "cancelled"
+)
+
+// UnbondingDelegation is one entry in a delegator's unbonding queue for a watched validator
+type UnbondingDelegation struct {
+	ID               uint      `gorm:"primaryKey"`
+	WatchlistID      uint      `gorm:"index"`
+	Watchlist        Watchlist `gorm:"foreignKey:WatchlistID"`
+	ChainID          string    `gorm:"type:varchar(64);uniqueIndex:idx_unbonding_entry"`
+	ValidatorAddress string    `gorm:"uniqueIndex:idx_unbonding_entry"`
+	DelegatorAddress string    `gorm:"uniqueIndex:idx_unbonding_entry;index"`
+	CreationHeight   int64     `gorm:"uniqueIndex:idx_unbonding_entry"` // the SDK merges entries created in the same block
+	CompletionTime   time.Time `gorm:"index"`
+	InitialBalance   numeric.Int
+	Balance          numeric.Int
+	Status           string    `gorm:"type:varchar(20);index"`
+	LastSeenHeight   int64     // block height of the last snapshot that still listed the entry
+	FirstSeenAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"cosmos-tracker/pkg/numeric"
+)
+
+// ValidatorSnapshot records validator-level state each collection run
+type ValidatorSnapshot struct {
+	ID                      uint      `gorm:"primaryKey"`
+	WatchlistID             uint      `gorm:"index"`
+	Watchlist               Watchlist `gorm:"foreignKey:WatchlistID"`
+	ChainID                 string    `gorm:"type:varchar(64);index:idx_validator_snapshot,priority:1;uniqueIndex:idx_validator_snapshots_run,priority:1"`
+	ValidatorAddress        string    `gorm:"index:idx_validator_snapshot,priority:2;uniqueIndex:idx_validator_snapshots_run,priority:2"`
+	RunID                   string    `gorm:"type:varchar(32);uniqueIndex:idx_validator_snapshots_run,priority:3"` // collection run that wrote the row
+	Moniker                 string    `gorm:"type:varchar(255)"`
+	Website                 string    `gorm:"type:varchar(255)"`
+	Tokens                  numeric.Int
+	DelegatorShares         numeric.Dec
+	CommissionRate          numeric.Dec
+	CommissionMaxRate       numeric.Dec
+	CommissionMaxChangeRate numeric.Dec
+	Jailed                  bool
+	Status                  string  `gorm:"type:varchar(40)"`
+	VotingPower             int64   // consensus power, tokens divided by the chain's power reduction
+	VotingPowerShare        float64 // share of the chain's bonded tokens, 0-1
+	BlockHeight             int64   `gorm:"index"`
+	BlockTime               time.Time
+	Timestamp               time.Time `gorm:"autoCreateTime;index:idx_validator_snapshot,priority:3"` // start of the collection run
+}
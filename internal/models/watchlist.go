@@ -3,6 +3,7 @@ package models
 // Watchlist represents a validator-delegator pair to track
 type Watchlist struct {
 	ID               uint   `gorm:"primaryKey" json:"id"`
+	ChainID          string `gorm:"type:varchar(64);index;default:'cosmoshub-4'" json:"chain_id"`
 	ValidatorAddress string `gorm:"index" json:"validator_address"`
 	ValidatorName    string `gorm:"type:varchar(100)" json:"validator_name"`
 }
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/pkg/numeric"
+)
+
+// per-delegator statistics of one rollup period, read from snapshots for days and from day rollups otherwise
+type rollupStats struct {
+	DelegatorAddress string
+	OpenAmount       numeric.Int
+	CloseAmount      numeric.Int
+	MinAmount        numeric.Int
+	MaxAmount        numeric.Int
+	NetChange        numeric.Int // set by netChanges from the first row and the close
+	FirstAmount      numeric.Int // amount after the first row of the period, i.e. the first day's close for weeks and months
+	FirstChange      numeric.Int // change made by that row
+	OpenHeight       int64
+	CloseHeight      int64
+	Snapshots        int64
+	OpenTime         time.Time // when the opening snapshot was taken; day rollups only
+}
+
+// converts a day in the aggregation timezone to the value stored in a date column,
+// midnight UTC of the same calendar date, so no driver shifts it across a boundary
+func calendarDate(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// returned for a table that retention does not prune
+func errNotRetained(table string) error {
+	return fmt.Errorf("table %s is not pruned by retention", table)
+}
+
+// returns the snapshots a day's rows are built from, by delegator: exits are reported on the day
+// they happened and dropped afterwards
+func dailySnapshots(latest map[string]models.HourlyDelegation, day time.Time) []models.HourlyDelegation {
+	snapshots := make([]models.HourlyDelegation, 0, len(latest))
+	for _, s := range latest {
+		if !s.Exited || !s.Timestamp.Before(day) {
+			snapshots = append(snapshots, s)
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].DelegatorAddress < snapshots[j].DelegatorAddress })
+	return snapshots
+}
+
+// builds the daily rows of an aggregated day from its snapshots
+func dailyRows(aggregation DayAggregation, snapshots []models.HourlyDelegation) []models.DailyDelegation {
+	watchlist := aggregation.Watchlist
+	daily := make([]models.DailyDelegation, len(snapshots))
+	for i, s := range snapshots {
+		daily[i] = models.DailyDelegation{
+			WatchlistID:      watchlist.ID,
+			ChainID:          watchlist.ChainID,
+			ValidatorAddress: watchlist.ValidatorAddress,
+			DelegatorAddress: s.DelegatorAddress,
+			TotalDelegation:  s.DelegationAmount,
+			TotalShares:      s.Shares,
+			BlockHeight:      s.BlockHeight,
+			BlockTime:        s.BlockTime,
+			Date:             calendarDate(aggregation.Day.Start),
+			Timezone:         aggregation.Timezone,
+		}
+	}
+	return daily
+}
+
+// sets each delegator's net change. Every change is the difference to the row before it, so the
+// changes of a period add up to its close minus the amount held before its first row; computing that
+// in Go keeps it exact where SQLite stores amounts as text and can't sum them.
+func netChanges(stats []rollupStats) {
+	for i := range stats {
+		s := &stats[i]
+		s.NetChange = s.CloseAmount.Sub(s.FirstAmount.Sub(s.FirstChange))
+	}
+}
+
+// folds positions held before the day, each delegator's latest snapshot in bases, into its rollup. A
+// delegator whose first row of the day came after the first run held its previous amount until then,
+// and one without rows that day (change-only storage) held it all day. With full storage both cases
+// only arise for runs that missed a delegator.
+func carryForwardRollups(stats []rollupStats, bases map[string]models.HourlyDelegation, firstRun time.Time) []rollupStats {
+	for i := range stats {
+		s := &stats[i]
+		base, ok := bases[s.DelegatorAddress]
+		delete(bases, s.DelegatorAddress)
+		if !ok || base.Exited || !s.OpenTime.After(firstRun) {
+			continue
+		}
+		s.OpenAmount, s.OpenHeight = base.DelegationAmount, base.BlockHeight
+		if base.DelegationAmount.Cmp(s.MinAmount) < 0 {
+			s.MinAmount = base.DelegationAmount
+		}
+		if base.DelegationAmount.Cmp(s.MaxAmount) > 0 {
+			s.MaxAmount = base.DelegationAmount
+		}
+	}
+
+	carried := make([]string, 0, len(bases))
+	for delegatorAddress, base := range bases {
+		if !base.Exited {
+			carried = append(carried, delegatorAddress)
+		}
+	}
+	sort.Strings(carried)
+
+	for _, delegatorAddress := range carried {
+		base := bases[delegatorAddress]
+		stats = append(stats, rollupStats{
+			DelegatorAddress: delegatorAddress,
+			OpenAmount:       base.DelegationAmount,
+			CloseAmount:      base.DelegationAmount,
+			MinAmount:        base.DelegationAmount,
+			MaxAmount:        base.DelegationAmount,
+			NetChange:        numeric.NewInt(0),
+			OpenHeight:       base.BlockHeight,
+			CloseHeight:      base.BlockHeight,
+		})
+	}
+	return stats
+}
+
+// sets each delegator's snapshot count to the day's runs that observed its position, so positions
+// carried unchanged through change-only storage count the runs they were carried into. Delegators
+// without recorded runs keep the number of their stored rows.
+func countRuns(stats []rollupStats, runs map[string]int64) {
+	for i := range stats {
+		if n, ok := runs[stats[i].DelegatorAddress]; ok {
+			stats[i].Snapshots = n
+		}
+	}
+}
+
+// builds the rollup rows of one period
+func rollupRows(aggregation DayAggregation, period RollupPeriod, stats []rollupStats) []models.DelegationRollup {
+	watchlist := aggregation.Watchlist
+	rollups := make([]models.DelegationRollup, len(stats))
+	for i, s := range stats {
+		rollups[i] = models.DelegationRollup{
+			WatchlistID:      watchlist.ID,
+			ChainID:          watchlist.ChainID,
+			ValidatorAddress: watchlist.ValidatorAddress,
+			DelegatorAddress: s.DelegatorAddress,
+			PeriodStart:      calendarDate(period.Start),
+			PeriodEnd:        calendarDate(period.End.AddDate(0, 0, -1)),
+			Label:            period.Label,
+			Timezone:         aggregation.Timezone,
+			OpenAmount:       s.OpenAmount,
+			CloseAmount:      s.CloseAmount,
+			MinAmount:        s.MinAmount,
+			MaxAmount:        s.MaxAmount,
+			NetChange:        s.NetChange,
+			OpenHeight:       s.OpenHeight,
+			CloseHeight:      s.CloseHeight,
+			Snapshots:        s.Snapshots,
+		}
+	}
+	return rollups
+}
+
+// totals a day's hourly validator stats, oldest first: flows add up across the day, while stake and
+// delegator count are taken at the close
+func dailyValidatorStats(aggregation DayAggregation, hours []models.HourlyValidatorStats) models.DailyValidatorStats {
+	watchlist := aggregation.Watchlist
+	closing := hours[len(hours)-1]
+	stats := models.ValidatorStats{
+		WatchlistID:      watchlist.ID,
+		ChainID:          watchlist.ChainID,
+		ValidatorAddress: watchlist.ValidatorAddress,
+		BlockHeight:      closing.BlockHeight,
+		TotalDelegated:   closing.TotalDelegated,
+		DelegatorCount:   closing.DelegatorCount,
+	}
+	for _, h := range hours {
+		stats.NewDelegators += h.NewDelegators
+		stats.ExitedDelegators += h.ExitedDelegators
+		stats.Inflow = stats.Inflow.Add(h.Inflow)
+		stats.Outflow = stats.Outflow.Add(h.Outflow)
+	}
+	stats.NetFlow = stats.Inflow.Sub(stats.Outflow)
+
+	return models.DailyValidatorStats{
+		ValidatorStats: stats,
+		Date:           calendarDate(aggregation.Day.Start),
+		Timezone:       aggregation.Timezone,
+	}
+}
@@ -0,0 +1,1147 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/pkg/db"
+	"cosmos-tracker/pkg/numeric"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Rows per INSERT when writing a snapshot; a 50k-delegator validator takes 500 statements
+// instead of one per delegator, and each stays far below the 65535 bind parameter limit
+const SnapshotBatchSize = 100
+
+// returns repositories backed by a GORM connection
+func NewGorm(database *gorm.DB) Repositories {
+	return Repositories{
+		Delegations:   gormDelegations{database},
+		Watchlist:     gormWatchlist{database},
+		Snapshots:     gormSnapshots{database},
+		Unbondings:    gormUnbondings{database},
+		Redelegations: gormRedelegations{database},
+		Validators:    gormValidators{database},
+		Health:        gormHealth{database},
+		Aggregation:   gormAggregation{database},
+		Retention:     gormRetention{database},
+		Migrations:    gormMigrations{database},
+	}
+}
+
+type gormDelegations struct {
+	db *gorm.DB
+}
+
+// narrows a delegation query to one validator and the optional delegator and height
+func (r gormDelegations) scope(ctx context.Context, model interface{}, query DelegationQuery) *gorm.DB {
+	scoped := r.db.WithContext(ctx).Model(model).
+		Where("chain_id = ? AND validator_address = ?", query.ChainID, query.ValidatorAddress)
+	if query.DelegatorAddress != "" {
+		scoped = scoped.Where("delegator_address = ?", query.DelegatorAddress)
+	}
+	if query.Height > 0 {
+		scoped = scoped.Where("block_height = ?", query.Height)
+	}
+	return scoped
+}
+
+// returns the query's inclusive time and height range as SQL conditions over the given columns
+func rangeConditions(query DelegationQuery, timeColumn, heightColumn string) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if !query.FromTime.IsZero() {
+		conditions = append(conditions, timeColumn+" >= ?")
+		args = append(args, query.FromTime)
+	}
+	if !query.ToTime.IsZero() {
+		conditions = append(conditions, timeColumn+" <= ?")
+		args = append(args, query.ToTime)
+	}
+	if query.FromHeight > 0 {
+		conditions = append(conditions, heightColumn+" >= ?")
+		args = append(args, query.FromHeight)
+	}
+	if query.ToHeight > 0 {
+		conditions = append(conditions, heightColumn+" <= ?")
+		args = append(args, query.ToHeight)
+	}
+	return conditions, args
+}
+
+// returns the query's amount and change filters as SQL conditions over the given columns; an empty
+// change column skips the change filters. Every bound is an amount argument rather than a literal or
+// ABS, so SQLite compares its sortable text with text in the same encoding.
+func amountConditions(query DelegationQuery, amountColumn, changeColumn string) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if query.MinAmount != nil {
+		conditions = append(conditions, amountColumn+" >= ?")
+		args = append(args, *query.MinAmount)
+	}
+	if changeColumn == "" {
+		return conditions, args
+	}
+	if query.MinChange != nil {
+		conditions = append(conditions, "("+changeColumn+" >= ? OR "+changeColumn+" <= ?)")
+		args = append(args, *query.MinChange, query.MinChange.Neg())
+	}
+	switch query.ChangeSign {
+	case 1:
+		conditions = append(conditions, changeColumn+" > ?")
+		args = append(args, numeric.NewInt(0))
+	case -1:
+		conditions = append(conditions, changeColumn+" < ?")
+		args = append(args, numeric.NewInt(0))
+	}
+	return conditions, args
+}
+
+// narrows a scoped query by the given conditions
+func filtered(scoped *gorm.DB, conditions []string, args []interface{}) *gorm.DB {
+	if len(conditions) == 0 {
+		return scoped
+	}
+	return scoped.Where(strings.Join(conditions, " AND "), args...)
+}
+
+// returns the ORDER BY direction for a query
+func direction(query DelegationQuery) string {
+	if query.Ascending {
+		return "ASC"
+	}
+	return "DESC"
+}
+
+func (r gormDelegations) HourlyDelegations(ctx context.Context, query DelegationQuery) ([]models.HourlyDelegation, int64, error) {
+	conditions, args := rangeConditions(query, "timestamp", "block_height")
+	amounts, amountArgs := amountConditions(query, "delegation_amount", "change_amount")
+	scoped := filtered(r.scope(ctx, &models.HourlyDelegation{}, query), append(conditions, amounts...), append(args, amountArgs...))
+
+	var total int64
+	if err := scoped.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []models.HourlyDelegation
+	order := direction(query)
+	if err := scoped.Order("timestamp " + order + ", id " + order).
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+// Every delegator's rows joined to the runs they cover: a row spans from its own run until the
+// delegator's next row, and exit rows only appear at their own run. %s narrows the spans, %s the runs.
+const denseDelegationsQuery = `WITH spans AS (
+	SELECT h.*, LEAD(h.timestamp) OVER (PARTITION BY h.delegator_address ORDER BY h.id) AS next_timestamp
+	FROM hourly_delegations h
+	WHERE h.chain_id = ? AND h.validator_address = ?%s
+)
+SELECT s.*, r.timestamp AS run_timestamp, r.block_height AS run_height, r.block_time AS run_block_time
+FROM collection_heartbeats r
+JOIN spans s ON s.timestamp <= r.timestamp AND (s.next_timestamp IS NULL OR r.timestamp < s.next_timestamp)
+WHERE r.chain_id = ? AND r.validator_address = ?%s
+	AND (NOT s.exited OR s.timestamp = r.timestamp)`
+
+// returns when the last full run at or before the query's first selected run was taken, or zero when
+// the query has no lower bound or no such run. A full run stores every staked delegator, so spans
+// never need an earlier row, and a time bound lets Postgres skip older partitions.
+func (r gormDelegations) denseSpanStart(database *gorm.DB, query DelegationQuery) (time.Time, error) {
+	var bounds []string
+	var args []interface{}
+	if !query.FromTime.IsZero() {
+		bounds = append(bounds, "timestamp <= ?")
+		args = append(args, query.FromTime)
+	}
+	for _, height := range []int64{query.FromHeight, query.Height} {
+		if height > 0 {
+			bounds = append(bounds, "block_height <= ?")
+			args = append(args, height)
+		}
+	}
+	if len(bounds) == 0 {
+		return time.Time{}, nil
+	}
+
+	var timestamps []time.Time
+	err := database.Model(&models.CollectionHeartbeat{}).
+		Where("chain_id = ? AND validator_address = ? AND storage_mode = ?", query.ChainID, query.ValidatorAddress, config.StorageModeFull).
+		Where("("+strings.Join(bounds, " OR ")+")", args...).
+		Order("timestamp DESC").
+		Limit(1).
+		Pluck("timestamp", &timestamps).Error
+	if err != nil || len(timestamps) == 0 {
+		return time.Time{}, err
+	}
+	return timestamps[0], nil
+}
+
+func (r gormDelegations) DenseDelegations(ctx context.Context, query DelegationQuery) ([]DenseDelegation, int64, error) {
+	database := r.db.WithContext(ctx)
+	spanStart, err := r.denseSpanStart(database, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Spans only need rows from the last full run before the range up to its end
+	var spanFilter, runFilter string
+	args := []interface{}{query.ChainID, query.ValidatorAddress}
+	if query.DelegatorAddress != "" {
+		spanFilter = " AND h.delegator_address = ?"
+		args = append(args, query.DelegatorAddress)
+	}
+	if !spanStart.IsZero() {
+		spanFilter += " AND h.timestamp >= ?"
+		args = append(args, spanStart)
+	}
+	if !query.ToTime.IsZero() {
+		spanFilter += " AND h.timestamp <= ?"
+		args = append(args, query.ToTime)
+	}
+	for _, height := range []int64{query.ToHeight, query.Height} {
+		if height > 0 {
+			spanFilter += " AND h.block_height <= ?"
+			args = append(args, height)
+		}
+	}
+	args = append(args, query.ChainID, query.ValidatorAddress)
+	if query.Height > 0 {
+		runFilter = " AND r.block_height = ?"
+		args = append(args, query.Height)
+	}
+
+	conditions, rangeArgs := rangeConditions(query, "r.timestamp", "r.block_height")
+	amounts, amountArgs := amountConditions(query, "s.delegation_amount", "s.change_amount")
+	if query.MinChange != nil || query.ChangeSign != 0 {
+		amounts = append(amounts, "s.timestamp = r.timestamp") // carried rows have not changed
+	}
+	for _, condition := range append(conditions, amounts...) {
+		runFilter += " AND " + condition
+	}
+	args = append(append(args, rangeArgs...), amountArgs...)
+	dense := fmt.Sprintf(denseDelegationsQuery, spanFilter, runFilter)
+
+	var total int64
+	if err := database.Raw("SELECT COUNT(*) FROM ("+dense+") dense", args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []DenseDelegation
+	if err := database.Raw(dense+" ORDER BY r.timestamp "+direction(query)+", s.delegator_address ASC LIMIT ? OFFSET ?",
+		append(args, query.Limit, query.Offset)...).Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+func (r gormDelegations) DailyDelegations(ctx context.Context, query DelegationQuery) ([]models.DailyDelegation, int64, error) {
+	conditions, args := rangeConditions(query, "date", "block_height")
+	amounts, amountArgs := amountConditions(query, "total_delegation", "")
+	scoped := filtered(r.scope(ctx, &models.DailyDelegation{}, query), append(conditions, amounts...), append(args, amountArgs...))
+
+	var total int64
+	if err := scoped.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []models.DailyDelegation
+	if err := scoped.Order("date " + direction(query) + ", delegator_address ASC").
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+func (r gormDelegations) CurrentDelegators(ctx context.Context, query DelegationQuery) ([]models.CurrentDelegation, CurrentTotals, error) {
+	scoped := r.db.WithContext(ctx).Model(&models.CurrentDelegation{}).
+		Where("chain_id = ? AND validator_address = ? AND exited = ?", query.ChainID, query.ValidatorAddress, false)
+
+	// summed in Go, since SQLite stores amounts as text
+	var amounts []numeric.Int
+	totals := CurrentTotals{TotalStake: numeric.NewInt(0)}
+	if err := scoped.Session(&gorm.Session{}).Pluck("delegation_amount", &amounts).Error; err != nil {
+		return nil, totals, err
+	}
+	for _, amount := range amounts {
+		totals.TotalStake = totals.TotalStake.Add(amount)
+	}
+	totals.TotalDelegators = int64(len(amounts))
+
+	var rows []models.CurrentDelegation
+	if err := scoped.Order("delegation_amount DESC, delegator_address ASC").
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&rows).Error; err != nil {
+		return nil, totals, err
+	}
+	return rows, totals, nil
+}
+
+func (r gormDelegations) CurrentDelegation(ctx context.Context, chainID, validatorAddress, delegatorAddress string) (models.CurrentDelegation, error) {
+	var position models.CurrentDelegation
+	err := r.db.WithContext(ctx).
+		Where("chain_id = ? AND validator_address = ? AND delegator_address = ? AND exited = ?",
+			chainID, validatorAddress, delegatorAddress, false).
+		First(&position).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return position, ErrNotFound
+	}
+	return position, err
+}
+
+func (r gormDelegations) Rollups(ctx context.Context, period string, query DelegationQuery) ([]models.DelegationRollup, int64, error) {
+	scoped := r.db.WithContext(ctx).Table(RollupTables[period]).
+		Where("chain_id = ? AND validator_address = ?", query.ChainID, query.ValidatorAddress)
+
+	var total int64
+	if err := scoped.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []models.DelegationRollup
+	if err := scoped.Order("period_start DESC, delegator_address ASC").
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+type gormWatchlist struct {
+	db *gorm.DB
+}
+
+func (r gormWatchlist) CreateWatchlist(ctx context.Context, entry *models.Watchlist) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r gormWatchlist) ListWatchlist(ctx context.Context, chainID string) ([]models.Watchlist, error) {
+	query := r.db.WithContext(ctx)
+	if chainID != "" {
+		query = query.Where("chain_id = ?", chainID)
+	}
+
+	var entries []models.Watchlist
+	if err := query.Order("id ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r gormWatchlist) FindWatchlist(ctx context.Context, chainID, validatorAddress string) (models.Watchlist, error) {
+	var entry models.Watchlist
+	err := r.db.WithContext(ctx).
+		Where("chain_id = ? AND validator_address = ?", chainID, validatorAddress).
+		First(&entry).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return entry, ErrNotFound
+	}
+	return entry, err
+}
+
+func (r gormWatchlist) DeleteWatchlist(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Watchlist{}, id).Error
+}
+
+type gormSnapshots struct {
+	db *gorm.DB
+}
+
+// seeds from the hourly history the first time a validator is seen without current positions
+func (r gormSnapshots) CurrentPositions(ctx context.Context, chainID, validatorAddress string) (map[string]models.CurrentDelegation, error) {
+	database := r.db.WithContext(ctx)
+
+	var rows []models.CurrentDelegation
+	if err := database.Where("chain_id = ? AND validator_address = ?", chainID, validatorAddress).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]models.CurrentDelegation, len(rows))
+	for _, row := range rows {
+		positions[row.DelegatorAddress] = row
+	}
+	if len(positions) > 0 {
+		return positions, nil
+	}
+
+	history, err := LatestSnapshots(database, chainID, validatorAddress, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	for delegatorAddress, snapshot := range history {
+		positions[delegatorAddress] = models.CurrentDelegation{
+			DelegationAmount: snapshot.DelegationAmount,
+			Shares:           snapshot.Shares,
+			Exited:           snapshot.Exited,
+		}
+	}
+	return positions, nil
+}
+
+func (r gormSnapshots) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The heartbeat claims the run first; a concurrent writer of the same run waits on its
+		// unique key and then finds it taken
+		claim := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chain_id"}, {Name: "validator_address"}, {Name: "run_id"}},
+			DoNothing: true,
+		}).Create(&snapshot.Heartbeat)
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			return ErrRunExists
+		}
+
+		if len(snapshot.Delegations) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{
+					{Name: "chain_id"}, {Name: "validator_address"}, {Name: "delegator_address"}, {Name: "run_id"}, {Name: "timestamp"},
+				},
+				DoNothing: true,
+			}).CreateInBatches(snapshot.Delegations, SnapshotBatchSize).Error; err != nil {
+				return err
+			}
+			if err := upsertCurrentDelegations(tx, snapshot.Delegations); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&snapshot.Stats).Error
+	})
+}
+
+// writes the positions recorded by a snapshot into the current-state table
+func upsertCurrentDelegations(tx *gorm.DB, snapshots []models.HourlyDelegation) error {
+	positions := make([]models.CurrentDelegation, len(snapshots))
+	for i, s := range snapshots {
+		positions[i] = models.CurrentDelegation{
+			WatchlistID:      s.WatchlistID,
+			ChainID:          s.ChainID,
+			ValidatorAddress: s.ValidatorAddress,
+			DelegatorAddress: s.DelegatorAddress,
+			DelegationAmount: s.DelegationAmount,
+			Shares:           s.Shares,
+			Exited:           s.Exited,
+			SnapshotID:       s.ID,
+			BlockHeight:      s.BlockHeight,
+			BlockTime:        s.BlockTime,
+		}
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "chain_id"}, {Name: "validator_address"}, {Name: "delegator_address"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"watchlist_id", "delegation_amount", "shares", "exited", "snapshot_id", "block_height", "block_time", "updated_at",
+		}),
+	}).CreateInBatches(positions, SnapshotBatchSize).Error
+}
+
+// returns every delegator's latest snapshot recorded before a time, keyed by address. Only rows at or
+// after since are considered, which lets Postgres skip older monthly partitions; a zero bound is open.
+func LatestSnapshots(tx *gorm.DB, chainID, validatorAddress string, since, before time.Time) (map[string]models.HourlyDelegation, error) {
+	window := func(query *gorm.DB) *gorm.DB {
+		if !since.IsZero() {
+			query = query.Where("timestamp >= ?", since)
+		}
+		if !before.IsZero() {
+			query = query.Where("timestamp < ?", before)
+		}
+		return query
+	}
+	latest := window(tx.Model(&models.HourlyDelegation{}).
+		Select("MAX(id)").
+		Where("chain_id = ? AND validator_address = ?", chainID, validatorAddress)).
+		Group("delegator_address")
+
+	var rows []models.HourlyDelegation
+	if err := window(tx.Where("id IN (?)", latest)).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	snapshots := make(map[string]models.HourlyDelegation, len(rows))
+	for _, row := range rows {
+		snapshots[row.DelegatorAddress] = row
+	}
+	return snapshots, nil
+}
+
+type gormUnbondings struct {
+	db *gorm.DB
+}
+
+func (r gormUnbondings) SaveUnbondings(ctx context.Context, chainID, validatorAddress string, unbondings []models.UnbondingDelegation, height int64, blockTime time.Time) (UnbondingSettlement, error) {
+	var settlement UnbondingSettlement
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(unbondings) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{
+					{Name: "chain_id"}, {Name: "validator_address"}, {Name: "delegator_address"}, {Name: "creation_height"},
+				},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"completion_time", "initial_balance", "balance", "status", "last_seen_height", "updated_at",
+				}),
+			}).CreateInBatches(&unbondings, 500).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// Entries no longer listed either matured or were cancelled
+		settled := tx.Model(&models.UnbondingDelegation{}).
+			Where("chain_id = ? AND validator_address = ? AND status = ? AND last_seen_height < ?",
+				chainID, validatorAddress, models.UnbondingPending, height)
+
+		completed := settled.Session(&gorm.Session{}).Where("completion_time <= ?", blockTime).
+			Update("status", models.UnbondingCompleted)
+		if completed.Error != nil {
+			return completed.Error
+		}
+
+		cancelled := settled.Session(&gorm.Session{}).Where("completion_time > ?", blockTime).
+			Update("status", models.UnbondingCancelled)
+		if cancelled.Error != nil {
+			return cancelled.Error
+		}
+
+		settlement = UnbondingSettlement{Completed: completed.RowsAffected, Cancelled: cancelled.RowsAffected}
+		return nil
+	})
+	return settlement, err
+}
+
+func (r gormUnbondings) PendingUnbondings(ctx context.Context, query UnbondingQuery) ([]models.UnbondingDelegation, int64, error) {
+	scoped := r.db.WithContext(ctx).Model(&models.UnbondingDelegation{}).
+		Where("chain_id = ? AND validator_address = ? AND status = ? AND completion_time > ?",
+			query.ChainID, query.ValidatorAddress, models.UnbondingPending, query.After)
+	if !query.Until.IsZero() {
+		scoped = scoped.Where("completion_time <= ?", query.Until)
+	}
+
+	var total int64
+	if err := scoped.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []models.UnbondingDelegation
+	if err := scoped.Order("completion_time ASC, id ASC").
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+type gormRedelegations struct {
+	db *gorm.DB
+}
+
+// narrows redelegations to one direction of a validator, or both when direction is empty
+func (r gormRedelegations) scope(ctx context.Context, chainID, validatorAddress, direction string) *gorm.DB {
+	scoped := r.db.WithContext(ctx).Model(&models.Redelegation{}).Where("chain_id = ?", chainID)
+	switch direction {
+	case models.RedelegationIn:
+		return scoped.Where("dst_validator_address = ?", validatorAddress)
+	case models.RedelegationOut:
+		return scoped.Where("src_validator_address = ?", validatorAddress)
+	default:
+		return scoped.Where("src_validator_address = ? OR dst_validator_address = ?", validatorAddress, validatorAddress)
+	}
+}
+
+func (r gormRedelegations) RedelegationCursor(ctx context.Context, chainID, validatorAddress, direction string) (int64, error) {
+	var cursor models.RedelegationScanCursor
+	err := r.db.WithContext(ctx).
+		Where("chain_id = ? AND validator_address = ? AND direction = ?", chainID, validatorAddress, direction).
+		Limit(1).
+		Find(&cursor).Error
+	return cursor.ScannedHeight, err
+}
+
+// the same tx is found again when both of its validators are watched, or when a capped scan
+// resumes inside a height, so duplicates are ignored
+func (r gormRedelegations) SaveRedelegations(ctx context.Context, redelegations []models.Redelegation, cursors []models.RedelegationScanCursor) (int64, error) {
+	var saved int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(redelegations) > 0 {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&redelegations, 500)
+			if result.Error != nil {
+				return result.Error
+			}
+			saved = result.RowsAffected
+		}
+		if len(cursors) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chain_id"}, {Name: "validator_address"}, {Name: "direction"}},
+			DoUpdates: clause.AssignmentColumns([]string{"scanned_height", "updated_at"}),
+		}).Create(&cursors).Error
+	})
+	return saved, err
+}
+
+func (r gormRedelegations) Redelegations(ctx context.Context, query RedelegationQuery) ([]models.Redelegation, int64, error) {
+	scoped := r.scope(ctx, query.ChainID, query.ValidatorAddress, query.Direction)
+
+	var total int64
+	if err := scoped.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []models.Redelegation
+	if err := scoped.Order("height DESC, id DESC").
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+func (r gormRedelegations) RedelegationSummary(ctx context.Context, chainID, validatorAddress, direction string) ([]RedelegationCounterpart, error) {
+	// summed in Go, since SQLite stores amounts as text
+	var redelegations []models.Redelegation
+	if err := r.scope(ctx, chainID, validatorAddress, direction).
+		Select("src_validator_address, dst_validator_address, amount").
+		Find(&redelegations).Error; err != nil {
+		return nil, err
+	}
+	return summarizeRedelegations(redelegations, direction), nil
+}
+
+type gormValidators struct {
+	db *gorm.DB
+}
+
+// a run that already stored its snapshot writes nothing
+func (r gormValidators) SaveValidatorSnapshot(ctx context.Context, snapshot models.ValidatorSnapshot, rename bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		created := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chain_id"}, {Name: "validator_address"}, {Name: "run_id"}},
+			DoNothing: true,
+		}).Create(&snapshot)
+		if created.Error != nil {
+			return created.Error
+		}
+		if created.RowsAffected == 0 || !rename {
+			return nil
+		}
+		return tx.Model(&models.Watchlist{}).
+			Where("id = ?", snapshot.WatchlistID).
+			Update("validator_name", snapshot.Moniker).Error
+	})
+}
+
+// pages any per-validator table newest first by the given order
+func pageValidatorRows[T any](ctx context.Context, database *gorm.DB, query PageQuery, order string) ([]T, int64, error) {
+	scoped := database.WithContext(ctx).Model(new(T)).
+		Where("chain_id = ? AND validator_address = ?", query.ChainID, query.ValidatorAddress)
+
+	var total int64
+	if err := scoped.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []T
+	if err := scoped.Order(order).Limit(query.Limit).Offset(query.Offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+func (r gormValidators) ValidatorSnapshots(ctx context.Context, query PageQuery) ([]models.ValidatorSnapshot, int64, error) {
+	return pageValidatorRows[models.ValidatorSnapshot](ctx, r.db, query, "timestamp DESC, id DESC")
+}
+
+func (r gormValidators) LatestValidatorSnapshot(ctx context.Context, chainID, validatorAddress string) (models.ValidatorSnapshot, error) {
+	var snapshot models.ValidatorSnapshot
+	err := r.db.WithContext(ctx).
+		Where("chain_id = ? AND validator_address = ?", chainID, validatorAddress).
+		Order("timestamp DESC, id DESC").
+		First(&snapshot).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return snapshot, ErrNotFound
+	}
+	return snapshot, err
+}
+
+func (r gormValidators) HourlyValidatorStats(ctx context.Context, query PageQuery) ([]models.HourlyValidatorStats, int64, error) {
+	return pageValidatorRows[models.HourlyValidatorStats](ctx, r.db, query, "timestamp DESC, id DESC")
+}
+
+func (r gormValidators) DailyValidatorStats(ctx context.Context, query PageQuery) ([]models.DailyValidatorStats, int64, error) {
+	return pageValidatorRows[models.DailyValidatorStats](ctx, r.db, query, "date DESC, id DESC")
+}
+
+type gormHealth struct {
+	db *gorm.DB
+}
+
+func (r gormHealth) Ping(ctx context.Context) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (r gormHealth) RecordCounts(ctx context.Context) (RecordCounts, error) {
+	var counts RecordCounts
+	database := r.db.WithContext(ctx)
+	for model, count := range map[interface{}]*int64{
+		&models.Watchlist{}:        &counts.WatchlistEntries,
+		&models.HourlyDelegation{}: &counts.HourlyDelegations,
+		&models.DailyDelegation{}:  &counts.DailyDelegations,
+	} {
+		if err := database.Model(model).Count(count).Error; err != nil {
+			return counts, err
+		}
+	}
+	return counts, nil
+}
+
+func (r gormHealth) LatestHeartbeat(ctx context.Context) (models.CollectionHeartbeat, error) {
+	var heartbeat models.CollectionHeartbeat
+	err := r.db.WithContext(ctx).Order("timestamp DESC, id DESC").First(&heartbeat).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return heartbeat, ErrNotFound
+	}
+	return heartbeat, err
+}
+
+// plucks a time column of the first row a query selects, or false when it selects none
+func pluckTime(query *gorm.DB, column string) (time.Time, bool, error) {
+	var times []time.Time
+	if err := query.Limit(1).Pluck(column, &times).Error; err != nil || len(times) == 0 {
+		return time.Time{}, false, err
+	}
+	return times[0], true, nil
+}
+
+type gormAggregation struct {
+	db *gorm.DB
+}
+
+func (r gormAggregation) LatestDailyDate(ctx context.Context, chainID, validatorAddress string) (time.Time, bool, error) {
+	return pluckTime(r.db.WithContext(ctx).Model(&models.DailyDelegation{}).
+		Where("chain_id = ? AND validator_address = ?", chainID, validatorAddress).
+		Order("date DESC"), "date")
+}
+
+func (r gormAggregation) FirstSnapshotTime(ctx context.Context, chainID, validatorAddress string) (time.Time, bool, error) {
+	return pluckTime(r.db.WithContext(ctx).Model(&models.HourlyDelegation{}).
+		Where("chain_id = ? AND validator_address = ?", chainID, validatorAddress).
+		Order("timestamp ASC"), "timestamp")
+}
+
+// returns the time of the first collection run in [from, to), from its heartbeats or, for data
+// collected before heartbeats existed, its hourly rows; false when nothing ran
+func firstRunBetween(tx *gorm.DB, watchlist models.Watchlist, from, to time.Time) (time.Time, bool, error) {
+	var first time.Time
+	found := false
+	for _, model := range []interface{}{&models.CollectionHeartbeat{}, &models.HourlyDelegation{}} {
+		timestamp, ok, err := pluckTime(tx.Model(model).
+			Where("chain_id = ? AND validator_address = ? AND timestamp >= ? AND timestamp < ?",
+				watchlist.ChainID, watchlist.ValidatorAddress, from, to).
+			Order("timestamp ASC"), "timestamp")
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if ok && (!found || timestamp.Before(first)) {
+			first, found = timestamp, true
+		}
+	}
+	return first, found, nil
+}
+
+// returns the time of the latest run before a time that stored every delegator, or zero when none
+// did. A delegator still staked then has a row at or after it, so older rows, and on Postgres the
+// monthly partitions holding them, never need to be read to find current positions.
+func lastFullRunBefore(tx *gorm.DB, watchlist models.Watchlist, before time.Time) (time.Time, error) {
+	timestamp, _, err := pluckTime(tx.Model(&models.CollectionHeartbeat{}).
+		Where("chain_id = ? AND validator_address = ? AND storage_mode = ? AND timestamp < ?",
+			watchlist.ChainID, watchlist.ValidatorAddress, config.StorageModeFull, before).
+		Order("timestamp DESC"), "timestamp")
+	return timestamp, err
+}
+
+// returns the cutoff of the latest pass that pruned an entry's hourly snapshots; days before it
+// can no longer be rebuilt because their carried-forward positions are gone
+func prunedBefore(tx *gorm.DB, watchlist models.Watchlist) (time.Time, error) {
+	cutoff, _, err := pluckTime(tx.Model(&models.RetentionRun{}).
+		Where(&models.RetentionRun{ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, Table: "hourly_delegations"}).
+		Order("cutoff DESC"), "cutoff")
+	return cutoff, err
+}
+
+// each delegator's last snapshot as of the end of the day is read no further back than the last full
+// run, or the day's start so that the day's exits are still seen
+func (r gormAggregation) AggregateDay(ctx context.Context, aggregation DayAggregation) (int, error) {
+	watchlist, day := aggregation.Watchlist, aggregation.Day
+	var rows int
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		pruned, err := prunedBefore(tx, watchlist)
+		if err != nil || day.Start.Before(pruned) {
+			return err
+		}
+
+		firstRun, covered, err := firstRunBetween(tx, watchlist, day.Start, day.End)
+		if err != nil || !covered {
+			return err
+		}
+
+		since, err := lastFullRunBefore(tx, watchlist, day.End)
+		if err != nil {
+			return err
+		}
+		if since.After(day.Start) {
+			since = day.Start
+		}
+		latest, err := LatestSnapshots(tx, watchlist.ChainID, watchlist.ValidatorAddress, since, day.End)
+		if err != nil {
+			return err
+		}
+		snapshots := dailySnapshots(latest, day.Start)
+		if len(snapshots) == 0 {
+			return nil
+		}
+
+		// Replace whatever an earlier pass wrote for the day; a pass running concurrently may still
+		// insert rows this delete can't see, which the upsert below overwrites instead of duplicating
+		if err := tx.Where("chain_id = ? AND validator_address = ? AND date = ?",
+			watchlist.ChainID, watchlist.ValidatorAddress, calendarDate(day.Start)).
+			Delete(&models.DailyDelegation{}).Error; err != nil {
+			return err
+		}
+
+		daily := dailyRows(aggregation, snapshots)
+		rows = len(daily)
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "chain_id"}, {Name: "validator_address"}, {Name: "delegator_address"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"watchlist_id", "total_delegation", "total_shares", "block_height", "block_time", "timezone",
+			}),
+		}).CreateInBatches(daily, SnapshotBatchSize).Error; err != nil {
+			return err
+		}
+		if err := rollupDay(tx, aggregation, firstRun); err != nil {
+			return err
+		}
+		return aggregateValidatorStats(tx, aggregation)
+	})
+
+	return rows, err
+}
+
+// Day statistics from the hourly snapshots; open and close come from the first and last row of each delegator
+const dailyRollupQuery = `SELECT s.delegator_address, s.min_amount, s.max_amount, s.snapshots,
+	o.delegation_amount AS open_amount, o.block_height AS open_height, o.timestamp AS open_time,
+	o.delegation_amount AS first_amount, o.change_amount AS first_change,
+	c.delegation_amount AS close_amount, c.block_height AS close_height
+FROM (
+	SELECT delegator_address, MIN(id) AS first_id, MAX(id) AS last_id,
+		MIN(delegation_amount) AS min_amount, MAX(delegation_amount) AS max_amount, COUNT(*) AS snapshots
+	FROM hourly_delegations
+	WHERE chain_id = ? AND validator_address = ? AND timestamp >= ? AND timestamp < ?
+	GROUP BY delegator_address
+) s
+JOIN hourly_delegations o ON o.id = s.first_id
+JOIN hourly_delegations c ON c.id = s.last_id`
+
+// Runs of the day that observed each delegator, counted like the dense series: a row covers the runs from
+// its own until the delegator's next row, and an exit row only its own run
+const dailyRunsQuery = `WITH spans AS (
+	SELECT delegator_address, timestamp, exited,
+		LEAD(timestamp) OVER (PARTITION BY delegator_address ORDER BY id) AS next_timestamp
+	FROM hourly_delegations
+	WHERE chain_id = ? AND validator_address = ? AND timestamp >= ? AND timestamp < ?
+)
+SELECT s.delegator_address, COUNT(*) AS runs
+FROM collection_heartbeats r
+JOIN spans s ON s.timestamp <= r.timestamp AND (s.next_timestamp IS NULL OR r.timestamp < s.next_timestamp)
+WHERE r.chain_id = ? AND r.validator_address = ? AND r.timestamp >= ? AND r.timestamp < ?
+	AND (NOT s.exited OR s.timestamp = r.timestamp)
+GROUP BY s.delegator_address`
+
+// Week and month statistics from the day rollups they contain
+const periodRollupQuery = `SELECT s.delegator_address, s.min_amount, s.max_amount, s.snapshots,
+	o.open_amount, o.open_height, o.close_amount AS first_amount, o.net_change AS first_change,
+	c.close_amount, c.close_height
+FROM (
+	SELECT delegator_address, MIN(period_start) AS first_day, MAX(period_start) AS last_day,
+		MIN(min_amount) AS min_amount, MAX(max_amount) AS max_amount, SUM(snapshots) AS snapshots
+	FROM daily_delegation_rollups
+	WHERE chain_id = ? AND validator_address = ? AND period_start >= ? AND period_start < ?
+	GROUP BY delegator_address
+) s
+JOIN daily_delegation_rollups o ON o.chain_id = ? AND o.validator_address = ?
+	AND o.delegator_address = s.delegator_address AND o.period_start = s.first_day
+JOIN daily_delegation_rollups c ON c.chain_id = ? AND c.validator_address = ?
+	AND c.delegator_address = s.delegator_address AND c.period_start = s.last_day`
+
+// rebuilds the rollups of an aggregated day, whose first run was at firstRun, and of the periods containing it
+func rollupDay(tx *gorm.DB, aggregation DayAggregation, firstRun time.Time) error {
+	watchlist, day := aggregation.Watchlist, aggregation.Day
+
+	var stats []rollupStats
+	if err := tx.Raw(dailyRollupQuery, watchlist.ChainID, watchlist.ValidatorAddress, day.Start, day.End).
+		Scan(&stats).Error; err != nil {
+		return err
+	}
+	netChanges(stats)
+
+	since, err := lastFullRunBefore(tx, watchlist, day.Start)
+	if err != nil {
+		return err
+	}
+	bases, err := LatestSnapshots(tx, watchlist.ChainID, watchlist.ValidatorAddress, since, day.Start)
+	if err != nil {
+		return err
+	}
+	stats = carryForwardRollups(stats, bases, firstRun)
+
+	var counts []struct {
+		DelegatorAddress string
+		Runs             int64
+	}
+	if err := tx.Raw(dailyRunsQuery,
+		watchlist.ChainID, watchlist.ValidatorAddress, since, day.End,
+		watchlist.ChainID, watchlist.ValidatorAddress, day.Start, day.End).
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+	runs := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		runs[c.DelegatorAddress] = c.Runs
+	}
+	countRuns(stats, runs)
+
+	if err := replaceRollups(tx, aggregation, day, stats); err != nil {
+		return err
+	}
+
+	for _, period := range aggregation.Periods {
+		var stats []rollupStats
+		if err := tx.Raw(periodRollupQuery,
+			watchlist.ChainID, watchlist.ValidatorAddress, calendarDate(period.Start), calendarDate(period.End),
+			watchlist.ChainID, watchlist.ValidatorAddress,
+			watchlist.ChainID, watchlist.ValidatorAddress).
+			Scan(&stats).Error; err != nil {
+			return err
+		}
+		netChanges(stats)
+		if err := replaceRollups(tx, aggregation, period, stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaces the rollup rows of one period; periods without data are left untouched so pruned history never wipes them
+func replaceRollups(tx *gorm.DB, aggregation DayAggregation, period RollupPeriod, stats []rollupStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	table := RollupTables[period.Period]
+	if err := tx.Table(table).
+		Where("chain_id = ? AND validator_address = ? AND period_start = ?",
+			aggregation.Watchlist.ChainID, aggregation.Watchlist.ValidatorAddress, calendarDate(period.Start)).
+		Delete(&models.DelegationRollup{}).Error; err != nil {
+		return err
+	}
+
+	rollups := rollupRows(aggregation, period, stats)
+	return tx.Table(table).CreateInBatches(&rollups, SnapshotBatchSize).Error
+}
+
+// rebuilds a validator's stats for an aggregated day from that day's hourly stats; days without any are left untouched
+func aggregateValidatorStats(tx *gorm.DB, aggregation DayAggregation) error {
+	watchlist, day := aggregation.Watchlist, aggregation.Day
+
+	var hours []models.HourlyValidatorStats
+	if err := tx.Where("chain_id = ? AND validator_address = ? AND timestamp >= ? AND timestamp < ?",
+		watchlist.ChainID, watchlist.ValidatorAddress, day.Start, day.End).
+		Order("timestamp ASC").
+		Find(&hours).Error; err != nil {
+		return err
+	}
+	if len(hours) == 0 {
+		return nil
+	}
+
+	if err := tx.Where("chain_id = ? AND validator_address = ? AND date = ?",
+		watchlist.ChainID, watchlist.ValidatorAddress, calendarDate(day.Start)).
+		Delete(&models.DailyValidatorStats{}).Error; err != nil {
+		return err
+	}
+
+	daily := dailyValidatorStats(aggregation, hours)
+	return tx.Create(&daily).Error
+}
+
+// an hourly table retention prunes, with the daily table its days are rolled into
+type retentionTable struct {
+	hourly     interface{}
+	daily      interface{}
+	keepLatest bool // keep each delegator's last row before the cutoff so positions can still be carried forward
+}
+
+// Hourly tables retention may prune, by name
+var retentionTables = map[string]retentionTable{
+	"hourly_delegations":     {hourly: &models.HourlyDelegation{}, daily: &models.DailyDelegation{}, keepLatest: true},
+	"hourly_validator_stats": {hourly: &models.HourlyValidatorStats{}, daily: &models.DailyValidatorStats{}},
+	"collection_heartbeats":  {hourly: &models.CollectionHeartbeat{}, daily: &models.DailyDelegation{}},
+}
+
+type gormRetention struct {
+	db *gorm.DB
+}
+
+func (r gormRetention) LatestRolledUpDate(ctx context.Context, table, chainID, validatorAddress string) (time.Time, bool, error) {
+	target, ok := retentionTables[table]
+	if !ok {
+		return time.Time{}, false, errNotRetained(table)
+	}
+	return pluckTime(r.db.WithContext(ctx).Model(target.daily).
+		Where("chain_id = ? AND validator_address = ?", chainID, validatorAddress).
+		Order("date DESC"), "date")
+}
+
+func (r gormRetention) PruneHourly(ctx context.Context, table, chainID, validatorAddress string, cutoff time.Time, batchSize int) (int64, error) {
+	target, ok := retentionTables[table]
+	if !ok {
+		return 0, errNotRetained(table)
+	}
+
+	var deleted int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		// Delete by primary key through a limited subquery so each statement stays short
+		batch := r.db.Model(target.hourly).
+			Select("id").
+			Where("chain_id = ? AND validator_address = ? AND timestamp < ?", chainID, validatorAddress, cutoff).
+			Limit(batchSize)
+		if target.keepLatest {
+			latest := r.db.Model(target.hourly).
+				Select("MAX(id)").
+				Where("chain_id = ? AND validator_address = ? AND timestamp < ?", chainID, validatorAddress, cutoff).
+				Group("delegator_address")
+			batch = batch.Where("id NOT IN (?)", latest)
+		}
+		result := r.db.WithContext(ctx).Where("id IN (?)", batch).Delete(target.hourly)
+		if result.Error != nil {
+			return deleted, result.Error
+		}
+		deleted += result.RowsAffected
+		if result.RowsAffected < int64(batchSize) {
+			return deleted, nil
+		}
+	}
+}
+
+func (r gormRetention) SaveRetentionRun(ctx context.Context, run *models.RetentionRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+func (r gormRetention) RetentionRuns(ctx context.Context, limit, offset int) ([]models.RetentionRun, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.RetentionRun{})
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var runs []models.RetentionRun
+	if err := query.Order("started_at DESC, id DESC").Limit(limit).Offset(offset).Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+func (r gormRetention) HourlyPartitioned() bool {
+	return r.db.Dialector.Name() == db.DriverPostgres
+}
+
+func (r gormRetention) CreateHourlyPartition(ctx context.Context, month time.Time) error {
+	return r.db.WithContext(ctx).
+		Exec("SELECT create_hourly_delegation_partition(?::date)", month.Format("2006-01-02")).Error
+}
+
+func (r gormRetention) HourlyPartitions(ctx context.Context) ([]string, error) {
+	var names []string
+	err := r.db.WithContext(ctx).Raw(`SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'hourly_delegations'::regclass`).Scan(&names).Error
+	return names, err
+}
+
+func (r gormRetention) PartitionRows(ctx context.Context, partition string) ([]PartitionRows, error) {
+	var counts []PartitionRows
+	err := r.db.WithContext(ctx).Raw("SELECT chain_id, validator_address, COUNT(*) AS row_count FROM " +
+		fmt.Sprintf("%q", partition) + " GROUP BY chain_id, validator_address").Scan(&counts).Error
+	return counts, err
+}
+
+func (r gormRetention) PinnedPartitionRows(ctx context.Context, partition string, end, cutoff time.Time) (int64, error) {
+	table := fmt.Sprintf("%q", partition)
+	var pinned int64
+	err := r.db.WithContext(ctx).Raw(`SELECT COUNT(*) FROM `+table+` p
+		WHERE p.id IN (SELECT MAX(id) FROM `+table+` GROUP BY chain_id, validator_address, delegator_address)
+			AND NOT p.exited
+			AND NOT EXISTS (
+				SELECT 1 FROM hourly_delegations h
+				WHERE h.chain_id = p.chain_id AND h.validator_address = p.validator_address
+					AND h.delegator_address = p.delegator_address
+					AND h.timestamp >= ? AND h.timestamp < ?
+			)`, end, cutoff).Scan(&pinned).Error
+	return pinned, err
+}
+
+func (r gormRetention) DropHourlyPartition(ctx context.Context, partition string, runs []models.RetentionRun) error {
+	table := fmt.Sprintf("%q", partition)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range runs {
+			if err := tx.Create(&runs[i]).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("ALTER TABLE hourly_delegations DETACH PARTITION " + table).Error; err != nil {
+			return err
+		}
+		return tx.Exec("DROP TABLE " + table).Error
+	})
+}
+
+type gormMigrations struct {
+	db *gorm.DB
+}
+
+func (r gormMigrations) MigrationStatuses(ctx context.Context) ([]db.MigrationStatus, error) {
+	return db.MigrationStatuses(r.db.WithContext(ctx))
+}
+
+func (r gormMigrations) MigrationHistory(ctx context.Context, limit int) ([]models.MigrationHistory, error) {
+	return db.GetMigrationHistory(r.db.WithContext(ctx), limit)
+}
@@ -0,0 +1,1199 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/pkg/db"
+	"cosmos-tracker/pkg/numeric"
+)
+
+// MemoryStore keeps everything the repositories read and write in process memory. Its repositories
+// share one store, so a snapshot saved through one is visible to the others, as with a database.
+type MemoryStore struct {
+	mu            sync.RWMutex
+	nextID        uint
+	watchlist     []models.Watchlist
+	hourly        []models.HourlyDelegation // in insertion order, so ids ascend
+	daily         []models.DailyDelegation
+	current       map[currentKey]models.CurrentDelegation
+	heartbeats    []models.CollectionHeartbeat
+	stats         []models.HourlyValidatorStats
+	dailyStats    []models.DailyValidatorStats
+	rollups       map[string][]models.DelegationRollup // by period
+	unbondings    []models.UnbondingDelegation
+	redelegations []models.Redelegation
+	cursors       []models.RedelegationScanCursor
+	validators    []models.ValidatorSnapshot
+	retentionRuns []models.RetentionRun
+}
+
+// identifies a delegator's position with a validator
+type currentKey struct {
+	chainID, validatorAddress, delegatorAddress string
+}
+
+// creates an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		current: make(map[currentKey]models.CurrentDelegation),
+		rollups: make(map[string][]models.DelegationRollup),
+	}
+}
+
+// returns repositories backed by the store
+func (s *MemoryStore) Repositories() Repositories {
+	return Repositories{
+		Delegations:   memoryDelegations{s},
+		Watchlist:     memoryWatchlist{s},
+		Snapshots:     memorySnapshots{s},
+		Unbondings:    memoryUnbondings{s},
+		Redelegations: memoryRedelegations{s},
+		Validators:    memoryValidators{s},
+		Health:        memoryHealth{s},
+		Aggregation:   memoryAggregation{s},
+		Retention:     memoryRetention{s},
+		Migrations:    memoryMigrations{},
+	}
+}
+
+// stores daily rows as the aggregator would, assigning ids to rows without one
+func (s *MemoryStore) AddDailyDelegations(rows ...models.DailyDelegation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range rows {
+		if row.ID == 0 {
+			row.ID = s.id()
+		}
+		s.daily = append(s.daily, row)
+	}
+}
+
+// stores rollups of a period as the aggregator would, assigning ids to rows without one
+func (s *MemoryStore) AddDelegationRollups(period string, rows ...models.DelegationRollup) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range rows {
+		if row.ID == 0 {
+			row.ID = s.id()
+		}
+		s.rollups[period] = append(s.rollups[period], row)
+	}
+}
+
+// stores daily validator stats as the aggregator would, assigning ids to rows without one
+func (s *MemoryStore) AddDailyValidatorStats(rows ...models.DailyValidatorStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range rows {
+		if row.ID == 0 {
+			row.ID = s.id()
+		}
+		s.dailyStats = append(s.dailyStats, row)
+	}
+}
+
+// returns the validator stats recorded by every saved snapshot
+func (s *MemoryStore) HourlyValidatorStats() []models.HourlyValidatorStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]models.HourlyValidatorStats(nil), s.stats...)
+}
+
+// returns the next id; ids are unique across tables, which is all callers rely on
+func (s *MemoryStore) id() uint {
+	s.nextID++
+	return s.nextID
+}
+
+// returns one page of a slice along with its full length; a limit below one returns the rest
+func page[T any](rows []T, limit, offset int) ([]T, int64) {
+	total := int64(len(rows))
+	start := offset
+	if start > len(rows) {
+		start = len(rows)
+	}
+	end := len(rows)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+	return rows[start:end], total
+}
+
+// reports whether a row belongs to the validator, delegator and height a query selects
+func (q DelegationQuery) matches(chainID, validatorAddress, delegatorAddress string, height int64) bool {
+	return chainID == q.ChainID && validatorAddress == q.ValidatorAddress &&
+		(q.DelegatorAddress == "" || delegatorAddress == q.DelegatorAddress) &&
+		(q.Height == 0 || height == q.Height)
+}
+
+// reports whether a row recorded at a time and height falls inside the query's range
+func (q DelegationQuery) inRange(at time.Time, height int64) bool {
+	return (q.FromTime.IsZero() || !at.Before(q.FromTime)) &&
+		(q.ToTime.IsZero() || !at.After(q.ToTime)) &&
+		(q.FromHeight == 0 || height >= q.FromHeight) &&
+		(q.ToHeight == 0 || height <= q.ToHeight)
+}
+
+// reports whether a row's amount and change pass the query's amount filters
+func (q DelegationQuery) amountsMatch(amount, change numeric.Int) bool {
+	return (q.MinAmount == nil || amount.Cmp(*q.MinAmount) >= 0) &&
+		(q.MinChange == nil || change.Abs().Cmp(*q.MinChange) >= 0) &&
+		(q.ChangeSign == 0 || change.Sign() == q.ChangeSign)
+}
+
+type memoryDelegations struct {
+	s *MemoryStore
+}
+
+func (r memoryDelegations) HourlyDelegations(_ context.Context, query DelegationQuery) ([]models.HourlyDelegation, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var rows []models.HourlyDelegation
+	for _, h := range r.s.hourly {
+		if query.matches(h.ChainID, h.ValidatorAddress, h.DelegatorAddress, h.BlockHeight) &&
+			query.inRange(h.Timestamp, h.BlockHeight) && query.amountsMatch(h.DelegationAmount, h.ChangeAmount) {
+			rows = append(rows, h)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !rows[i].Timestamp.Equal(rows[j].Timestamp) {
+			return rows[i].Timestamp.After(rows[j].Timestamp) != query.Ascending
+		}
+		return rows[i].ID > rows[j].ID != query.Ascending
+	})
+
+	rows, total := page(rows, query.Limit, query.Offset)
+	return rows, total, nil
+}
+
+func (r memoryDelegations) DenseDelegations(_ context.Context, query DelegationQuery) ([]DenseDelegation, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	// Each delegator's rows in id order; a row spans until the delegator's next one.
+	// The height selects runs, not the rows carried into them.
+	spans := query
+	spans.Height = 0
+	byDelegator := make(map[string][]models.HourlyDelegation)
+	for _, h := range r.s.hourly {
+		if spans.matches(h.ChainID, h.ValidatorAddress, h.DelegatorAddress, 0) {
+			byDelegator[h.DelegatorAddress] = append(byDelegator[h.DelegatorAddress], h)
+		}
+	}
+
+	var rows []DenseDelegation
+	for _, run := range r.s.heartbeats {
+		if !query.matches(run.ChainID, run.ValidatorAddress, "", run.BlockHeight) || !query.inRange(run.Timestamp, run.BlockHeight) {
+			continue
+		}
+		for _, history := range byDelegator {
+			for i, h := range history {
+				if h.Timestamp.After(run.Timestamp) {
+					continue
+				}
+				if i+1 < len(history) && !run.Timestamp.Before(history[i+1].Timestamp) {
+					continue
+				}
+				// Exit rows only appear at their own run, and carried rows have not changed
+				change := h.ChangeAmount
+				if !h.Timestamp.Equal(run.Timestamp) {
+					if h.Exited {
+						continue
+					}
+					change = numeric.NewInt(0)
+				}
+				if !query.amountsMatch(h.DelegationAmount, change) {
+					continue
+				}
+				rows = append(rows, DenseDelegation{
+					HourlyDelegation: h,
+					RunTimestamp:     run.Timestamp,
+					RunHeight:        run.BlockHeight,
+					RunBlockTime:     run.BlockTime,
+				})
+			}
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].RunTimestamp.Equal(rows[j].RunTimestamp) {
+			return rows[i].RunTimestamp.After(rows[j].RunTimestamp) != query.Ascending
+		}
+		return rows[i].DelegatorAddress < rows[j].DelegatorAddress
+	})
+
+	rows, total := page(rows, query.Limit, query.Offset)
+	return rows, total, nil
+}
+
+func (r memoryDelegations) DailyDelegations(_ context.Context, query DelegationQuery) ([]models.DailyDelegation, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	// Daily rows have no change to filter on
+	amounts := query
+	amounts.MinChange, amounts.ChangeSign = nil, 0
+
+	var rows []models.DailyDelegation
+	for _, d := range r.s.daily {
+		if query.matches(d.ChainID, d.ValidatorAddress, d.DelegatorAddress, d.BlockHeight) &&
+			query.inRange(d.Date, d.BlockHeight) && amounts.amountsMatch(d.TotalDelegation, numeric.NewInt(0)) {
+			rows = append(rows, d)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !rows[i].Date.Equal(rows[j].Date) {
+			return rows[i].Date.After(rows[j].Date) != query.Ascending
+		}
+		return rows[i].DelegatorAddress < rows[j].DelegatorAddress
+	})
+
+	rows, total := page(rows, query.Limit, query.Offset)
+	return rows, total, nil
+}
+
+func (r memoryDelegations) CurrentDelegators(_ context.Context, query DelegationQuery) ([]models.CurrentDelegation, CurrentTotals, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	totals := CurrentTotals{TotalStake: numeric.NewInt(0)}
+	var rows []models.CurrentDelegation
+	for key, position := range r.s.current {
+		if key.chainID != query.ChainID || key.validatorAddress != query.ValidatorAddress || position.Exited {
+			continue
+		}
+		rows = append(rows, position)
+		totals.TotalDelegators++
+		totals.TotalStake = totals.TotalStake.Add(position.DelegationAmount)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if c := rows[i].DelegationAmount.Cmp(rows[j].DelegationAmount); c != 0 {
+			return c > 0
+		}
+		return rows[i].DelegatorAddress < rows[j].DelegatorAddress
+	})
+
+	rows, _ = page(rows, query.Limit, query.Offset)
+	return rows, totals, nil
+}
+
+func (r memoryDelegations) CurrentDelegation(_ context.Context, chainID, validatorAddress, delegatorAddress string) (models.CurrentDelegation, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	position, ok := r.s.current[currentKey{chainID, validatorAddress, delegatorAddress}]
+	if !ok || position.Exited {
+		return models.CurrentDelegation{}, ErrNotFound
+	}
+	return position, nil
+}
+
+func (r memoryDelegations) Rollups(_ context.Context, period string, query DelegationQuery) ([]models.DelegationRollup, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var rows []models.DelegationRollup
+	for _, rollup := range r.s.rollups[period] {
+		if rollup.ChainID == query.ChainID && rollup.ValidatorAddress == query.ValidatorAddress {
+			rows = append(rows, rollup)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !rows[i].PeriodStart.Equal(rows[j].PeriodStart) {
+			return rows[i].PeriodStart.After(rows[j].PeriodStart)
+		}
+		return rows[i].DelegatorAddress < rows[j].DelegatorAddress
+	})
+
+	rows, total := page(rows, query.Limit, query.Offset)
+	return rows, total, nil
+}
+
+type memoryWatchlist struct {
+	s *MemoryStore
+}
+
+func (r memoryWatchlist) CreateWatchlist(_ context.Context, entry *models.Watchlist) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	entry.ID = r.s.id()
+	r.s.watchlist = append(r.s.watchlist, *entry)
+	return nil
+}
+
+func (r memoryWatchlist) ListWatchlist(_ context.Context, chainID string) ([]models.Watchlist, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	entries := []models.Watchlist{}
+	for _, entry := range r.s.watchlist {
+		if chainID == "" || entry.ChainID == chainID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (r memoryWatchlist) FindWatchlist(_ context.Context, chainID, validatorAddress string) (models.Watchlist, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, entry := range r.s.watchlist {
+		if entry.ChainID == chainID && entry.ValidatorAddress == validatorAddress {
+			return entry, nil
+		}
+	}
+	return models.Watchlist{}, ErrNotFound
+}
+
+func (r memoryWatchlist) DeleteWatchlist(_ context.Context, id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i, entry := range r.s.watchlist {
+		if entry.ID == id {
+			r.s.watchlist = append(r.s.watchlist[:i], r.s.watchlist[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+type memorySnapshots struct {
+	s *MemoryStore
+}
+
+// seeds from the hourly history the first time a validator is seen without current positions
+func (r memorySnapshots) CurrentPositions(_ context.Context, chainID, validatorAddress string) (map[string]models.CurrentDelegation, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	positions := make(map[string]models.CurrentDelegation)
+	for key, position := range r.s.current {
+		if key.chainID == chainID && key.validatorAddress == validatorAddress {
+			positions[key.delegatorAddress] = position
+		}
+	}
+	if len(positions) > 0 {
+		return positions, nil
+	}
+
+	// Later rows overwrite earlier ones, leaving each delegator's latest
+	for _, h := range r.s.hourly {
+		if h.ChainID == chainID && h.ValidatorAddress == validatorAddress {
+			positions[h.DelegatorAddress] = models.CurrentDelegation{
+				DelegationAmount: h.DelegationAmount,
+				Shares:           h.Shares,
+				Exited:           h.Exited,
+			}
+		}
+	}
+	return positions, nil
+}
+
+func (r memorySnapshots) SaveSnapshot(_ context.Context, snapshot Snapshot) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, run := range r.s.heartbeats {
+		if run.ChainID == snapshot.Heartbeat.ChainID && run.ValidatorAddress == snapshot.Heartbeat.ValidatorAddress &&
+			run.RunID == snapshot.Heartbeat.RunID {
+			return ErrRunExists
+		}
+	}
+
+	now := time.Now()
+	for i := range snapshot.Delegations {
+		h := &snapshot.Delegations[i]
+		h.ID = r.s.id()
+		if h.Timestamp.IsZero() {
+			h.Timestamp = now
+		}
+		r.s.hourly = append(r.s.hourly, *h)
+
+		key := currentKey{h.ChainID, h.ValidatorAddress, h.DelegatorAddress}
+		position := r.s.current[key]
+		if position.ID == 0 {
+			position.ID = r.s.id()
+		}
+		position.WatchlistID = h.WatchlistID
+		position.ChainID = h.ChainID
+		position.ValidatorAddress = h.ValidatorAddress
+		position.DelegatorAddress = h.DelegatorAddress
+		position.DelegationAmount = h.DelegationAmount
+		position.Shares = h.Shares
+		position.Exited = h.Exited
+		position.SnapshotID = h.ID
+		position.BlockHeight = h.BlockHeight
+		position.BlockTime = h.BlockTime
+		position.UpdatedAt = now
+		r.s.current[key] = position
+	}
+
+	stats := snapshot.Stats
+	stats.ID = r.s.id()
+	r.s.stats = append(r.s.stats, stats)
+
+	heartbeat := snapshot.Heartbeat
+	heartbeat.ID = r.s.id()
+	r.s.heartbeats = append(r.s.heartbeats, heartbeat)
+	return nil
+}
+
+type memoryUnbondings struct {
+	s *MemoryStore
+}
+
+func (r memoryUnbondings) SaveUnbondings(_ context.Context, chainID, validatorAddress string, unbondings []models.UnbondingDelegation, height int64, blockTime time.Time) (UnbondingSettlement, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for _, u := range unbondings {
+		found := false
+		for i := range r.s.unbondings {
+			stored := &r.s.unbondings[i]
+			if stored.ChainID == u.ChainID && stored.ValidatorAddress == u.ValidatorAddress &&
+				stored.DelegatorAddress == u.DelegatorAddress && stored.CreationHeight == u.CreationHeight {
+				stored.CompletionTime = u.CompletionTime
+				stored.InitialBalance = u.InitialBalance
+				stored.Balance = u.Balance
+				stored.Status = u.Status
+				stored.LastSeenHeight = u.LastSeenHeight
+				stored.UpdatedAt = now
+				found = true
+				break
+			}
+		}
+		if !found {
+			u.ID = r.s.id()
+			u.FirstSeenAt, u.UpdatedAt = now, now
+			r.s.unbondings = append(r.s.unbondings, u)
+		}
+	}
+
+	var settlement UnbondingSettlement
+	for i := range r.s.unbondings {
+		stored := &r.s.unbondings[i]
+		if stored.ChainID != chainID || stored.ValidatorAddress != validatorAddress ||
+			stored.Status != models.UnbondingPending || stored.LastSeenHeight >= height {
+			continue
+		}
+		if stored.CompletionTime.After(blockTime) {
+			stored.Status = models.UnbondingCancelled
+			settlement.Cancelled++
+		} else {
+			stored.Status = models.UnbondingCompleted
+			settlement.Completed++
+		}
+	}
+	return settlement, nil
+}
+
+func (r memoryUnbondings) PendingUnbondings(_ context.Context, query UnbondingQuery) ([]models.UnbondingDelegation, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var rows []models.UnbondingDelegation
+	for _, u := range r.s.unbondings {
+		if u.ChainID == query.ChainID && u.ValidatorAddress == query.ValidatorAddress &&
+			u.Status == models.UnbondingPending && u.CompletionTime.After(query.After) &&
+			(query.Until.IsZero() || !u.CompletionTime.After(query.Until)) {
+			rows = append(rows, u)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !rows[i].CompletionTime.Equal(rows[j].CompletionTime) {
+			return rows[i].CompletionTime.Before(rows[j].CompletionTime)
+		}
+		return rows[i].ID < rows[j].ID
+	})
+
+	rows, total := page(rows, query.Limit, query.Offset)
+	return rows, total, nil
+}
+
+type memoryRedelegations struct {
+	s *MemoryStore
+}
+
+// reports whether a redelegation moves stake in the given direction of a validator, or either when empty
+func redelegationMatches(r models.Redelegation, chainID, validatorAddress, direction string) bool {
+	if r.ChainID != chainID {
+		return false
+	}
+	switch direction {
+	case models.RedelegationIn:
+		return r.DstValidatorAddress == validatorAddress
+	case models.RedelegationOut:
+		return r.SrcValidatorAddress == validatorAddress
+	default:
+		return r.SrcValidatorAddress == validatorAddress || r.DstValidatorAddress == validatorAddress
+	}
+}
+
+func (r memoryRedelegations) RedelegationCursor(_ context.Context, chainID, validatorAddress, direction string) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, cursor := range r.s.cursors {
+		if cursor.ChainID == chainID && cursor.ValidatorAddress == validatorAddress && cursor.Direction == direction {
+			return cursor.ScannedHeight, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r memoryRedelegations) SaveRedelegations(_ context.Context, redelegations []models.Redelegation, cursors []models.RedelegationScanCursor) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var saved int64
+	for _, redelegation := range redelegations {
+		duplicate := false
+		for _, stored := range r.s.redelegations {
+			if stored.ChainID == redelegation.ChainID && stored.TxHash == redelegation.TxHash &&
+				stored.EventIndex == redelegation.EventIndex {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		redelegation.ID = r.s.id()
+		redelegation.CreatedAt = time.Now()
+		r.s.redelegations = append(r.s.redelegations, redelegation)
+		saved++
+	}
+
+	for _, cursor := range cursors {
+		cursor.UpdatedAt = time.Now()
+		stored := false
+		for i, existing := range r.s.cursors {
+			if existing.ChainID == cursor.ChainID && existing.ValidatorAddress == cursor.ValidatorAddress &&
+				existing.Direction == cursor.Direction {
+				cursor.ID = existing.ID
+				r.s.cursors[i] = cursor
+				stored = true
+				break
+			}
+		}
+		if !stored {
+			cursor.ID = r.s.id()
+			r.s.cursors = append(r.s.cursors, cursor)
+		}
+	}
+	return saved, nil
+}
+
+func (r memoryRedelegations) Redelegations(_ context.Context, query RedelegationQuery) ([]models.Redelegation, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var rows []models.Redelegation
+	for _, redelegation := range r.s.redelegations {
+		if redelegationMatches(redelegation, query.ChainID, query.ValidatorAddress, query.Direction) {
+			rows = append(rows, redelegation)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Height != rows[j].Height {
+			return rows[i].Height > rows[j].Height
+		}
+		return rows[i].ID > rows[j].ID
+	})
+
+	rows, total := page(rows, query.Limit, query.Offset)
+	return rows, total, nil
+}
+
+func (r memoryRedelegations) RedelegationSummary(_ context.Context, chainID, validatorAddress, direction string) ([]RedelegationCounterpart, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var matching []models.Redelegation
+	for _, redelegation := range r.s.redelegations {
+		if redelegationMatches(redelegation, chainID, validatorAddress, direction) {
+			matching = append(matching, redelegation)
+		}
+	}
+	return summarizeRedelegations(matching, direction), nil
+}
+
+// totals redelegations by the validator on their other side, largest total first
+func summarizeRedelegations(redelegations []models.Redelegation, direction string) []RedelegationCounterpart {
+	byCounterpart := make(map[string]*RedelegationCounterpart)
+	summary := []RedelegationCounterpart{}
+	for _, redelegation := range redelegations {
+		counterpart := redelegation.DstValidatorAddress
+		if direction == models.RedelegationIn {
+			counterpart = redelegation.SrcValidatorAddress
+		}
+		entry, ok := byCounterpart[counterpart]
+		if !ok {
+			entry = &RedelegationCounterpart{ValidatorAddress: counterpart, Amount: numeric.NewInt(0)}
+			byCounterpart[counterpart] = entry
+		}
+		entry.Amount = entry.Amount.Add(redelegation.Amount)
+		entry.Count++
+	}
+	for _, entry := range byCounterpart {
+		summary = append(summary, *entry)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if c := summary[i].Amount.Cmp(summary[j].Amount); c != 0 {
+			return c > 0
+		}
+		return summary[i].ValidatorAddress < summary[j].ValidatorAddress
+	})
+	return summary
+}
+
+type memoryValidators struct {
+	s *MemoryStore
+}
+
+func (r memoryValidators) SaveValidatorSnapshot(_ context.Context, snapshot models.ValidatorSnapshot, rename bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, stored := range r.s.validators {
+		if stored.ChainID == snapshot.ChainID &&
+			stored.ValidatorAddress == snapshot.ValidatorAddress && stored.RunID == snapshot.RunID {
+			return nil
+		}
+	}
+
+	snapshot.ID = r.s.id()
+	if snapshot.Timestamp.IsZero() {
+		snapshot.Timestamp = time.Now()
+	}
+	r.s.validators = append(r.s.validators, snapshot)
+	if !rename {
+		return nil
+	}
+	for i := range r.s.watchlist {
+		if r.s.watchlist[i].ID == snapshot.WatchlistID {
+			r.s.watchlist[i].ValidatorName = snapshot.Moniker
+		}
+	}
+	return nil
+}
+
+// returns the rows of one validator, newest first by the given time
+func newestFirst[T any](rows []T, query PageQuery, key func(T) (chainID, validatorAddress string, at time.Time, id uint)) ([]T, int64) {
+	var matched []T
+	for _, row := range rows {
+		if chainID, validatorAddress, _, _ := key(row); chainID == query.ChainID && validatorAddress == query.ValidatorAddress {
+			matched = append(matched, row)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		_, _, ai, ii := key(matched[i])
+		_, _, aj, ij := key(matched[j])
+		if !ai.Equal(aj) {
+			return ai.After(aj)
+		}
+		return ii > ij
+	})
+	return page(matched, query.Limit, query.Offset)
+}
+
+func validatorSnapshotKey(v models.ValidatorSnapshot) (string, string, time.Time, uint) {
+	return v.ChainID, v.ValidatorAddress, v.Timestamp, v.ID
+}
+
+func (r memoryValidators) ValidatorSnapshots(_ context.Context, query PageQuery) ([]models.ValidatorSnapshot, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	rows, total := newestFirst(r.s.validators, query, validatorSnapshotKey)
+	return rows, total, nil
+}
+
+func (r memoryValidators) LatestValidatorSnapshot(_ context.Context, chainID, validatorAddress string) (models.ValidatorSnapshot, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	rows, _ := newestFirst(r.s.validators, PageQuery{ChainID: chainID, ValidatorAddress: validatorAddress, Limit: 1}, validatorSnapshotKey)
+	if len(rows) == 0 {
+		return models.ValidatorSnapshot{}, ErrNotFound
+	}
+	return rows[0], nil
+}
+
+func (r memoryValidators) HourlyValidatorStats(_ context.Context, query PageQuery) ([]models.HourlyValidatorStats, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	rows, total := newestFirst(r.s.stats, query, func(s models.HourlyValidatorStats) (string, string, time.Time, uint) {
+		return s.ChainID, s.ValidatorAddress, s.Timestamp, s.ID
+	})
+	return rows, total, nil
+}
+
+func (r memoryValidators) DailyValidatorStats(_ context.Context, query PageQuery) ([]models.DailyValidatorStats, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	rows, total := newestFirst(r.s.dailyStats, query, func(s models.DailyValidatorStats) (string, string, time.Time, uint) {
+		return s.ChainID, s.ValidatorAddress, s.Date, s.ID
+	})
+	return rows, total, nil
+}
+
+type memoryHealth struct {
+	s *MemoryStore
+}
+
+func (r memoryHealth) Ping(context.Context) error {
+	return nil
+}
+
+func (r memoryHealth) RecordCounts(context.Context) (RecordCounts, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return RecordCounts{
+		WatchlistEntries:  int64(len(r.s.watchlist)),
+		HourlyDelegations: int64(len(r.s.hourly)),
+		DailyDelegations:  int64(len(r.s.daily)),
+	}, nil
+}
+
+func (r memoryHealth) LatestHeartbeat(context.Context) (models.CollectionHeartbeat, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	if len(r.s.heartbeats) == 0 {
+		return models.CollectionHeartbeat{}, ErrNotFound
+	}
+	latest := r.s.heartbeats[0]
+	for _, heartbeat := range r.s.heartbeats[1:] {
+		if !heartbeat.Timestamp.Before(latest.Timestamp) {
+			latest = heartbeat
+		}
+	}
+	return latest, nil
+}
+
+// returns the earliest time pick selects from rows, or the latest when latest is set; false when it selects none
+func pickTime[T any](rows []T, latest bool, pick func(T) (time.Time, bool)) (time.Time, bool) {
+	var picked time.Time
+	found := false
+	for _, row := range rows {
+		at, ok := pick(row)
+		if ok && (!found || (latest && at.After(picked)) || (!latest && at.Before(picked))) {
+			picked, found = at, true
+		}
+	}
+	return picked, found
+}
+
+// returns the rows drop doesn't match, in a new slice, and how many it matched
+func removeRows[T any](rows []T, drop func(T) bool) ([]T, int64) {
+	kept := make([]T, 0, len(rows))
+	for _, row := range rows {
+		if !drop(row) {
+			kept = append(kept, row)
+		}
+	}
+	return kept, int64(len(rows) - len(kept))
+}
+
+// returns every delegator's latest snapshot in [since, before), keyed by address; a zero bound is
+// open. Callers hold the lock.
+func (s *MemoryStore) latestSnapshots(chainID, validatorAddress string, since, before time.Time) map[string]models.HourlyDelegation {
+	latest := make(map[string]models.HourlyDelegation)
+	for _, h := range s.hourly {
+		if h.ChainID == chainID && h.ValidatorAddress == validatorAddress &&
+			(since.IsZero() || !h.Timestamp.Before(since)) && (before.IsZero() || h.Timestamp.Before(before)) {
+			latest[h.DelegatorAddress] = h
+		}
+	}
+	return latest
+}
+
+// returns the time of the latest full run before a time, or zero when none did. Callers hold the lock.
+func (s *MemoryStore) lastFullRunBefore(chainID, validatorAddress string, before time.Time) time.Time {
+	last, _ := pickTime(s.heartbeats, true, func(run models.CollectionHeartbeat) (time.Time, bool) {
+		return run.Timestamp, run.ChainID == chainID && run.ValidatorAddress == validatorAddress &&
+			run.StorageMode == config.StorageModeFull && run.Timestamp.Before(before)
+	})
+	return last
+}
+
+type memoryAggregation struct {
+	s *MemoryStore
+}
+
+func (r memoryAggregation) LatestDailyDate(_ context.Context, chainID, validatorAddress string) (time.Time, bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	date, ok := pickTime(r.s.daily, true, func(d models.DailyDelegation) (time.Time, bool) {
+		return d.Date, d.ChainID == chainID && d.ValidatorAddress == validatorAddress
+	})
+	return date, ok, nil
+}
+
+func (r memoryAggregation) FirstSnapshotTime(_ context.Context, chainID, validatorAddress string) (time.Time, bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	first, ok := pickTime(r.s.hourly, false, func(h models.HourlyDelegation) (time.Time, bool) {
+		return h.Timestamp, h.ChainID == chainID && h.ValidatorAddress == validatorAddress
+	})
+	return first, ok, nil
+}
+
+func (r memoryAggregation) AggregateDay(_ context.Context, aggregation DayAggregation) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	chainID, validatorAddress := aggregation.Watchlist.ChainID, aggregation.Watchlist.ValidatorAddress
+	day := aggregation.Day
+	entryAt := func(entryChainID, entryValidatorAddress string, at time.Time) (time.Time, bool) {
+		return at, entryChainID == chainID && entryValidatorAddress == validatorAddress && !at.Before(day.Start) && at.Before(day.End)
+	}
+
+	pruned, _ := pickTime(r.s.retentionRuns, true, func(run models.RetentionRun) (time.Time, bool) {
+		return run.Cutoff, run.ChainID == chainID && run.ValidatorAddress == validatorAddress && run.Table == "hourly_delegations"
+	})
+	if day.Start.Before(pruned) {
+		return 0, nil
+	}
+
+	// The day's first run, from its heartbeats or, for data collected before they existed, its rows
+	firstRun, covered := pickTime(r.s.heartbeats, false, func(run models.CollectionHeartbeat) (time.Time, bool) {
+		return entryAt(run.ChainID, run.ValidatorAddress, run.Timestamp)
+	})
+	firstRow, found := pickTime(r.s.hourly, false, func(h models.HourlyDelegation) (time.Time, bool) {
+		return entryAt(h.ChainID, h.ValidatorAddress, h.Timestamp)
+	})
+	if found && (!covered || firstRow.Before(firstRun)) {
+		firstRun, covered = firstRow, true
+	}
+	if !covered {
+		return 0, nil
+	}
+
+	since := r.s.lastFullRunBefore(chainID, validatorAddress, day.End)
+	if since.After(day.Start) {
+		since = day.Start
+	}
+	snapshots := dailySnapshots(r.s.latestSnapshots(chainID, validatorAddress, since, day.End), day.Start)
+	if len(snapshots) == 0 {
+		return 0, nil
+	}
+
+	date := calendarDate(day.Start)
+	r.s.daily, _ = removeRows(r.s.daily, func(d models.DailyDelegation) bool {
+		return d.ChainID == chainID && d.ValidatorAddress == validatorAddress && d.Date.Equal(date)
+	})
+	for _, d := range dailyRows(aggregation, snapshots) {
+		d.ID = r.s.id()
+		r.s.daily = append(r.s.daily, d)
+	}
+
+	r.s.rollupDay(aggregation, firstRun)
+	r.s.aggregateValidatorStats(aggregation)
+	return len(snapshots), nil
+}
+
+// rebuilds the rollups of an aggregated day, whose first run was at firstRun, and of the periods
+// containing it. Callers hold the lock.
+func (s *MemoryStore) rollupDay(aggregation DayAggregation, firstRun time.Time) {
+	chainID, validatorAddress := aggregation.Watchlist.ChainID, aggregation.Watchlist.ValidatorAddress
+	day := aggregation.Day
+
+	// Open and close come from each delegator's first and last row of the day
+	var stats []rollupStats
+	index := make(map[string]int)
+	for _, h := range s.hourly {
+		if h.ChainID != chainID || h.ValidatorAddress != validatorAddress || h.Timestamp.Before(day.Start) || !h.Timestamp.Before(day.End) {
+			continue
+		}
+		i, ok := index[h.DelegatorAddress]
+		if !ok {
+			i = len(stats)
+			index[h.DelegatorAddress] = i
+			stats = append(stats, rollupStats{
+				DelegatorAddress: h.DelegatorAddress,
+				OpenAmount:       h.DelegationAmount,
+				MinAmount:        h.DelegationAmount,
+				MaxAmount:        h.DelegationAmount,
+				FirstAmount:      h.DelegationAmount,
+				FirstChange:      h.ChangeAmount,
+				OpenHeight:       h.BlockHeight,
+				OpenTime:         h.Timestamp,
+			})
+		}
+		stat := &stats[i]
+		stat.CloseAmount, stat.CloseHeight = h.DelegationAmount, h.BlockHeight
+		if h.DelegationAmount.Cmp(stat.MinAmount) < 0 {
+			stat.MinAmount = h.DelegationAmount
+		}
+		if h.DelegationAmount.Cmp(stat.MaxAmount) > 0 {
+			stat.MaxAmount = h.DelegationAmount
+		}
+		stat.Snapshots++
+	}
+	netChanges(stats)
+
+	since := s.lastFullRunBefore(chainID, validatorAddress, day.Start)
+	stats = carryForwardRollups(stats, s.latestSnapshots(chainID, validatorAddress, since, day.Start), firstRun)
+	countRuns(stats, s.dailyRuns(chainID, validatorAddress, day, since))
+	s.replaceRollups(aggregation, day, stats)
+
+	for _, period := range aggregation.Periods {
+		s.replaceRollups(aggregation, period, s.periodStats(chainID, validatorAddress, period))
+	}
+}
+
+// counts the runs of a day that observed each delegator, reading rows from since: a row covers the
+// runs from its own until the delegator's next row, and an exit row only its own run. Callers hold the lock.
+func (s *MemoryStore) dailyRuns(chainID, validatorAddress string, day RollupPeriod, since time.Time) map[string]int64 {
+	spans := make(map[string][]models.HourlyDelegation)
+	for _, h := range s.hourly {
+		if h.ChainID == chainID && h.ValidatorAddress == validatorAddress && !h.Timestamp.Before(since) && h.Timestamp.Before(day.End) {
+			spans[h.DelegatorAddress] = append(spans[h.DelegatorAddress], h)
+		}
+	}
+
+	runs := make(map[string]int64)
+	for _, run := range s.heartbeats {
+		if run.ChainID != chainID || run.ValidatorAddress != validatorAddress || run.Timestamp.Before(day.Start) || !run.Timestamp.Before(day.End) {
+			continue
+		}
+		for delegatorAddress, rows := range spans {
+			for i, h := range rows {
+				covers := !h.Timestamp.After(run.Timestamp) && (i+1 == len(rows) || run.Timestamp.Before(rows[i+1].Timestamp))
+				if covers && (!h.Exited || h.Timestamp.Equal(run.Timestamp)) {
+					runs[delegatorAddress]++
+				}
+			}
+		}
+	}
+	return runs
+}
+
+// returns a week or month's statistics from the day rollups it contains. Callers hold the lock.
+func (s *MemoryStore) periodStats(chainID, validatorAddress string, period RollupPeriod) []rollupStats {
+	start, end := calendarDate(period.Start), calendarDate(period.End)
+	var days []models.DelegationRollup
+	for _, d := range s.rollups[models.PeriodDay] {
+		if d.ChainID == chainID && d.ValidatorAddress == validatorAddress && !d.PeriodStart.Before(start) && d.PeriodStart.Before(end) {
+			days = append(days, d)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].PeriodStart.Before(days[j].PeriodStart) })
+
+	var stats []rollupStats
+	index := make(map[string]int)
+	for _, d := range days {
+		i, ok := index[d.DelegatorAddress]
+		if !ok {
+			i = len(stats)
+			index[d.DelegatorAddress] = i
+			stats = append(stats, rollupStats{
+				DelegatorAddress: d.DelegatorAddress,
+				OpenAmount:       d.OpenAmount,
+				MinAmount:        d.MinAmount,
+				MaxAmount:        d.MaxAmount,
+				FirstAmount:      d.CloseAmount,
+				FirstChange:      d.NetChange,
+				OpenHeight:       d.OpenHeight,
+			})
+		}
+		stat := &stats[i]
+		stat.CloseAmount, stat.CloseHeight = d.CloseAmount, d.CloseHeight
+		if d.MinAmount.Cmp(stat.MinAmount) < 0 {
+			stat.MinAmount = d.MinAmount
+		}
+		if d.MaxAmount.Cmp(stat.MaxAmount) > 0 {
+			stat.MaxAmount = d.MaxAmount
+		}
+		stat.Snapshots += d.Snapshots
+	}
+	netChanges(stats)
+	return stats
+}
+
+// replaces the rollups of one period; periods without data are left untouched. Callers hold the lock.
+func (s *MemoryStore) replaceRollups(aggregation DayAggregation, period RollupPeriod, stats []rollupStats) {
+	if len(stats) == 0 {
+		return
+	}
+
+	start := calendarDate(period.Start)
+	rows, _ := removeRows(s.rollups[period.Period], func(r models.DelegationRollup) bool {
+		return r.ChainID == aggregation.Watchlist.ChainID && r.ValidatorAddress == aggregation.Watchlist.ValidatorAddress && r.PeriodStart.Equal(start)
+	})
+	for _, rollup := range rollupRows(aggregation, period, stats) {
+		rollup.ID = s.id()
+		rows = append(rows, rollup)
+	}
+	s.rollups[period.Period] = rows
+}
+
+// rebuilds a validator's stats for an aggregated day; days without hourly stats are left untouched.
+// Callers hold the lock.
+func (s *MemoryStore) aggregateValidatorStats(aggregation DayAggregation) {
+	chainID, validatorAddress := aggregation.Watchlist.ChainID, aggregation.Watchlist.ValidatorAddress
+	day := aggregation.Day
+
+	var hours []models.HourlyValidatorStats
+	for _, h := range s.stats {
+		if h.ChainID == chainID && h.ValidatorAddress == validatorAddress && !h.Timestamp.Before(day.Start) && h.Timestamp.Before(day.End) {
+			hours = append(hours, h)
+		}
+	}
+	if len(hours) == 0 {
+		return
+	}
+	sort.SliceStable(hours, func(i, j int) bool { return hours[i].Timestamp.Before(hours[j].Timestamp) })
+
+	date := calendarDate(day.Start)
+	s.dailyStats, _ = removeRows(s.dailyStats, func(d models.DailyValidatorStats) bool {
+		return d.ChainID == chainID && d.ValidatorAddress == validatorAddress && d.Date.Equal(date)
+	})
+	daily := dailyValidatorStats(aggregation, hours)
+	daily.ID = s.id()
+	s.dailyStats = append(s.dailyStats, daily)
+}
+
+type memoryRetention struct {
+	s *MemoryStore
+}
+
+func (r memoryRetention) LatestRolledUpDate(_ context.Context, table, chainID, validatorAddress string) (time.Time, bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var dates []time.Time
+	switch table {
+	case "hourly_delegations", "collection_heartbeats":
+		for _, d := range r.s.daily {
+			if d.ChainID == chainID && d.ValidatorAddress == validatorAddress {
+				dates = append(dates, d.Date)
+			}
+		}
+	case "hourly_validator_stats":
+		for _, d := range r.s.dailyStats {
+			if d.ChainID == chainID && d.ValidatorAddress == validatorAddress {
+				dates = append(dates, d.Date)
+			}
+		}
+	default:
+		return time.Time{}, false, errNotRetained(table)
+	}
+
+	latest, ok := pickTime(dates, true, func(date time.Time) (time.Time, bool) { return date, true })
+	return latest, ok, nil
+}
+
+// deletes everything in one pass, as nothing is gained by batching in memory
+func (r memoryRetention) PruneHourly(_ context.Context, table, chainID, validatorAddress string, cutoff time.Time, _ int) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	expired := func(rowChainID, rowValidatorAddress string, at time.Time) bool {
+		return rowChainID == chainID && rowValidatorAddress == validatorAddress && at.Before(cutoff)
+	}
+
+	var deleted int64
+	switch table {
+	case "hourly_delegations":
+		// Each delegator's last row before the cutoff stays, so positions can still be carried forward
+		latest := make(map[string]uint)
+		for _, h := range r.s.hourly {
+			if expired(h.ChainID, h.ValidatorAddress, h.Timestamp) {
+				latest[h.DelegatorAddress] = h.ID
+			}
+		}
+		r.s.hourly, deleted = removeRows(r.s.hourly, func(h models.HourlyDelegation) bool {
+			return expired(h.ChainID, h.ValidatorAddress, h.Timestamp) && latest[h.DelegatorAddress] != h.ID
+		})
+	case "hourly_validator_stats":
+		r.s.stats, deleted = removeRows(r.s.stats, func(h models.HourlyValidatorStats) bool {
+			return expired(h.ChainID, h.ValidatorAddress, h.Timestamp)
+		})
+	case "collection_heartbeats":
+		r.s.heartbeats, deleted = removeRows(r.s.heartbeats, func(h models.CollectionHeartbeat) bool {
+			return expired(h.ChainID, h.ValidatorAddress, h.Timestamp)
+		})
+	default:
+		return 0, errNotRetained(table)
+	}
+	return deleted, nil
+}
+
+func (r memoryRetention) SaveRetentionRun(_ context.Context, run *models.RetentionRun) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	run.ID = r.s.id()
+	r.s.retentionRuns = append(r.s.retentionRuns, *run)
+	return nil
+}
+
+func (r memoryRetention) RetentionRuns(_ context.Context, limit, offset int) ([]models.RetentionRun, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	runs := append([]models.RetentionRun(nil), r.s.retentionRuns...)
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].StartedAt.Equal(runs[j].StartedAt) {
+			return runs[i].StartedAt.After(runs[j].StartedAt)
+		}
+		return runs[i].ID > runs[j].ID
+	})
+
+	runs, total := page(runs, limit, offset)
+	return runs, total, nil
+}
+
+// memory storage is never partitioned, so the aggregator never reaches the partition methods below
+func (r memoryRetention) HourlyPartitioned() bool {
+	return false
+}
+
+func (r memoryRetention) CreateHourlyPartition(context.Context, time.Time) error {
+	return nil
+}
+
+func (r memoryRetention) HourlyPartitions(context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (r memoryRetention) PartitionRows(context.Context, string) ([]PartitionRows, error) {
+	return nil, nil
+}
+
+func (r memoryRetention) PinnedPartitionRows(context.Context, string, time.Time, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r memoryRetention) DropHourlyPartition(_ context.Context, partition string, _ []models.RetentionRun) error {
+	return fmt.Errorf("memory storage has no partition %s", partition)
+}
+
+// memory storage has no schema, so it has no migrations to report
+type memoryMigrations struct{}
+
+func (r memoryMigrations) MigrationStatuses(context.Context) ([]db.MigrationStatus, error) {
+	return []db.MigrationStatus{}, nil
+}
+
+func (r memoryMigrations) MigrationHistory(context.Context, int) ([]models.MigrationHistory, error) {
+	return []models.MigrationHistory{}, nil
+}
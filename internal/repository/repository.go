@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/pkg/db"
+	"cosmos-tracker/pkg/numeric"
+)
+
+// returned when a single record is looked up and does not exist
+var ErrNotFound = errors.New("record not found")
+
+// returned by SaveSnapshot when the validator's snapshot for the run is already stored
+var ErrRunExists = errors.New("collection run already stored")
+
+// DelegationQuery selects a page of one validator's delegation data
+type DelegationQuery struct {
+	ChainID          string
+	ValidatorAddress string
+	DelegatorAddress string // empty for every delegator
+	Height           int64  // block height to match, zero for every height
+
+	// Inclusive range over row times (days for daily rows) or block heights; zero ends are open
+	FromTime   time.Time
+	FromHeight int64
+	ToTime     time.Time
+	ToHeight   int64
+
+	Ascending  bool         // oldest first instead of newest first
+	MinAmount  *numeric.Int // smallest amount held
+	MinChange  *numeric.Int // smallest change either way, positive; hourly rows only
+	ChangeSign int          // 1 for increases only, -1 for decreases only; hourly rows only
+	Limit      int
+	Offset     int
+}
+
+// PageQuery selects a page of one validator's records
+type PageQuery struct {
+	ChainID          string
+	ValidatorAddress string
+	Limit            int
+	Offset           int
+}
+
+// UnbondingQuery selects a validator's pending unbondings that complete within a window, soonest first
+type UnbondingQuery struct {
+	ChainID          string
+	ValidatorAddress string
+	After            time.Time // only entries completing after this time
+	Until            time.Time // and no later than this one; zero for no bound
+	Limit            int       // negative for every entry
+	Offset           int
+}
+
+// UnbondingSettlement counts the pending unbondings a snapshot no longer listed
+type UnbondingSettlement struct {
+	Completed int64 // due by the snapshot's block time, so they matured
+	Cancelled int64 // not yet due, so the delegator cancelled them
+}
+
+// RedelegationQuery selects a page of redelegations into or out of a validator, newest first
+type RedelegationQuery struct {
+	ChainID          string
+	ValidatorAddress string
+	Direction        string // models.RedelegationIn or models.RedelegationOut; empty for both
+	Limit            int
+	Offset           int
+}
+
+// RedelegationCounterpart totals the stake moved between a validator and one other validator
+type RedelegationCounterpart struct {
+	ValidatorAddress string
+	Amount           numeric.Int
+	Count            int64
+}
+
+// RecordCounts sizes the main tables for health reporting
+type RecordCounts struct {
+	WatchlistEntries  int64
+	HourlyDelegations int64
+	DailyDelegations  int64
+}
+
+// Table holding each rollup period
+var RollupTables = map[string]string{
+	models.PeriodDay:   "daily_delegation_rollups",
+	models.PeriodWeek:  "weekly_delegation_rollups",
+	models.PeriodMonth: "monthly_delegation_rollups",
+}
+
+// DenseDelegation is a snapshot row as of a collection run, which may be later than the run that wrote it
+type DenseDelegation struct {
+	models.HourlyDelegation
+	RunTimestamp time.Time
+	RunHeight    int64
+	RunBlockTime time.Time
+}
+
+// CurrentTotals sums every current delegator of a validator, not just one page
+type CurrentTotals struct {
+	TotalDelegators int64
+	TotalStake      numeric.Int
+}
+
+// RollupPeriod is a day, ISO week or month rebuilt when one of its days is aggregated
+type RollupPeriod struct {
+	Period string    // models.PeriodDay, PeriodWeek or PeriodMonth
+	Start  time.Time // midnight starting its first day in the aggregation timezone
+	End    time.Time // midnight ending its last day
+	Label  string
+}
+
+// DayAggregation rebuilds one entry's daily rows, daily validator stats and rollups for one day
+type DayAggregation struct {
+	Watchlist models.Watchlist
+	Timezone  string         // name of the aggregation timezone, stored with every row
+	Day       RollupPeriod   // the day being aggregated
+	Periods   []RollupPeriod // longer periods containing the day, rebuilt from its day rollups
+}
+
+// PartitionRows counts one validator's rows in a partition of hourly_delegations
+type PartitionRows struct {
+	ChainID          string
+	ValidatorAddress string
+	RowCount         int64
+}
+
+// Snapshot is everything one collection run writes for a validator
+type Snapshot struct {
+	Delegations []models.HourlyDelegation // changed and exited positions only in change-only storage
+	Stats       models.HourlyValidatorStats
+	Heartbeat   models.CollectionHeartbeat // its RunID identifies the run; rows carry the same one
+}
+
+// DelegationRepository reads stored delegation history and current positions
+type DelegationRepository interface {
+	// hourly snapshot rows, newest first, with the number of matching rows
+	HourlyDelegations(ctx context.Context, query DelegationQuery) ([]models.HourlyDelegation, int64, error)
+	// one row per delegator and collection run, newest run first, carrying unchanged positions forward
+	DenseDelegations(ctx context.Context, query DelegationQuery) ([]DenseDelegation, int64, error)
+	// daily rows, newest day first, with the number of matching rows
+	DailyDelegations(ctx context.Context, query DelegationQuery) ([]models.DailyDelegation, int64, error)
+	// delegators still staked, largest first, with totals across all of them
+	CurrentDelegators(ctx context.Context, query DelegationQuery) ([]models.CurrentDelegation, CurrentTotals, error)
+	// one delegator's position while still staked, or ErrNotFound
+	CurrentDelegation(ctx context.Context, chainID, validatorAddress, delegatorAddress string) (models.CurrentDelegation, error)
+	// rollups of one period, newest period first, with the number of matching rows
+	Rollups(ctx context.Context, period string, query DelegationQuery) ([]models.DelegationRollup, int64, error)
+}
+
+// WatchlistRepository stores the validators being tracked
+type WatchlistRepository interface {
+	CreateWatchlist(ctx context.Context, entry *models.Watchlist) error
+	// every entry, or only one chain's when chainID is set
+	ListWatchlist(ctx context.Context, chainID string) ([]models.Watchlist, error)
+	// the entry tracking a validator, or ErrNotFound
+	FindWatchlist(ctx context.Context, chainID, validatorAddress string) (models.Watchlist, error)
+	DeleteWatchlist(ctx context.Context, id uint) error
+}
+
+// SnapshotRepository is the collector's view of delegation state
+type SnapshotRepository interface {
+	// the last known position of every delegator ever seen with a validator, keyed by address
+	CurrentPositions(ctx context.Context, chainID, validatorAddress string) (map[string]models.CurrentDelegation, error)
+	// writes a run's rows, updates current positions and records its stats and heartbeat, all or nothing;
+	// ErrRunExists, with nothing written, when the validator already has a heartbeat for the run
+	SaveSnapshot(ctx context.Context, snapshot Snapshot) error
+}
+
+// UnbondingRepository stores the unbonding queues of watched validators
+type UnbondingRepository interface {
+	// upserts the entries listed at a height and settles the validator's pending entries it no longer lists
+	SaveUnbondings(ctx context.Context, chainID, validatorAddress string, unbondings []models.UnbondingDelegation, height int64, blockTime time.Time) (UnbondingSettlement, error)
+	// pending entries, soonest completion first, with the number of matching entries
+	PendingUnbondings(ctx context.Context, query UnbondingQuery) ([]models.UnbondingDelegation, int64, error)
+}
+
+// RedelegationRepository stores redelegations into and out of watched validators
+type RedelegationRepository interface {
+	// the height up to which tx search has been fully scanned in one direction, zero when never scanned
+	RedelegationCursor(ctx context.Context, chainID, validatorAddress, direction string) (int64, error)
+	// stores redelegations, skipping events already stored, and advances the scan cursors in the
+	// same transaction; returns how many redelegations were new
+	SaveRedelegations(ctx context.Context, redelegations []models.Redelegation, cursors []models.RedelegationScanCursor) (int64, error)
+	// redelegations, newest first, with the number of matching rows
+	Redelegations(ctx context.Context, query RedelegationQuery) ([]models.Redelegation, int64, error)
+	// stake moved per counterpart validator in one direction, largest first
+	RedelegationSummary(ctx context.Context, chainID, validatorAddress, direction string) ([]RedelegationCounterpart, error)
+}
+
+// ValidatorRepository stores validator-level snapshots and stats
+type ValidatorRepository interface {
+	// writes a validator snapshot, once per collection run, and names its watchlist entry after the
+	// moniker when rename is set
+	SaveValidatorSnapshot(ctx context.Context, snapshot models.ValidatorSnapshot, rename bool) error
+	// snapshots, newest first, with the number of matching rows
+	ValidatorSnapshots(ctx context.Context, query PageQuery) ([]models.ValidatorSnapshot, int64, error)
+	// the newest snapshot of a validator, or ErrNotFound
+	LatestValidatorSnapshot(ctx context.Context, chainID, validatorAddress string) (models.ValidatorSnapshot, error)
+	// per-run stats, newest first, with the number of matching rows
+	HourlyValidatorStats(ctx context.Context, query PageQuery) ([]models.HourlyValidatorStats, int64, error)
+	// daily stats, newest day first, with the number of matching rows
+	DailyValidatorStats(ctx context.Context, query PageQuery) ([]models.DailyValidatorStats, int64, error)
+}
+
+// AggregationRepository rolls stored snapshots into daily rows, rollups and daily validator stats
+type AggregationRepository interface {
+	// the date of an entry's newest daily row, or false before its first aggregation
+	LatestDailyDate(ctx context.Context, chainID, validatorAddress string) (time.Time, bool, error)
+	// the time of an entry's oldest stored snapshot, or false before its first
+	FirstSnapshotTime(ctx context.Context, chainID, validatorAddress string) (time.Time, bool, error)
+	// rebuilds a day from each delegator's last snapshot as of its end, all or nothing, and returns the
+	// daily rows written; days without a run, or whose snapshots were pruned, are left untouched
+	AggregateDay(ctx context.Context, aggregation DayAggregation) (int, error)
+}
+
+// RetentionRepository prunes hourly tables once their days are rolled up, and their Postgres partitions
+type RetentionRepository interface {
+	// the date of an entry's newest row in the daily table an hourly table is rolled into, or false
+	// while nothing is rolled up
+	LatestRolledUpDate(ctx context.Context, table, chainID, validatorAddress string) (time.Time, bool, error)
+	// deletes an entry's rows of an hourly table older than cutoff, at most batchSize per statement, and
+	// keeps each delegator's last snapshot so positions can still be carried forward; returns the rows
+	// deleted, also when it stops part way
+	PruneHourly(ctx context.Context, table, chainID, validatorAddress string, cutoff time.Time, batchSize int) (int64, error)
+	SaveRetentionRun(ctx context.Context, run *models.RetentionRun) error
+	// retention runs, newest first, with the number of runs
+	RetentionRuns(ctx context.Context, limit, offset int) ([]models.RetentionRun, int64, error)
+
+	// whether hourly_delegations is split into monthly partitions, which only the Postgres migrations do
+	HourlyPartitioned() bool
+	// creates the partition for the month starting at month unless it exists
+	CreateHourlyPartition(ctx context.Context, month time.Time) error
+	// the names of the partitions attached to hourly_delegations
+	HourlyPartitions(ctx context.Context) ([]string, error)
+	// each validator's rows in a partition
+	PartitionRows(ctx context.Context, partition string) ([]PartitionRows, error)
+	// counts delegators whose last row in a partition ending at end shows them staked and who have no
+	// row from end until cutoff, so the partition still holds their position as of the cutoff
+	PinnedPartitionRows(ctx context.Context, partition string, end, cutoff time.Time) (int64, error)
+	// records the runs and detaches and drops a partition, all or nothing
+	DropHourlyPartition(ctx context.Context, partition string, runs []models.RetentionRun) error
+}
+
+// MigrationRepository reports on the schema migrations of the storage backend
+type MigrationRepository interface {
+	// every migration of this build and whether it is applied, plus any applied by a newer build
+	MigrationStatuses(ctx context.Context) ([]db.MigrationStatus, error)
+	// the latest migration events, newest first
+	MigrationHistory(ctx context.Context, limit int) ([]models.MigrationHistory, error)
+}
+
+// HealthRepository reports on the storage backend for health checks
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	RecordCounts(ctx context.Context) (RecordCounts, error)
+	// the newest heartbeat of any validator, or ErrNotFound before the first run
+	LatestHeartbeat(ctx context.Context) (models.CollectionHeartbeat, error)
+}
+
+// Repositories groups one storage backend's repositories
+type Repositories struct {
+	Delegations   DelegationRepository
+	Watchlist     WatchlistRepository
+	Snapshots     SnapshotRepository
+	Unbondings    UnbondingRepository
+	Redelegations RedelegationRepository
+	Validators    ValidatorRepository
+	Health        HealthRepository
+	Aggregation   AggregationRepository
+	Retention     RetentionRepository
+	Migrations    MigrationRepository
+}
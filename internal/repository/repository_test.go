@@ -0,0 +1,732 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/pkg/db"
+	"cosmos-tracker/pkg/numeric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+// a storage backend under test and a way to seed the daily rows only the aggregator writes
+type backend struct {
+	repos    Repositories
+	addDaily func(rows ...models.DailyDelegation)
+}
+
+// returns the GORM repositories on a migrated in-memory SQLite database, and the in-memory ones
+func backends(t *testing.T) map[string]func(t *testing.T) backend {
+	return map[string]func(t *testing.T) backend{
+		"gorm": func(t *testing.T) backend {
+			t.Setenv("DB_DRIVER", db.DriverSQLite)
+			t.Setenv("SQLITE_PATH", ":memory:")
+			database, err := db.Open()
+			require.NoError(t, err)
+			database.Logger = logger.Discard
+
+			previous := db.DB
+			db.DB = database
+			t.Cleanup(func() {
+				db.DB = previous
+				if sqlDB, err := database.DB(); err == nil {
+					sqlDB.Close()
+				}
+			})
+			_, err = db.MigrateUp(context.Background(), 0)
+			require.NoError(t, err)
+
+			return backend{
+				repos: NewGorm(database),
+				addDaily: func(rows ...models.DailyDelegation) {
+					require.NoError(t, database.Create(&rows).Error)
+				},
+			}
+		},
+		"memory": func(t *testing.T) backend {
+			store := NewMemoryStore()
+			return backend{repos: store.Repositories(), addDaily: store.AddDailyDelegations}
+		},
+	}
+}
+
+// builds one run's snapshot for the validator, identified by its height
+func run(watchlist models.Watchlist, at time.Time, height int64, rows ...models.HourlyDelegation) Snapshot {
+	runID := fmt.Sprint(height)
+	for i := range rows {
+		rows[i].RunID = runID
+		rows[i].WatchlistID = watchlist.ID
+		rows[i].ChainID = watchlist.ChainID
+		rows[i].ValidatorAddress = watchlist.ValidatorAddress
+		rows[i].BlockHeight = height
+		rows[i].BlockTime = at
+		rows[i].Timestamp = at
+	}
+	return Snapshot{
+		Delegations: rows,
+		Stats: models.HourlyValidatorStats{
+			ValidatorStats: models.ValidatorStats{WatchlistID: watchlist.ID, ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, BlockHeight: height},
+			Timestamp:      at,
+		},
+		Heartbeat: models.CollectionHeartbeat{
+			WatchlistID:      watchlist.ID,
+			ChainID:          watchlist.ChainID,
+			ValidatorAddress: watchlist.ValidatorAddress,
+			RunID:            runID,
+			Timestamp:        at,
+			BlockHeight:      height,
+			BlockTime:        at,
+			RowsWritten:      int64(len(rows)),
+		},
+	}
+}
+
+func TestRepositoriesBehaveAlike(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			b := open(t)
+			ctx := context.Background()
+
+			// Watchlist
+			watchlist := models.Watchlist{ChainID: "cosmoshub-4", ValidatorAddress: "cosmosvaloper1watched"}
+			require.NoError(t, b.repos.Watchlist.CreateWatchlist(ctx, &watchlist))
+			require.NotZero(t, watchlist.ID)
+
+			other := models.Watchlist{ChainID: "osmosis-1", ValidatorAddress: "osmovaloper1other"}
+			require.NoError(t, b.repos.Watchlist.CreateWatchlist(ctx, &other))
+			entries, err := b.repos.Watchlist.ListWatchlist(ctx, "cosmoshub-4")
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, watchlist.ID, entries[0].ID)
+
+			require.NoError(t, b.repos.Watchlist.DeleteWatchlist(ctx, other.ID))
+			_, err = b.repos.Watchlist.FindWatchlist(ctx, other.ChainID, other.ValidatorAddress)
+			assert.ErrorIs(t, err, ErrNotFound)
+			found, err := b.repos.Watchlist.FindWatchlist(ctx, watchlist.ChainID, watchlist.ValidatorAddress)
+			require.NoError(t, err)
+			assert.Equal(t, watchlist.ID, found.ID)
+
+			// Three runs: both delegators, then Alice grows and Bob exits, then nothing changes
+			t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+			t1, t2 := t0.Add(time.Hour), t0.Add(2*time.Hour)
+			require.NoError(t, b.repos.Snapshots.SaveSnapshot(ctx, run(watchlist, t0, 100,
+				models.HourlyDelegation{DelegatorAddress: "cosmos1alice", DelegationAmount: numeric.NewInt(1000), ChangeAmount: numeric.NewInt(1000)},
+				models.HourlyDelegation{DelegatorAddress: "cosmos1bob", DelegationAmount: numeric.NewInt(500), ChangeAmount: numeric.NewInt(500)})))
+			require.NoError(t, b.repos.Snapshots.SaveSnapshot(ctx, run(watchlist, t1, 101,
+				models.HourlyDelegation{DelegatorAddress: "cosmos1alice", DelegationAmount: numeric.NewInt(1200), ChangeAmount: numeric.NewInt(200)},
+				models.HourlyDelegation{DelegatorAddress: "cosmos1bob", DelegationAmount: numeric.NewInt(0), ChangeAmount: numeric.NewInt(-500), Exited: true})))
+			require.NoError(t, b.repos.Snapshots.SaveSnapshot(ctx, run(watchlist, t2, 102)))
+
+			// Saving a run again writes nothing, even with different rows
+			err = b.repos.Snapshots.SaveSnapshot(ctx, run(watchlist, t2.Add(time.Minute), 102,
+				models.HourlyDelegation{DelegatorAddress: "cosmos1carol", DelegationAmount: numeric.NewInt(700), ChangeAmount: numeric.NewInt(700)}))
+			assert.ErrorIs(t, err, ErrRunExists)
+
+			positions, err := b.repos.Snapshots.CurrentPositions(ctx, watchlist.ChainID, watchlist.ValidatorAddress)
+			require.NoError(t, err)
+			require.Len(t, positions, 2)
+			assert.Equal(t, "1200", positions["cosmos1alice"].DelegationAmount.String())
+			assert.Equal(t, int64(101), positions["cosmos1alice"].BlockHeight)
+			assert.True(t, positions["cosmos1bob"].Exited)
+
+			query := DelegationQuery{ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, Limit: 10}
+
+			// Hourly rows, newest first
+			hourly, total, err := b.repos.Delegations.HourlyDelegations(ctx, query)
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), total)
+			require.Len(t, hourly, 4)
+			assert.Equal(t, "cosmos1bob", hourly[0].DelegatorAddress)
+			assert.True(t, hourly[0].Exited)
+			assert.True(t, hourly[3].Timestamp.Equal(t0))
+
+			filtered := query
+			filtered.DelegatorAddress, filtered.Height = "cosmos1alice", 100
+			hourly, total, err = b.repos.Delegations.HourlyDelegations(ctx, filtered)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), total)
+			assert.Equal(t, "1000", hourly[0].DelegationAmount.String())
+
+			// Dense rows: Bob's exit only at its own run, Alice carried to the last one
+			dense, total, err := b.repos.Delegations.DenseDelegations(ctx, query)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), total)
+			require.Len(t, dense, 5)
+			assert.True(t, dense[0].RunTimestamp.Equal(t2))
+			assert.Equal(t, int64(102), dense[0].RunHeight)
+			assert.Equal(t, "cosmos1alice", dense[0].DelegatorAddress)
+			assert.True(t, dense[0].Timestamp.Equal(t1))
+			assert.Equal(t, "cosmos1bob", dense[2].DelegatorAddress)
+			assert.True(t, dense[2].Exited)
+
+			paged := query
+			paged.Limit, paged.Offset, paged.Height = 1, 1, 100
+			dense, total, err = b.repos.Delegations.DenseDelegations(ctx, paged)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), total)
+			require.Len(t, dense, 1)
+			assert.Equal(t, "cosmos1bob", dense[0].DelegatorAddress)
+
+			// Ranges are inclusive and can run oldest first
+			ranged := query
+			ranged.FromTime, ranged.Ascending = t1, true
+			hourly, total, err = b.repos.Delegations.HourlyDelegations(ctx, ranged)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), total)
+			require.Len(t, hourly, 2)
+			assert.Equal(t, "cosmos1alice", hourly[0].DelegatorAddress)
+			assert.Equal(t, "cosmos1bob", hourly[1].DelegatorAddress)
+
+			ranged = query
+			ranged.FromHeight, ranged.ToHeight = 100, 100
+			_, total, err = b.repos.Delegations.HourlyDelegations(ctx, ranged)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), total)
+
+			// Amount filters: the size of a change counts either way, the sign picks a direction
+			minAmount, minChange := numeric.NewInt(600), numeric.NewInt(400)
+			for _, c := range []struct {
+				minAmount, minChange *numeric.Int
+				sign                 int
+				want                 int64
+			}{
+				{minAmount: &minAmount, want: 2},
+				{minChange: &minChange, want: 3},
+				{sign: -1, want: 1},
+				{minChange: &minChange, sign: 1, want: 2},
+			} {
+				amounts := query
+				amounts.MinAmount, amounts.MinChange, amounts.ChangeSign = c.minAmount, c.minChange, c.sign
+				_, total, err = b.repos.Delegations.HourlyDelegations(ctx, amounts)
+				require.NoError(t, err)
+				assert.Equal(t, c.want, total)
+			}
+
+			// Carried dense rows have not changed and use their run's time
+			ranged = query
+			ranged.ChangeSign, ranged.Ascending = 1, true
+			dense, total, err = b.repos.Delegations.DenseDelegations(ctx, ranged)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), total)
+			require.Len(t, dense, 3)
+			assert.True(t, dense[0].RunTimestamp.Equal(t0))
+
+			ranged = query
+			ranged.ToTime, ranged.MinAmount = t1, &minAmount
+			_, total, err = b.repos.Delegations.DenseDelegations(ctx, ranged)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), total)
+
+			// Current positions hide exits
+			current, totals, err := b.repos.Delegations.CurrentDelegators(ctx, query)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), totals.TotalDelegators)
+			assert.Equal(t, "1200", totals.TotalStake.String())
+			require.Len(t, current, 1)
+			assert.Equal(t, "cosmos1alice", current[0].DelegatorAddress)
+
+			_, err = b.repos.Delegations.CurrentDelegation(ctx, watchlist.ChainID, watchlist.ValidatorAddress, "cosmos1bob")
+			assert.ErrorIs(t, err, ErrNotFound)
+			position, err := b.repos.Delegations.CurrentDelegation(ctx, watchlist.ChainID, watchlist.ValidatorAddress, "cosmos1alice")
+			require.NoError(t, err)
+			assert.Equal(t, "1200", position.DelegationAmount.String())
+
+			// Daily rows, newest day first
+			day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+			b.addDaily(
+				models.DailyDelegation{WatchlistID: watchlist.ID, ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress,
+					DelegatorAddress: "cosmos1alice", TotalDelegation: numeric.NewInt(1000), BlockHeight: 90, Date: day.AddDate(0, 0, -1)},
+				models.DailyDelegation{WatchlistID: watchlist.ID, ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress,
+					DelegatorAddress: "cosmos1alice", TotalDelegation: numeric.NewInt(1200), BlockHeight: 102, Date: day},
+			)
+			daily, total, err := b.repos.Delegations.DailyDelegations(ctx, query)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), total)
+			require.Len(t, daily, 2)
+			assert.Equal(t, "1200", daily[0].TotalDelegation.String())
+
+			ranged = query
+			ranged.ToTime, ranged.Ascending = day, true
+			daily, total, err = b.repos.Delegations.DailyDelegations(ctx, ranged)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), total)
+			require.Len(t, daily, 2)
+			assert.Equal(t, "1000", daily[0].TotalDelegation.String())
+
+			dailyMin := numeric.NewInt(1100)
+			ranged = query
+			ranged.FromTime, ranged.MinAmount = day.AddDate(0, 0, -1), &dailyMin
+			_, total, err = b.repos.Delegations.DailyDelegations(ctx, ranged)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), total)
+
+			// Amounts and shares far beyond 64-bit integers keep every digit, their order and their sums
+			big, _ := numeric.ParseInt("123456789012345678901234567")
+			bigger := big.Add(numeric.NewInt(1))
+			shares, _ := numeric.ParseDec("123456789012345678901234567.123456789012345678")
+			require.NoError(t, b.repos.Snapshots.SaveSnapshot(ctx, run(watchlist, t2.Add(time.Hour), 103,
+				models.HourlyDelegation{DelegatorAddress: "cosmos1carol", DelegationAmount: big, ChangeAmount: big, Shares: shares},
+				models.HourlyDelegation{DelegatorAddress: "cosmos1dave", DelegationAmount: bigger, ChangeAmount: bigger.Neg(), Shares: shares})))
+
+			positions, err = b.repos.Snapshots.CurrentPositions(ctx, watchlist.ChainID, watchlist.ValidatorAddress)
+			require.NoError(t, err)
+			assert.Equal(t, "123456789012345678901234567", positions["cosmos1carol"].DelegationAmount.String())
+			assert.Equal(t, 0, positions["cosmos1carol"].Shares.Cmp(shares), "unchanged shares must compare equal")
+
+			filtered = query
+			filtered.Height = 103
+			hourly, _, err = b.repos.Delegations.HourlyDelegations(ctx, filtered)
+			require.NoError(t, err)
+			require.Len(t, hourly, 2)
+			for _, h := range hourly {
+				assert.Equal(t, "123456789012345678901234567.123456789012345678", h.Shares.String())
+			}
+
+			current, totals, err = b.repos.Delegations.CurrentDelegators(ctx, query)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), totals.TotalDelegators)
+			assert.Equal(t, "246913578024691357802470335", totals.TotalStake.String())
+			require.Len(t, current, 3)
+			assert.Equal(t, []string{"cosmos1dave", "cosmos1carol", "cosmos1alice"},
+				[]string{current[0].DelegatorAddress, current[1].DelegatorAddress, current[2].DelegatorAddress})
+
+			threshold, _ := numeric.ParseInt("100000000000000000000")
+			for sign, want := range map[int]int64{0: 2, 1: 1, -1: 1} {
+				amounts := filtered
+				amounts.Height, amounts.MinAmount, amounts.MinChange, amounts.ChangeSign = 0, &threshold, &threshold, sign
+				_, total, err = b.repos.Delegations.HourlyDelegations(ctx, amounts)
+				require.NoError(t, err)
+				assert.Equal(t, want, total, "change sign %d", sign)
+			}
+		})
+	}
+}
+
+func TestSavingARunTwiceStoresItOnce(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			b := open(t)
+			ctx := context.Background()
+			watchlist := models.Watchlist{ChainID: "cosmoshub-4", ValidatorAddress: "cosmosvaloper1watched"}
+			require.NoError(t, b.repos.Watchlist.CreateWatchlist(ctx, &watchlist))
+
+			// The collector timestamps a run with the start of its hour, so a repeat carries the same keys
+			at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+			snapshot := func() Snapshot {
+				return run(watchlist, at, 100,
+					models.HourlyDelegation{DelegatorAddress: "cosmos1alice", DelegationAmount: numeric.NewInt(1000), ChangeAmount: numeric.NewInt(1000)},
+					models.HourlyDelegation{DelegatorAddress: "cosmos1bob", DelegationAmount: numeric.NewInt(500), ChangeAmount: numeric.NewInt(500)})
+			}
+			require.NoError(t, b.repos.Snapshots.SaveSnapshot(ctx, snapshot()))
+			assert.ErrorIs(t, b.repos.Snapshots.SaveSnapshot(ctx, snapshot()), ErrRunExists)
+
+			counts, err := b.repos.Health.RecordCounts(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), counts.HourlyDelegations)
+
+			// Past the heartbeat, the snapshot rows' own run key refuses them too
+			if name == "gorm" {
+				assert.Error(t, db.DB.Create(snapshot().Delegations[:1]).Error)
+			}
+		})
+	}
+}
+
+// records every statement GORM runs
+type statementLog struct {
+	logger.Interface
+	statements []string
+}
+
+func (l *statementLog) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	l.statements = append(l.statements, sql)
+}
+
+func TestDenseRangesStartFromTheLastFullRun(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			b := open(t)
+			ctx := context.Background()
+			watchlist := models.Watchlist{ChainID: "cosmoshub-4", ValidatorAddress: "cosmosvaloper1watched"}
+			require.NoError(t, b.repos.Watchlist.CreateWatchlist(ctx, &watchlist))
+
+			// Full, change-only, full, change-only and empty change-only runs an hour apart
+			t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+			at := func(hour int) time.Time { return t0.Add(time.Duration(hour) * time.Hour) }
+			holding := func(delegator string, amount, change int64) models.HourlyDelegation {
+				return models.HourlyDelegation{DelegatorAddress: delegator, DelegationAmount: numeric.NewInt(amount), ChangeAmount: numeric.NewInt(change)}
+			}
+			for i, r := range []struct {
+				mode string
+				rows []models.HourlyDelegation
+			}{
+				{config.StorageModeFull, []models.HourlyDelegation{holding("cosmos1alice", 1000, 1000), holding("cosmos1bob", 500, 500), holding("cosmos1carol", 300, 300)}},
+				{config.StorageModeChanges, []models.HourlyDelegation{{DelegatorAddress: "cosmos1bob", DelegationAmount: numeric.NewInt(0), ChangeAmount: numeric.NewInt(-500), Exited: true}}},
+				{config.StorageModeFull, []models.HourlyDelegation{holding("cosmos1alice", 1000, 0), holding("cosmos1carol", 300, 0)}},
+				{config.StorageModeChanges, []models.HourlyDelegation{holding("cosmos1carol", 400, 100)}},
+				{config.StorageModeChanges, nil},
+			} {
+				snapshot := run(watchlist, at(i), int64(100+i), r.rows...)
+				snapshot.Heartbeat.StorageMode = r.mode
+				require.NoError(t, b.repos.Snapshots.SaveSnapshot(ctx, snapshot))
+			}
+
+			var statements *statementLog
+			if name == "gorm" {
+				statements = &statementLog{Interface: logger.Discard}
+				db.DB.Logger = statements
+			}
+
+			query := DelegationQuery{ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, FromTime: at(3), Ascending: true, Limit: 10}
+			dense, total, err := b.repos.Delegations.DenseDelegations(ctx, query)
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), total)
+			require.Len(t, dense, 4)
+			var got []string
+			for _, d := range dense {
+				got = append(got, fmt.Sprintf("%d %s %s", d.RunHeight, d.DelegatorAddress, d.DelegationAmount))
+			}
+			assert.Equal(t, []string{"103 cosmos1alice 1000", "103 cosmos1carol 400", "104 cosmos1alice 1000", "104 cosmos1carol 400"}, got)
+
+			bounded := query
+			bounded.FromTime, bounded.FromHeight, bounded.ToHeight = time.Time{}, 101, 101
+			dense, total, err = b.repos.Delegations.DenseDelegations(ctx, bounded)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), total)
+			require.Len(t, dense, 3)
+			assert.True(t, dense[1].Exited)
+
+			// The history read by the spans starts at the full run before the range and ends with it
+			if statements != nil {
+				var spans []string
+				for _, statement := range statements.statements {
+					if strings.Contains(statement, "WITH spans") {
+						spans = append(spans, statement)
+					}
+				}
+				require.NotEmpty(t, spans)
+				assert.Contains(t, spans[0], "h.timestamp >= \"2024-03-01 12:00:00")
+				assert.Contains(t, spans[len(spans)-1], "h.block_height <= 101")
+			}
+		})
+	}
+}
+
+func TestQueueAndValidatorRepositoriesBehaveAlike(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			b := open(t)
+			ctx := context.Background()
+
+			watchlist := models.Watchlist{ChainID: "cosmoshub-4", ValidatorAddress: "cosmosvaloper1watched"}
+			require.NoError(t, b.repos.Watchlist.CreateWatchlist(ctx, &watchlist))
+
+			// Unbondings: an entry left the queue before maturing, another after, one is still listed
+			t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+			entry := func(delegator string, completion time.Time, seen int64) models.UnbondingDelegation {
+				return models.UnbondingDelegation{
+					WatchlistID: watchlist.ID, ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress,
+					DelegatorAddress: delegator, CreationHeight: 50, CompletionTime: completion,
+					InitialBalance: numeric.NewInt(100), Balance: numeric.NewInt(100), Status: models.UnbondingPending, LastSeenHeight: seen,
+				}
+			}
+			_, err := b.repos.Unbondings.SaveUnbondings(ctx, watchlist.ChainID, watchlist.ValidatorAddress, []models.UnbondingDelegation{
+				entry("cosmos1matured", t0.Add(time.Hour), 100),
+				entry("cosmos1cancelled", t0.Add(48*time.Hour), 100),
+				entry("cosmos1pending", t0.Add(24*time.Hour), 100),
+			}, 100, t0)
+			require.NoError(t, err)
+
+			settlement, err := b.repos.Unbondings.SaveUnbondings(ctx, watchlist.ChainID, watchlist.ValidatorAddress, []models.UnbondingDelegation{
+				entry("cosmos1pending", t0.Add(24*time.Hour), 101),
+			}, 101, t0.Add(2*time.Hour))
+			require.NoError(t, err)
+			assert.Equal(t, UnbondingSettlement{Completed: 1, Cancelled: 1}, settlement)
+
+			pending, total, err := b.repos.Unbondings.PendingUnbondings(ctx, UnbondingQuery{
+				ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, After: t0, Limit: -1,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), total)
+			require.Len(t, pending, 1)
+			assert.Equal(t, "cosmos1pending", pending[0].DelegatorAddress)
+
+			_, total, err = b.repos.Unbondings.PendingUnbondings(ctx, UnbondingQuery{
+				ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, After: t0, Until: t0.Add(time.Hour), Limit: -1,
+			})
+			require.NoError(t, err)
+			assert.Zero(t, total)
+
+			// Redelegations: one out to each of two validators and one in; the duplicate is ignored
+			redelegation := func(hash string, src, dst string, amount, height int64) models.Redelegation {
+				return models.Redelegation{
+					ChainID: watchlist.ChainID, TxHash: hash, DelegatorAddress: "cosmos1mover",
+					SrcValidatorAddress: src, DstValidatorAddress: dst, Amount: numeric.NewInt(amount), Denom: "uatom",
+					Height: height, Timestamp: t0,
+				}
+			}
+			cursor := func(direction string, height int64) models.RedelegationScanCursor {
+				return models.RedelegationScanCursor{
+					ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, Direction: direction, ScannedHeight: height,
+				}
+			}
+			height, err := b.repos.Redelegations.RedelegationCursor(ctx, watchlist.ChainID, watchlist.ValidatorAddress, models.RedelegationIn)
+			require.NoError(t, err)
+			assert.Zero(t, height)
+
+			saved, err := b.repos.Redelegations.SaveRedelegations(ctx, []models.Redelegation{
+				redelegation("A", watchlist.ValidatorAddress, "cosmosvaloper1x", 300, 10),
+				redelegation("B", watchlist.ValidatorAddress, "cosmosvaloper1y", 500, 12),
+				redelegation("C", "cosmosvaloper1x", watchlist.ValidatorAddress, 200, 11),
+			}, []models.RedelegationScanCursor{cursor(models.RedelegationOut, 12), cursor(models.RedelegationIn, 11)})
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), saved)
+			saved, err = b.repos.Redelegations.SaveRedelegations(ctx, []models.Redelegation{
+				redelegation("A", watchlist.ValidatorAddress, "cosmosvaloper1x", 300, 10),
+			}, []models.RedelegationScanCursor{cursor(models.RedelegationIn, 20)})
+			require.NoError(t, err)
+			assert.Zero(t, saved)
+
+			// The cursor moves on even when a scan finds nothing new
+			height, err = b.repos.Redelegations.RedelegationCursor(ctx, watchlist.ChainID, watchlist.ValidatorAddress, models.RedelegationIn)
+			require.NoError(t, err)
+			assert.Equal(t, int64(20), height)
+			height, err = b.repos.Redelegations.RedelegationCursor(ctx, watchlist.ChainID, watchlist.ValidatorAddress, models.RedelegationOut)
+			require.NoError(t, err)
+			assert.Equal(t, int64(12), height)
+
+			redelegations, total, err := b.repos.Redelegations.Redelegations(ctx, RedelegationQuery{
+				ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, Limit: 10,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), total)
+			assert.Equal(t, "B", redelegations[0].TxHash)
+
+			summary, err := b.repos.Redelegations.RedelegationSummary(ctx, watchlist.ChainID, watchlist.ValidatorAddress, models.RedelegationOut)
+			require.NoError(t, err)
+			require.Len(t, summary, 2)
+			assert.Equal(t, "cosmosvaloper1y", summary[0].ValidatorAddress)
+			assert.Equal(t, "500", summary[0].Amount.String())
+			assert.Equal(t, int64(1), summary[1].Count)
+
+			// Validator snapshots, newest first; a rename reaches the watchlist and a repeated run is ignored
+			_, err = b.repos.Validators.LatestValidatorSnapshot(ctx, watchlist.ChainID, watchlist.ValidatorAddress)
+			assert.ErrorIs(t, err, ErrNotFound)
+			for i, moniker := range []string{"Old", "New", "Repeated"} {
+				height := int64(100 + min(i, 1))
+				require.NoError(t, b.repos.Validators.SaveValidatorSnapshot(ctx, models.ValidatorSnapshot{
+					WatchlistID: watchlist.ID, ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, RunID: fmt.Sprint(height),
+					Moniker: moniker, Tokens: numeric.NewInt(1000), BlockHeight: height, Timestamp: t0.Add(time.Duration(i) * time.Hour),
+				}, i > 0))
+			}
+			latest, err := b.repos.Validators.LatestValidatorSnapshot(ctx, watchlist.ChainID, watchlist.ValidatorAddress)
+			require.NoError(t, err)
+			assert.Equal(t, "New", latest.Moniker)
+			snapshots, total, err := b.repos.Validators.ValidatorSnapshots(ctx, PageQuery{
+				ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, Limit: 1, Offset: 1,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), total)
+			require.Len(t, snapshots, 1)
+			assert.Equal(t, "Old", snapshots[0].Moniker)
+			renamed, err := b.repos.Watchlist.FindWatchlist(ctx, watchlist.ChainID, watchlist.ValidatorAddress)
+			require.NoError(t, err)
+			assert.Equal(t, "New", renamed.ValidatorName)
+
+			// Stats and health follow a saved run
+			require.NoError(t, b.repos.Snapshots.SaveSnapshot(ctx, run(watchlist, t0, 100,
+				models.HourlyDelegation{DelegatorAddress: "cosmos1alice", DelegationAmount: numeric.NewInt(1000), ChangeAmount: numeric.NewInt(1000)})))
+			stats, total, err := b.repos.Validators.HourlyValidatorStats(ctx, PageQuery{
+				ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, Limit: 10,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), total)
+			assert.Equal(t, int64(100), stats[0].BlockHeight)
+
+			require.NoError(t, b.repos.Health.Ping(ctx))
+			counts, err := b.repos.Health.RecordCounts(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, RecordCounts{WatchlistEntries: 1, HourlyDelegations: 1}, counts)
+			heartbeat, err := b.repos.Health.LatestHeartbeat(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, int64(100), heartbeat.BlockHeight)
+		})
+	}
+}
+
+func TestAggregationAndRetentionBehaveAlike(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			b := open(t)
+			ctx := context.Background()
+			watchlist := models.Watchlist{ChainID: "cosmoshub-4", ValidatorAddress: "cosmosvaloper1watched"}
+			require.NoError(t, b.repos.Watchlist.CreateWatchlist(ctx, &watchlist))
+
+			// Monday: a full run, then Alice grows and Bob exits. Tuesday: Carol joins, then nothing changes.
+			monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+			tuesday := monday.AddDate(0, 0, 1)
+			holding := func(delegator string, amount, change int64) models.HourlyDelegation {
+				return models.HourlyDelegation{DelegatorAddress: delegator, DelegationAmount: numeric.NewInt(amount), ChangeAmount: numeric.NewInt(change)}
+			}
+			for i, r := range []struct {
+				at   time.Time
+				mode string
+				rows []models.HourlyDelegation
+			}{
+				{monday.Add(10 * time.Hour), config.StorageModeFull, []models.HourlyDelegation{holding("cosmos1alice", 1000, 1000), holding("cosmos1bob", 500, 500)}},
+				{monday.Add(14 * time.Hour), config.StorageModeChanges, []models.HourlyDelegation{holding("cosmos1alice", 1200, 200), {DelegatorAddress: "cosmos1bob", DelegationAmount: numeric.NewInt(0), ChangeAmount: numeric.NewInt(-500), Exited: true}}},
+				{tuesday.Add(10 * time.Hour), config.StorageModeChanges, []models.HourlyDelegation{holding("cosmos1carol", 300, 300)}},
+				{tuesday.Add(11 * time.Hour), config.StorageModeChanges, nil},
+			} {
+				snapshot := run(watchlist, r.at, int64(100+i), r.rows...)
+				snapshot.Heartbeat.StorageMode = r.mode
+				snapshot.Stats.TotalDelegated = numeric.NewInt(int64(1000 + i))
+				snapshot.Stats.Inflow = numeric.NewInt(int64(10 * (i + 1)))
+				require.NoError(t, b.repos.Snapshots.SaveSnapshot(ctx, snapshot))
+			}
+
+			aggregation := func(day time.Time) DayAggregation {
+				return DayAggregation{
+					Watchlist: watchlist,
+					Timezone:  "UTC",
+					Day:       RollupPeriod{Period: models.PeriodDay, Start: day, End: day.AddDate(0, 0, 1), Label: day.Format("2006-01-02")},
+					Periods: []RollupPeriod{
+						{Period: models.PeriodWeek, Start: monday, End: monday.AddDate(0, 0, 7), Label: "2024-W10"},
+						{Period: models.PeriodMonth, Start: monday.AddDate(0, 0, -3), End: monday.AddDate(0, 0, 28), Label: "2024-03"},
+					},
+				}
+			}
+
+			_, ok, err := b.repos.Aggregation.LatestDailyDate(ctx, watchlist.ChainID, watchlist.ValidatorAddress)
+			require.NoError(t, err)
+			assert.False(t, ok)
+			first, ok, err := b.repos.Aggregation.FirstSnapshotTime(ctx, watchlist.ChainID, watchlist.ValidatorAddress)
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.True(t, first.Equal(monday.Add(10*time.Hour)))
+
+			// Bob's exit is reported on Monday only; a day without runs writes nothing
+			for i, day := range []time.Time{monday, tuesday, tuesday.AddDate(0, 0, 1)} {
+				rows, err := b.repos.Aggregation.AggregateDay(ctx, aggregation(day))
+				require.NoError(t, err)
+				assert.Equal(t, []int{2, 2, 0}[i], rows, day.Format("2006-01-02"))
+			}
+			// Aggregating again replaces the day's rows
+			_, err = b.repos.Aggregation.AggregateDay(ctx, aggregation(tuesday))
+			require.NoError(t, err)
+
+			latest, ok, err := b.repos.Aggregation.LatestDailyDate(ctx, watchlist.ChainID, watchlist.ValidatorAddress)
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.True(t, latest.Equal(tuesday))
+
+			daily, total, err := b.repos.Delegations.DailyDelegations(ctx, DelegationQuery{ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, Ascending: true, Limit: 10})
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), total)
+			var days []string
+			for _, d := range daily {
+				days = append(days, fmt.Sprintf("%s %s %s", d.Date.Format("2006-01-02"), d.DelegatorAddress, d.TotalDelegation))
+			}
+			assert.Equal(t, []string{
+				"2024-03-04 cosmos1alice 1200", "2024-03-04 cosmos1bob 0",
+				"2024-03-05 cosmos1alice 1200", "2024-03-05 cosmos1carol 300",
+			}, days)
+
+			// Open, close, min, max, net change and runs observed of each rollup
+			rollups := func(period string) []string {
+				rows, _, err := b.repos.Delegations.Rollups(ctx, period, DelegationQuery{ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, Limit: 10})
+				require.NoError(t, err)
+				var got []string
+				for _, r := range rows {
+					got = append(got, fmt.Sprintf("%s %s %s-%s [%s,%s] %s x%d",
+						r.Label, r.DelegatorAddress, r.OpenAmount, r.CloseAmount, r.MinAmount, r.MaxAmount, r.NetChange, r.Snapshots))
+				}
+				return got
+			}
+			assert.Equal(t, []string{
+				"2024-03-05 cosmos1alice 1200-1200 [1200,1200] 0 x2",
+				"2024-03-05 cosmos1carol 300-300 [300,300] 300 x2",
+				"2024-03-04 cosmos1alice 1000-1200 [1000,1200] 1200 x2",
+				"2024-03-04 cosmos1bob 500-0 [0,500] 0 x2",
+			}, rollups(models.PeriodDay))
+			assert.Equal(t, []string{
+				"2024-W10 cosmos1alice 1000-1200 [1000,1200] 1200 x4",
+				"2024-W10 cosmos1bob 500-0 [0,500] 0 x2",
+				"2024-W10 cosmos1carol 300-300 [300,300] 300 x2",
+			}, rollups(models.PeriodWeek))
+			assert.Len(t, rollups(models.PeriodMonth), 3)
+
+			stats, total, err := b.repos.Validators.DailyValidatorStats(ctx, PageQuery{ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, Limit: 10})
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), total)
+			require.Len(t, stats, 2)
+			assert.Equal(t, "1003", stats[0].TotalDelegated.String())
+			assert.Equal(t, "70", stats[0].Inflow.String())
+			assert.Equal(t, int64(103), stats[0].BlockHeight)
+
+			// Retention: only what was rolled up before the cutoff goes, and each delegator's last snapshot stays
+			rolledUp, ok, err := b.repos.Retention.LatestRolledUpDate(ctx, "hourly_validator_stats", watchlist.ChainID, watchlist.ValidatorAddress)
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.True(t, rolledUp.Equal(tuesday))
+			_, _, err = b.repos.Retention.LatestRolledUpDate(ctx, "daily_delegations", watchlist.ChainID, watchlist.ValidatorAddress)
+			assert.Error(t, err)
+			assert.False(t, b.repos.Retention.HourlyPartitioned())
+
+			deleted, err := b.repos.Retention.PruneHourly(ctx, "hourly_delegations", watchlist.ChainID, watchlist.ValidatorAddress, tuesday, 1)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), deleted)
+			deleted, err = b.repos.Retention.PruneHourly(ctx, "collection_heartbeats", watchlist.ChainID, watchlist.ValidatorAddress, tuesday, 1)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), deleted)
+
+			hourly, _, err := b.repos.Delegations.HourlyDelegations(ctx, DelegationQuery{ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, Ascending: true, Limit: 10})
+			require.NoError(t, err)
+			var kept []string
+			for _, h := range hourly {
+				kept = append(kept, fmt.Sprintf("%s %s", h.DelegatorAddress, h.DelegationAmount))
+			}
+			assert.Equal(t, []string{"cosmos1alice 1200", "cosmos1bob 0", "cosmos1carol 300"}, kept)
+
+			for i, table := range []string{"hourly_delegations", "collection_heartbeats"} {
+				require.NoError(t, b.repos.Retention.SaveRetentionRun(ctx, &models.RetentionRun{
+					ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, Table: table,
+					Cutoff: tuesday, RowsDeleted: 2, StartedAt: tuesday.Add(time.Duration(i) * time.Minute),
+				}))
+			}
+			runs, total, err := b.repos.Retention.RetentionRuns(ctx, 1, 0)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), total)
+			require.Len(t, runs, 1)
+			assert.Equal(t, "collection_heartbeats", runs[0].Table)
+
+			// A pruned day can no longer be rebuilt, so it is left as it was
+			rows, err := b.repos.Aggregation.AggregateDay(ctx, aggregation(monday))
+			require.NoError(t, err)
+			assert.Zero(t, rows)
+			_, total, err = b.repos.Delegations.DailyDelegations(ctx, DelegationQuery{ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, Limit: 10})
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), total)
+
+			// Migrations: every migration of a migrated database is applied; memory storage has none
+			statuses, err := b.repos.Migrations.MigrationStatuses(ctx)
+			require.NoError(t, err)
+			for _, s := range statuses {
+				assert.True(t, s.Applied, "%04d_%s", s.Version, s.Name)
+			}
+			history, err := b.repos.Migrations.MigrationHistory(ctx, 2)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(history), 2)
+			assert.Equal(t, name == "gorm", len(statuses) > 0 && len(history) > 0)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"fmt"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/errors"
+)
+
+// resolves the chain for a validator, inferring it from the bech32 prefix when no chain is given
+func ResolveChain(chainID, validatorAddress string) (config.ChainConfig, error) {
+	if chainID == "" {
+		if chain, ok := config.ChainForValidator(validatorAddress); ok {
+			return chain, nil
+		}
+		chainID = config.DefaultChainID
+	}
+
+	chain, ok := config.GetChain(chainID)
+	if !ok {
+		return config.ChainConfig{}, errors.NewNotFoundError(fmt.Sprintf("chain %q", chainID), nil)
+	}
+
+	if validatorAddress != "" && !chain.OwnsValidator(validatorAddress) {
+		return config.ChainConfig{}, errors.NewBadRequestError(
+			fmt.Sprintf("validator %s does not belong to chain %s (expected prefix %s)",
+				validatorAddress, chain.ChainID, chain.ValidatorPrefix), nil)
+	}
+
+	return chain, nil
+}
@@ -3,20 +3,25 @@ package services
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"log"
 	"math"
 	"math/rand/v2"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"cosmos-tracker/config"
 	"cosmos-tracker/internal/dto"
 	"cosmos-tracker/internal/errors"
 	"cosmos-tracker/internal/models"
-	"cosmos-tracker/pkg/db"
-
-	"gorm.io/gorm"
+	"cosmos-tracker/internal/repository"
+	"cosmos-tracker/pkg/numeric"
 )
 
 // Configurable HTTP client with timeouts and connection pooling
@@ -39,6 +44,26 @@ const (
 	MaxRetryDelayMs  = 30000 // Maximum 30 second delay
 )
 
+// Page size requested from paginated staking endpoints
+const DelegationPageLimit = 500
+
+// How often the collector runs
+const CollectionInterval = 1 * time.Hour
+
+// collectionRun identifies a collection pass by the UTC start of its interval. Every row the run
+// writes carries that start as its timestamp, so a restarted run, or one started by a second
+// instance, in the same interval produces the same unique keys and writes nothing new.
+type collectionRun struct {
+	ID    string
+	Start time.Time
+}
+
+// returns the collection run a time falls in
+func collectionRunAt(t time.Time) collectionRun {
+	start := t.UTC().Truncate(CollectionInterval)
+	return collectionRun{ID: start.Format(time.RFC3339), Start: start}
+}
+
 // API response structure matching the actual Cosmos API format
 type DelegationResponse struct {
 	Delegations []struct {
@@ -52,192 +77,551 @@ type DelegationResponse struct {
 			Amount string `json:"amount"`
 		} `json:"balance"`
 	} `json:"delegation_responses"`
-	Pagination struct {
-		NextKey string `json:"next_key"`
-		Total   string `json:"total"`
-	} `json:"pagination,omitempty"`
+	Pagination LCDPagination `json:"pagination,omitempty"`
+}
+
+// pagination block returned by Cosmos SDK list queries
+type LCDPagination struct {
+	NextKey string `json:"next_key"`
+	Total   string `json:"total"`
+}
+
+// Header asking the LCD to answer queries at a specific block height
+const BlockHeightHeader = "x-cosmos-block-height"
+
+// API response structure for the latest block
+type LatestBlockResponse struct {
+	Block struct {
+		Header struct {
+			Height string    `json:"height"`
+			Time   time.Time `json:"time"`
+		} `json:"header"`
+	} `json:"block"`
 }
 
-// retrieves delegation information from the Cosmos API
-func FetchDelegationData() {
+// identifies the chain state a snapshot was taken at
+type blockRef struct {
+	Height int64
+	Time   time.Time
+}
+
+// Collector snapshots the delegations of every watchlist entry
+type Collector struct {
+	watchlist     repository.WatchlistRepository
+	snapshots     repository.SnapshotRepository
+	unbondings    repository.UnbondingRepository
+	redelegations repository.RedelegationRepository
+	validators    repository.ValidatorRepository
+}
+
+// creates a collector that reads the watchlist and writes everything it collects through the given repositories
+func NewCollector(repos repository.Repositories) *Collector {
+	return &Collector{
+		watchlist:     repos.Watchlist,
+		snapshots:     repos.Snapshots,
+		unbondings:    repos.Unbondings,
+		redelegations: repos.Redelegations,
+		validators:    repos.Validators,
+	}
+}
+
+// retrieves delegation information from the Cosmos API, stopping early if ctx is cancelled
+func (c *Collector) FetchDelegationData(ctx context.Context) {
 	// Get watchlist entries to monitor
-	watchlist, err := GetWatchlist()
+	watchlistItems, err := c.watchlist.ListWatchlist(ctx, "")
 	if err != nil {
 		log.Printf("❌ Failed to get watchlist: %v", err)
 		return
 	}
+	watchlist := make([]dto.WatchlistEntry, len(watchlistItems))
+	for i, item := range watchlistItems {
+		watchlist[i] = toWatchlistEntry(item)
+	}
 
 	if len(watchlist) == 0 {
 		log.Println("⚠️ Watchlist is empty. Add entries to start collecting delegation data.")
 		return
 	}
 
+	cfg := config.CollectorConfig()
+	workers := cfg.Concurrency
+	if workers > len(watchlist) {
+		workers = len(watchlist)
+	}
+
+	started := time.Now()
+	run := collectionRunAt(started)
+	log.Printf("🧵 Collecting run %s: %d watchlist entries with %d workers", run.ID, len(watchlist), workers)
+
 	// Track success and failure counts for metrics
-	successCount := 0
-	failureCount := 0
+	var successCount, failureCount atomic.Int64
+
+	// Feed entries to a bounded pool of workers
+	jobs := make(chan dto.WatchlistEntry)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range jobs {
+				if err := c.collectEntry(ctx, entry, run); err != nil {
+					log.Printf("❌ Collection failed for [%s] %s: %v", entry.ChainID, entry.ValidatorAddress, err)
+					failureCount.Add(1)
+					continue
+				}
+				successCount.Add(1)
+			}
+		}()
+	}
+
+	// Stop handing out entries once shutdown starts; in-flight entries drain
+	skipped := 0
+dispatch:
+	for i, entry := range watchlist {
+		select {
+		case jobs <- entry:
+		case <-ctx.Done():
+			skipped = len(watchlist) - i
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if skipped > 0 {
+		log.Printf("🛑 Collection interrupted by shutdown, %d entries not started", skipped)
+	}
+
+	// Log collection summary
+	elapsed := time.Since(started)
+	log.Printf("📊 Collection summary: %d successful, %d failed in %v",
+		successCount.Load(), failureCount.Load(), elapsed.Round(time.Millisecond))
+	if failureCount.Load() > 0 && successCount.Load() == 0 {
+		log.Println("⚠️ WARNING: All collection attempts failed. Check API connectivity.")
+	}
+	if elapsed > CollectionInterval/2 {
+		log.Printf("⚠️ WARNING: Collection took %v, more than half the %v interval. Consider raising COLLECTOR_CONCURRENCY.",
+			elapsed.Round(time.Second), CollectionInterval)
+	}
+}
+
+// fetches and stores the delegations of a single watchlist entry for a collection run
+func (c *Collector) collectEntry(ctx context.Context, entry dto.WatchlistEntry, run collectionRun) error {
+	log.Printf("🔍 Fetching delegations for [%s] %s -> %s", entry.ChainID, entry.ValidatorAddress, entry.ValidatorName)
+
+	chain, ok := config.GetChain(entry.ChainID)
+	if !ok {
+		return fmt.Errorf("unknown chain %q", entry.ChainID)
+	}
+
+	// Pin every page to the same block so the snapshot is consistent
+	block, err := fetchLatestBlock(ctx, chain)
+	if err != nil {
+		return fmt.Errorf("error fetching latest block: %w", err)
+	}
+
+	// Walk every page so large validators are fully collected
+	result, pages, err := fetchAllDelegations(ctx, chain, entry.ValidatorAddress, block.Height)
+	if err != nil {
+		return fmt.Errorf("error fetching delegation data: %w", err)
+	}
+
+	log.Printf("📄 Fetched %d delegations across %d page(s) for %s at height %d (reported total: %s)",
+		len(result.Delegations), pages, entry.ValidatorAddress, block.Height, result.Pagination.Total)
+
+	// Unbonding queue at the same height
+	unbondings, unbondingPages, err := fetchAllUnbondings(ctx, chain, entry, block.Height)
+	if err != nil {
+		return fmt.Errorf("error fetching unbonding delegations: %w", err)
+	}
+
+	log.Printf("📄 Fetched %d unbonding entries across %d page(s) for %s",
+		len(unbondings), unbondingPages, entry.ValidatorAddress)
+
+	// Validator-level state at the same height
+	validator, err := fetchValidatorSnapshot(ctx, chain, entry, block)
+	if err != nil {
+		return fmt.Errorf("error fetching validator state: %w", err)
+	}
+
+	// Let the write finish during shutdown instead of aborting mid-transaction
+	writeCtx, cancel := drainContext(ctx)
+	defer cancel()
+
+	// Snapshot rows, positions, stats and the heartbeat are written together
+	err = c.processEntryData(writeCtx, entry, result, entry.ValidatorAddress, block, run)
+	if stderrors.Is(err, repository.ErrRunExists) {
+		log.Printf("⏭️ Run %s already stored for [%s] %s, skipping", run.ID, entry.ChainID, entry.ValidatorAddress)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error processing delegation data: %w", err)
+	}
+
+	if err := c.storeUnbondings(writeCtx, entry, unbondings, block); err != nil {
+		return fmt.Errorf("error storing unbonding delegations: %w", err)
+	}
+
+	if err := c.storeValidatorSnapshot(writeCtx, entry, validator, run); err != nil {
+		return fmt.Errorf("error storing validator snapshot: %w", err)
+	}
+
+	// Tx search is optional on many nodes, so a failure here doesn't fail the snapshot
+	if err := c.collectRedelegations(ctx, chain, entry, block); err != nil {
+		log.Printf("⚠️ Redelegation collection failed for %s: %v", entry.ValidatorAddress, err)
+	}
 
-	// Process each watchlist entry
-	for _, entry := range watchlist {
-		log.Printf("🔍 Fetching delegations for %s -> %s", entry.ValidatorAddress, entry.ValidatorName)
+	log.Printf("✅ Delegation data successfully updated for %s -> %s",
+		entry.ValidatorAddress, entry.ValidatorName)
+	return nil
+}
+
+// fetches the chain's latest block height and time
+func fetchLatestBlock(ctx context.Context, chain config.ChainConfig) (blockRef, error) {
+	var latest LatestBlockResponse
+	if err := fetchChainJSON(ctx, chain, "/cosmos/base/tendermint/v1beta1/blocks/latest", 0, &latest); err != nil {
+		return blockRef{}, err
+	}
+
+	height, err := strconv.ParseInt(latest.Block.Header.Height, 10, 64)
+	if err != nil || height <= 0 {
+		return blockRef{}, fmt.Errorf("invalid latest block height %q", latest.Block.Header.Height)
+	}
+
+	return blockRef{Height: height, Time: latest.Block.Header.Time}, nil
+}
+
+// walks every page of a validator's delegations at a pinned height and merges them into one snapshot
+func fetchAllDelegations(ctx context.Context, chain config.ChainConfig, validatorAddress string, height int64) (DelegationResponse, int, error) {
+	path := fmt.Sprintf("/cosmos/staking/v1beta1/validators/%s/delegations", validatorAddress)
+
+	var snapshot DelegationResponse
+	seen := make(map[string]bool)
+
+	pages, total, err := walkPages(path, func(pagePath string) (LCDPagination, error) {
+		var page DelegationResponse
+		if err := fetchChainJSON(ctx, chain, pagePath, height, &page); err != nil {
+			return LCDPagination{}, err
+		}
 
-		// Updated URL to use Polkachu API endpoint
-		url := fmt.Sprintf("https://cosmos-api.polkachu.com/cosmos/staking/v1beta1/validators/%s/delegations",
-			entry.ValidatorAddress)
+		// Skip delegators already seen in case the set shifted between pages
+		for _, delegation := range page.Delegations {
+			delegator := delegation.Delegation.DelegatorAddress
+			if seen[delegator] {
+				continue
+			}
+			seen[delegator] = true
+			snapshot.Delegations = append(snapshot.Delegations, delegation)
+		}
+		return page.Pagination, nil
+	})
+	if err != nil {
+		return DelegationResponse{}, pages, err
+	}
 
-		// Use retry mechanism
-		resp, err := fetchWithAdvancedRetry(url, MaxRetries)
+	snapshot.Pagination.Total = total
+	return snapshot, pages, nil
+}
+
+// walks every page of a paginated LCD list; fetchPage decodes the page at pagePath
+// and returns its pagination block. Returns the page count and the reported total.
+func walkPages(path string, fetchPage func(pagePath string) (LCDPagination, error)) (int, string, error) {
+	nextKey := ""
+	total := ""
+	pages := 0
+
+	for {
+		params := url.Values{}
+		params.Set("pagination.limit", strconv.Itoa(DelegationPageLimit))
+		if nextKey == "" {
+			// Only the first page needs the total count
+			params.Set("pagination.count_total", "true")
+		} else {
+			params.Set("pagination.key", nextKey)
+		}
+
+		pagination, err := fetchPage(path + "?" + params.Encode())
 		if err != nil {
-			log.Printf("❌ Error fetching delegation data after %d retries: %v", MaxRetries, err)
-			failureCount++
-			continue
+			return pages, total, fmt.Errorf("page %d: %w", pages+1, err)
 		}
-		defer resp.Body.Close()
+		pages++
 
-		// Parse API response
-		var result DelegationResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			log.Printf("❌ Error decoding response: %v", err)
-			failureCount++
-			continue
+		if pages == 1 {
+			total = pagination.Total
 		}
 
-		// Process data within a transaction for consistency
-		if err := processEntryData(entry, result, entry.ValidatorAddress); err != nil {
-			log.Printf("❌ Error processing delegation data: %v", err)
-			failureCount++
-			continue
+		if pagination.NextKey == "" {
+			return pages, total, nil
+		}
+		if pagination.NextKey == nextKey {
+			return pages, total, fmt.Errorf("pagination did not advance past key %q", nextKey)
 		}
+		nextKey = pagination.NextKey
+	}
+}
 
-		successCount++
-		log.Printf("✅ Delegation data successfully updated for %s -> %s",
-			entry.ValidatorAddress, entry.ValidatorName)
+// fetches a path from the chain's healthiest LCD endpoint and decodes the JSON body;
+// a non-zero height pins the query to that block
+func fetchChainJSON(ctx context.Context, chain config.ChainConfig, path string, height int64, out interface{}) error {
+	header := http.Header{}
+	if height > 0 {
+		header.Set(BlockHeightHeader, strconv.FormatInt(height, 10))
 	}
 
-	// Log collection summary
-	log.Printf("📊 Collection summary: %d successful, %d failed", successCount, failureCount)
-	if failureCount > 0 && successCount == 0 {
-		log.Println("⚠️ WARNING: All collection attempts failed. Check API connectivity.")
+	resp, err := fetchWithAdvancedRetry(ctx, endpointPoolFor(chain), path, header, MaxRetries)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
 	}
+
+	return nil
 }
 
-// saves delegation data from API response to database
-func processEntryData(entry dto.WatchlistEntry, result DelegationResponse, validatorAddress string) error {
-	return db.WithTransaction(func(tx *gorm.DB) error {
-		// Process each delegation record
-		for _, delegation := range result.Delegations {
-			// Extract delegator address from the nested delegation object
-			delegatorAddress := delegation.Delegation.DelegatorAddress
+// saves delegation data from API response using one state lookup and a single snapshot write;
+// returns repository.ErrRunExists, having written nothing, when the run is already stored
+func (c *Collector) processEntryData(ctx context.Context, entry dto.WatchlistEntry, result DelegationResponse, validatorAddress string, block blockRef, run collectionRun) error {
+	// Find associated watchlist entry for foreign key, once per validator
+	watchlistItem, err := c.watchlist.FindWatchlist(ctx, entry.ChainID, validatorAddress)
+	if stderrors.Is(err, repository.ErrNotFound) {
+		log.Printf("⚠️ Warning: No watchlist entry found for [%s] %s", entry.ChainID, validatorAddress)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 
-			// Skip if zero amount to avoid noise in the data
-			delegationAmount, err := strconv.ParseInt(delegation.Balance.Amount, 10, 64)
-			if err != nil {
-				log.Printf("❌ Error parsing delegation amount '%s': %v", delegation.Balance.Amount, err)
-				continue
-			}
+	// Load every delegator's current position in a single query
+	previous, err := c.snapshots.CurrentPositions(ctx, entry.ChainID, validatorAddress)
+	if err != nil {
+		return err
+	}
 
-			// Parse shares for additional data
-			var sharesFloat float64
-			if delegation.Delegation.Shares != "" {
-				sharesFloat, err = strconv.ParseFloat(delegation.Delegation.Shares, 64)
-				if err != nil {
-					log.Printf("⚠️ Error parsing shares value '%s': %v", delegation.Delegation.Shares, err)
-					// Continue anyway since this is optional data
-				}
-			}
+	storage := config.CollectorConfig()
+	snapshots := make([]models.HourlyDelegation, 0, len(result.Delegations))
+	stats := newFlowTotals(watchlistItem, block.Height)
 
-			// Fetch the last recorded amount to calculate the change
-			var lastRecord models.HourlyDelegation
-			if err := tx.Where("validator_address = ? AND delegator_address = ?",
-				validatorAddress, delegatorAddress).
-				Order("timestamp DESC").
-				First(&lastRecord).Error; err != nil && err.Error() != "record not found" {
-				return err
-			}
+	// Process each delegation record
+	for _, delegation := range result.Delegations {
+		// Extract delegator address from the nested delegation object
+		delegatorAddress := delegation.Delegation.DelegatorAddress
 
-			// Calculate change amount
-			changeAmount := delegationAmount
-			if lastRecord.ID != 0 {
-				changeAmount = delegationAmount - lastRecord.DelegationAmount
-			}
+		// A delegator skipped here would be recorded as exited, so a bad amount fails the whole run
+		delegationAmount, err := numeric.ParseInt(delegation.Balance.Amount)
+		if err != nil {
+			return fmt.Errorf("error parsing delegation amount '%s' of %s: %w", delegation.Balance.Amount, delegatorAddress, err)
+		}
 
-			// Find associated watchlist entry for foreign key
-			var watchlistItem models.Watchlist
-			if err := tx.Where("validator_address = ?",
-				validatorAddress).First(&watchlistItem).Error; err != nil {
-				log.Printf("⚠️ Warning: No watchlist entry found for %s -> %s",
-					validatorAddress, delegatorAddress)
-				continue
+		// Parse shares for additional data
+		var shares numeric.Dec
+		if delegation.Delegation.Shares != "" {
+			shares, err = numeric.ParseDec(delegation.Delegation.Shares)
+			if err != nil {
+				log.Printf("⚠️ Error parsing shares value '%s': %v", delegation.Delegation.Shares, err)
+				// Continue anyway since this is optional data
 			}
+		}
 
-			// Save the new delegation snapshot with watchlist reference
-			entry := models.HourlyDelegation{
-				WatchlistID:      watchlistItem.ID,
-				ValidatorAddress: validatorAddress,
-				DelegatorAddress: delegatorAddress,
-				DelegationAmount: delegationAmount,
-				ChangeAmount:     changeAmount,
-				Shares:           sharesFloat, // Store the parsed shares value
-				Timestamp:        time.Now(),
-			}
+		// Calculate change amount against the last recorded amount
+		lastRecord, known := previous[delegatorAddress]
+		delete(previous, delegatorAddress) // whatever remains afterwards has left the validator
 
-			if err := tx.Create(&entry).Error; err != nil {
-				return err
-			}
+		changeAmount := delegationAmount
+		if known {
+			changeAmount = delegationAmount.Sub(lastRecord.DelegationAmount)
+		}
+		stats.addHolding(delegationAmount, changeAmount, !known || lastRecord.Exited)
 
-			// Log significant delegation changes for monitoring
-			if lastRecord.ID != 0 && math.Abs(float64(changeAmount)) > float64(delegationAmount)*0.05 {
-				log.Printf("📈 Significant delegation change: %s -> %s changed by %d (%.2f%%)",
-					validatorAddress, delegatorAddress, changeAmount,
-					float64(changeAmount)*100/float64(lastRecord.DelegationAmount))
-			}
+		// In change-only mode an unchanged position is carried forward from its last row instead
+		if storage.ChangesOnly() && known && !lastRecord.Exited &&
+			changeAmount.IsZero() && shares.Cmp(lastRecord.Shares) == 0 {
+			continue
 		}
-		return nil
+
+		snapshots = append(snapshots, models.HourlyDelegation{
+			WatchlistID:      watchlistItem.ID,
+			ChainID:          entry.ChainID,
+			ValidatorAddress: validatorAddress,
+			DelegatorAddress: delegatorAddress,
+			RunID:            run.ID,
+			DelegationAmount: delegationAmount,
+			ChangeAmount:     changeAmount,
+			Shares:           shares, // Store the parsed shares value
+			Returned:         lastRecord.Exited,
+			BlockHeight:      block.Height,
+			BlockTime:        block.Time,
+			Timestamp:        run.Start,
+		})
+
+		// Log significant delegation changes for monitoring
+		if known && !lastRecord.Exited && changeAmount.Abs().MulInt64(20).Cmp(delegationAmount) > 0 {
+			log.Printf("📈 Significant delegation change: %s -> %s changed by %s (%.2f%%)",
+				validatorAddress, delegatorAddress, changeAmount,
+				changeAmount.Float64()*100/lastRecord.DelegationAmount.Float64())
+		}
+		if lastRecord.Exited {
+			log.Printf("↩️ Delegator returned: %s -> %s with %s", validatorAddress, delegatorAddress, delegationAmount)
+		}
+	}
+
+	// Anyone who held stake last time but is missing now has fully undelegated
+	exited := make([]string, 0, len(previous))
+	for delegatorAddress, lastRecord := range previous {
+		if !lastRecord.Exited {
+			exited = append(exited, delegatorAddress)
+		}
+	}
+	sort.Strings(exited)
+
+	for _, delegatorAddress := range exited {
+		lastRecord := previous[delegatorAddress]
+		stats.addExit(lastRecord.DelegationAmount)
+		snapshots = append(snapshots, models.HourlyDelegation{
+			WatchlistID:      watchlistItem.ID,
+			ChainID:          entry.ChainID,
+			ValidatorAddress: validatorAddress,
+			DelegatorAddress: delegatorAddress,
+			RunID:            run.ID,
+			DelegationAmount: numeric.NewInt(0),
+			ChangeAmount:     lastRecord.DelegationAmount.Neg(),
+			Exited:           true,
+			BlockHeight:      block.Height,
+			BlockTime:        block.Time,
+			Timestamp:        run.Start,
+		})
+
+		log.Printf("🚪 Delegator exited: %s -> %s withdrew %s", validatorAddress, delegatorAddress, lastRecord.DelegationAmount)
+	}
+
+	// The heartbeat records that this run covered the validator, whether or not any row was written
+	return c.snapshots.SaveSnapshot(ctx, repository.Snapshot{
+		Delegations: snapshots,
+		Stats:       models.HourlyValidatorStats{ValidatorStats: stats.ValidatorStats, Timestamp: run.Start},
+		Heartbeat: models.CollectionHeartbeat{
+			WatchlistID:      watchlistItem.ID,
+			ChainID:          entry.ChainID,
+			ValidatorAddress: validatorAddress,
+			RunID:            run.ID,
+			Timestamp:        run.Start,
+			BlockHeight:      block.Height,
+			BlockTime:        block.Time,
+			StorageMode:      storage.StorageMode,
+			Delegators:       int64(len(result.Delegations)),
+			RowsWritten:      int64(len(snapshots)),
+		},
 	})
 }
 
-// implements exponential backoff with jitter for API resilience
-func fetchWithAdvancedRetry(url string, maxRetries int) (*http.Response, error) {
+// implements endpoint failover plus exponential backoff with jitter for API resilience
+func fetchWithAdvancedRetry(ctx context.Context, pool *endpointPool, path string, header http.Header, maxRetries int) (*http.Response, error) {
 	var resp *http.Response
 	var err error
 
+	// Endpoints already tried in the current round; backoff only once all have failed
+	tried := make(map[string]bool)
+	round := 0
+
 	for attempt := 0; attempt < maxRetries; attempt++ {
+		endpoint := pool.next(tried)
+		if endpoint == "" {
+			return nil, errors.NewInternalServerError(fmt.Sprintf("No LCD endpoints configured for %s", pool.chainID), nil)
+		}
+		tried[endpoint] = true
+
+		// All workers hitting the same host share one limiter
+		limiter := limiterFor(endpoint)
+		if waitErr := limiter.Wait(ctx); waitErr != nil {
+			return nil, waitErr
+		}
+
 		// Try the request
-		resp, err = httpClient.Get(url)
+		req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+path, nil)
+		if reqErr != nil {
+			return nil, errors.NewBadRequestError("Invalid API request", reqErr)
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		started := time.Now()
+		resp, err = httpClient.Do(req)
+		latency := time.Since(started)
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
 
 		// Success case
 		if err == nil && resp.StatusCode == http.StatusOK {
+			pool.record(endpoint, latency, outcomeSuccess, nil)
 			return resp, nil
 		}
 
+		if err != nil {
+			pool.record(endpoint, latency, outcomeError, err)
+			log.Printf("⚠️ Request to %s failed: %v", endpoint, err)
+		}
+
 		// Handle specific status codes
 		if resp != nil {
 			switch resp.StatusCode {
 			case http.StatusTooManyRequests, http.StatusServiceUnavailable:
-				log.Printf("⚠️ Rate limited (%d). Backing off...", resp.StatusCode)
-				// Check for Retry-After header
-				if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
-					if seconds, parseErr := strconv.Atoi(retryAfter); parseErr == nil {
-						time.Sleep(time.Duration(seconds) * time.Second)
-						resp.Body.Close()
-						continue
-					}
+				log.Printf("⚠️ Rate limited (%d) by %s. Backing off...", resp.StatusCode, endpoint)
+				outcome := outcomeThrottled
+				if resp.StatusCode == http.StatusServiceUnavailable {
+					outcome = outcomeError
+				}
+				pool.record(endpoint, latency, outcome, fmt.Errorf("HTTP %d", resp.StatusCode))
+
+				// Honour Retry-After for every worker using this host
+				if until, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+					limiter.PauseUntil(until)
+					resp.Body.Close()
+					continue
 				}
 			case http.StatusBadRequest, http.StatusNotFound:
-				// Don't retry for client errors
+				// A node behind or pruned below a pinned height rejects it with 400; another may have it
+				if height := header.Get(BlockHeightHeader); height != "" && resp.StatusCode == http.StatusBadRequest && len(tried) < pool.size() {
+					pool.record(endpoint, latency, outcomeError, fmt.Errorf("HTTP 400 at height %s", height))
+					resp.Body.Close()
+					log.Printf("🔀 %s rejected height %s, failing over (attempt %d/%d)", endpoint, height, attempt+1, maxRetries)
+					continue
+				}
+
+				// The endpoint answered fine; don't retry client errors
+				pool.record(endpoint, latency, outcomeSuccess, nil)
 				errMsg := fmt.Sprintf("API client error: %d", resp.StatusCode)
 				resp.Body.Close()
 				return nil, errors.NewBadRequestError(errMsg, nil)
+			default:
+				pool.record(endpoint, latency, outcomeError, fmt.Errorf("HTTP %d", resp.StatusCode))
 			}
 			resp.Body.Close()
 		}
 
+		// Fail over straight away while other endpoints remain untried
+		if len(tried) < pool.size() {
+			log.Printf("🔀 Failing over from %s (attempt %d/%d)", endpoint, attempt+1, maxRetries)
+			continue
+		}
+
 		// Calculate backoff with jitter for distributed clients
-		baseDelay := math.Min(float64(BaseRetryDelayMs)*math.Pow(2, float64(attempt)), float64(MaxRetryDelayMs))
+		baseDelay := math.Min(float64(BaseRetryDelayMs)*math.Pow(2, float64(round)), float64(MaxRetryDelayMs))
 		jitter := (baseDelay * 0.2) * (0.5 + rand.Float64()) // Add 0-20% jitter
 		backoffTime := time.Duration(baseDelay+jitter) * time.Millisecond
 
 		log.Printf("🔄 Retrying API call in %v (attempt %d/%d)", backoffTime, attempt+1, maxRetries)
-		time.Sleep(backoffTime)
+		if !sleepContext(ctx, backoffTime) {
+			return nil, ctx.Err()
+		}
+
+		// Start a new round over every endpoint
+		tried = make(map[string]bool)
+		round++
 	}
 
 	if err != nil {
@@ -247,39 +631,110 @@ func fetchWithAdvancedRetry(url string, maxRetries int) (*http.Response, error)
 	return nil, errors.NewInternalServerError("Maximum retries exceeded", nil)
 }
 
-// runs the delegation collector on a schedule
-func StartCollector() {
+// parses a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return now.Add(time.Duration(seconds) * time.Second), true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return at, true
+	}
+	return time.Time{}, false
+}
+
+// runs the delegation collector on a schedule until ctx is cancelled
+func (c *Collector) Start(ctx context.Context) {
 	// Run immediately at startup
 	log.Println("🚀 Initial data collection starting...")
-	FetchDelegationData()
+	c.FetchDelegationData(ctx)
 
 	// Then run hourly
-	ticker := time.NewTicker(1 * time.Hour)
+	ticker := time.NewTicker(CollectionInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		log.Println("⏳ Running scheduled collection...")
-		FetchDelegationData()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Collector stopped")
+			return
+		case <-ticker.C:
+			log.Println("⏳ Running scheduled collection...")
+			c.FetchDelegationData(ctx)
+		}
+	}
+}
+
+// Overall deadline for probing every LCD endpoint in a health check
+const ChainHealthTimeout = 2 * time.Second
+
+// performs a simple check to verify the collector can reach every registered chain
+func IsHealthy(ctx context.Context) bool {
+	for _, healthy := range ChainHealth(ctx) {
+		if !healthy {
+			return false
+		}
 	}
+	return true
+}
+
+// reports LCD reachability for each registered chain; a chain is healthy if any endpoint answers.
+// Every endpoint is probed at once under a shared deadline, so the check takes at most
+// ChainHealthTimeout however many endpoints are configured.
+func ChainHealth(ctx context.Context) map[string]bool {
+	return probeChains(ctx, config.Chains())
 }
 
-// performs a simple check to verify the collector service is functional
-func IsHealthy() bool {
-	// Check if we can connect to the Cosmos API
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// probes every endpoint of the given chains concurrently
+func probeChains(ctx context.Context, chains []config.ChainConfig) map[string]bool {
+	ctx, cancel := context.WithTimeout(ctx, ChainHealthTimeout)
 	defer cancel()
 
-	// Updated URL for health check to match new API provider
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://cosmos-api.polkachu.com/cosmos/base/tendermint/v1beta1/node_info", nil)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	status := make(map[string]bool)
+	for _, chain := range chains {
+		status[chain.ChainID] = false
+		pool := endpointPoolFor(chain)
+		for _, endpoint := range chain.LCDEndpoints {
+			// Probing every endpoint lets failed ones earn their score back
+			wg.Add(1)
+			go func(chainID, endpoint string) {
+				defer wg.Done()
+				if isEndpointHealthy(ctx, pool, endpoint) {
+					mu.Lock()
+					status[chainID] = true
+					mu.Unlock()
+				}
+			}(chain.ChainID, endpoint)
+		}
+	}
+	wg.Wait()
+	return status
+}
+
+// checks whether a single LCD endpoint answers node_info, recording the result in its pool
+func isEndpointHealthy(ctx context.Context, pool *endpointPool, endpoint string) bool {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"/cosmos/base/tendermint/v1beta1/node_info", nil)
 	if err != nil {
 		return false
 	}
 
+	started := time.Now()
 	resp, err := httpClient.Do(req)
 	if err != nil {
+		pool.record(endpoint, time.Since(started), outcomeError, err)
 		return false
 	}
 	defer resp.Body.Close()
 
-	return resp.StatusCode == http.StatusOK
+	if resp.StatusCode != http.StatusOK {
+		pool.record(endpoint, time.Since(started), outcomeError, fmt.Errorf("node_info returned HTTP %d", resp.StatusCode))
+		return false
+	}
+
+	pool.record(endpoint, time.Since(started), outcomeSuccess, nil)
+	return true
 }
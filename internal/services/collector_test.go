@@ -0,0 +1,482 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/internal/repository"
+	"cosmos-tracker/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+// points db.DB at a fresh in-memory database for the duration of a test or benchmark
+func useTestDB(tb testing.TB) dto.WatchlistEntry {
+	tb.Helper()
+
+	// The same driver selection and migrations as a deployment, on a throwaway database
+	tb.Setenv("DB_DRIVER", db.DriverSQLite)
+	tb.Setenv("SQLITE_PATH", ":memory:")
+	database, err := db.Open()
+	require.NoError(tb, err)
+	database.Logger = logger.Discard
+
+	previous := db.DB
+	db.DB = database
+	tb.Cleanup(func() {
+		db.DB = previous
+		if sqlDB, err := database.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	_, err = db.MigrateUp(context.Background(), 0)
+	require.NoError(tb, err)
+
+	watchlist := models.Watchlist{ChainID: "cosmoshub-4", ValidatorAddress: "cosmosvaloper1watched"}
+	require.NoError(tb, database.Create(&watchlist).Error)
+
+	return dto.WatchlistEntry{ID: int(watchlist.ID), ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress}
+}
+
+// builds a delegations response with one entry per address/amount pair
+func delegationPage(tb testing.TB, amounts map[string]string) DelegationResponse {
+	type item struct {
+		Delegation map[string]string `json:"delegation"`
+		Balance    map[string]string `json:"balance"`
+	}
+	items := make([]item, 0, len(amounts))
+	for delegator, amount := range amounts {
+		items = append(items, item{
+			Delegation: map[string]string{"delegator_address": delegator, "shares": amount + ".000000000000000000"},
+			Balance:    map[string]string{"denom": "uatom", "amount": amount},
+		})
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"delegation_responses": items})
+	require.NoError(tb, err)
+
+	var page DelegationResponse
+	require.NoError(tb, json.Unmarshal(data, &page))
+	return page
+}
+
+// builds the collector and delegation service on the test database
+func testServices() (*Collector, *DelegationService) {
+	repos := repository.NewGorm(db.DB)
+	return NewCollector(repos), NewDelegationService(repos.Delegations)
+}
+
+// returns a distinct collection run per height, starting now so successive runs stay in order
+func testRun(height int64) collectionRun {
+	return collectionRun{ID: fmt.Sprint(height), Start: time.Now()}
+}
+
+// returns an aggregator working on the test database
+func testAggregator() *Aggregator {
+	return NewAggregator(repository.NewGorm(db.DB))
+}
+
+// returns the latest snapshot per delegator after a run
+func latestByDelegator(t *testing.T, entry dto.WatchlistEntry) map[string]models.HourlyDelegation {
+	latest, err := repository.LatestSnapshots(db.DB, entry.ChainID, entry.ValidatorAddress, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	return latest
+}
+
+func TestProcessEntryDataRecordsExitsAndReturns(t *testing.T) {
+	entry := useTestDB(t)
+	ctx := context.Background()
+	collector, _ := testServices()
+
+	run := func(height int64, amounts map[string]string) map[string]models.HourlyDelegation {
+		require.NoError(t, collector.processEntryData(ctx, entry, delegationPage(t, amounts), entry.ValidatorAddress, blockRef{Height: height, Time: time.Now()}, testRun(height)))
+		return latestByDelegator(t, entry)
+	}
+
+	run(100, map[string]string{"cosmos1alice": "1000", "cosmos1bob": "500"})
+
+	// Bob fully undelegates
+	latest := run(101, map[string]string{"cosmos1alice": "1200"})
+	assert.Equal(t, "200", latest["cosmos1alice"].ChangeAmount.String())
+	assert.True(t, latest["cosmos1bob"].Exited)
+	assert.Equal(t, "0", latest["cosmos1bob"].DelegationAmount.String())
+	assert.Equal(t, "-500", latest["cosmos1bob"].ChangeAmount.String())
+
+	// An exited delegator is not written again while absent
+	run(102, map[string]string{"cosmos1alice": "1200"})
+	var bobRows int64
+	db.DB.Model(&models.HourlyDelegation{}).Where("delegator_address = ?", "cosmos1bob").Count(&bobRows)
+	assert.Equal(t, int64(2), bobRows)
+
+	// Bob comes back
+	latest = run(103, map[string]string{"cosmos1alice": "1200", "cosmos1bob": "300"})
+	assert.True(t, latest["cosmos1bob"].Returned)
+	assert.False(t, latest["cosmos1bob"].Exited)
+	assert.Equal(t, "300", latest["cosmos1bob"].ChangeAmount.String())
+	assert.False(t, latest["cosmos1alice"].Returned)
+}
+
+func TestProcessEntryDataRejectsUnparsableAmounts(t *testing.T) {
+	entry := useTestDB(t)
+	ctx := context.Background()
+	collector, delegations := testServices()
+
+	page := func(amounts map[string]string) DelegationResponse { return delegationPage(t, amounts) }
+	require.NoError(t, collector.processEntryData(ctx, entry, page(map[string]string{"cosmos1alice": "1000", "cosmos1bob": "500"}),
+		entry.ValidatorAddress, blockRef{Height: 100, Time: time.Now()}, testRun(100)))
+
+	// Bob is still staked, so the run fails instead of recording him as exited
+	err := collector.processEntryData(ctx, entry, page(map[string]string{"cosmos1alice": "1000", "cosmos1bob": "5x0"}),
+		entry.ValidatorAddress, blockRef{Height: 101, Time: time.Now()}, testRun(101))
+	assert.ErrorContains(t, err, "error parsing delegation amount '5x0' of cosmos1bob")
+
+	var rows int64
+	require.NoError(t, db.DB.Model(&models.HourlyDelegation{}).Where("block_height = ?", 101).Count(&rows).Error)
+	assert.Zero(t, rows)
+	bob, err := delegations.FetchCurrentDelegation(ctx, entry.ChainID, entry.ValidatorAddress, "cosmos1bob")
+	require.NoError(t, err)
+	assert.Equal(t, "500", bob.DelegationAmount.String())
+
+	// The next good run sees no exit or return
+	require.NoError(t, collector.processEntryData(ctx, entry, page(map[string]string{"cosmos1alice": "1000", "cosmos1bob": "600"}),
+		entry.ValidatorAddress, blockRef{Height: 102, Time: time.Now()}, testRun(102)))
+	latest := latestByDelegator(t, entry)
+	assert.False(t, latest["cosmos1bob"].Returned)
+	assert.Equal(t, "100", latest["cosmos1bob"].ChangeAmount.String())
+}
+
+func TestProcessEntryDataMaintainsCurrentDelegations(t *testing.T) {
+	entry := useTestDB(t)
+	ctx := context.Background()
+	collector, delegations := testServices()
+
+	run := func(height int64, amounts map[string]string) {
+		require.NoError(t, collector.processEntryData(ctx, entry, delegationPage(t, amounts), entry.ValidatorAddress, blockRef{Height: height, Time: time.Now()}, testRun(height)))
+	}
+
+	run(100, map[string]string{"cosmos1alice": "1000", "cosmos1bob": "500", "cosmos1carol": "2000"})
+	run(101, map[string]string{"cosmos1alice": "1200", "cosmos1carol": "2000"})
+
+	current, err := delegations.FetchCurrentDelegatorsWithPagination(ctx, entry.ChainID, entry.ValidatorAddress, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), current.TotalDelegators)
+	assert.Equal(t, "3200", current.TotalStake.String())
+	require.Len(t, current.Delegators, 1)
+	assert.Equal(t, "cosmos1carol", current.Delegators[0].DelegatorAddress)
+	assert.Equal(t, int64(101), current.Delegators[0].BlockHeight)
+
+	// Bob's exit is kept in the table but hidden from current positions
+	_, err = delegations.FetchCurrentDelegation(ctx, entry.ChainID, entry.ValidatorAddress, "cosmos1bob")
+	assert.Error(t, err)
+
+	var rows int64
+	db.DB.Model(&models.CurrentDelegation{}).Count(&rows)
+	assert.Equal(t, int64(3), rows)
+}
+
+func TestRepeatedRunsAndAggregationsWriteNothingNew(t *testing.T) {
+	entry := useTestDB(t)
+	ctx := context.Background()
+	collector, _ := testServices()
+	watchlist := models.Watchlist{ID: uint(entry.ID), ChainID: entry.ChainID, ValidatorAddress: entry.ValidatorAddress}
+	run := collectionRunAt(time.Now())
+
+	page := delegationPage(t, map[string]string{"cosmos1alice": "1000", "cosmos1bob": "500"})
+	require.NoError(t, collector.processEntryData(ctx, entry, page, entry.ValidatorAddress, blockRef{Height: 100, Time: time.Now()}, run))
+
+	// A restarted run in the same interval sees a later block but is refused as a whole
+	page = delegationPage(t, map[string]string{"cosmos1alice": "1500"})
+	err := collector.processEntryData(ctx, entry, page, entry.ValidatorAddress, blockRef{Height: 105, Time: time.Now()}, run)
+	assert.ErrorIs(t, err, repository.ErrRunExists)
+
+	counts := func() (hourly, heartbeats, stats int64) {
+		db.DB.Model(&models.HourlyDelegation{}).Count(&hourly)
+		db.DB.Model(&models.CollectionHeartbeat{}).Count(&heartbeats)
+		db.DB.Model(&models.HourlyValidatorStats{}).Count(&stats)
+		return
+	}
+	hourly, heartbeats, stats := counts()
+	assert.Equal(t, []int64{2, 1, 1}, []int64{hourly, heartbeats, stats})
+	assert.Equal(t, "1000", latestByDelegator(t, entry)["cosmos1alice"].DelegationAmount.String())
+
+	// Aggregating a day again replaces its rows, and the table refuses a second row per delegator and day
+	today := startOfDay(time.Now())
+	for i := 0; i < 2; i++ {
+		_, err := testAggregator().aggregateDay(ctx, watchlist, today)
+		require.NoError(t, err)
+	}
+	var daily []models.DailyDelegation
+	require.NoError(t, db.DB.Find(&daily).Error)
+	require.Len(t, daily, 2)
+
+	duplicate := daily[0]
+	duplicate.ID = 0
+	assert.Error(t, db.DB.Create(&duplicate).Error)
+}
+
+func TestValidatorStatsPerRunAndDay(t *testing.T) {
+	entry := useTestDB(t)
+	ctx := context.Background()
+	collector, _ := testServices()
+	watchlist := models.Watchlist{ID: uint(entry.ID), ChainID: entry.ChainID, ValidatorAddress: entry.ValidatorAddress}
+
+	run := func(height int64, amounts map[string]string) {
+		require.NoError(t, collector.processEntryData(ctx, entry, delegationPage(t, amounts), entry.ValidatorAddress, blockRef{Height: height, Time: time.Now()}, testRun(height)))
+	}
+
+	run(100, map[string]string{"cosmos1alice": "1000", "cosmos1bob": "500"})
+	// Alice adds 200, Bob leaves, Carol joins with 50
+	run(101, map[string]string{"cosmos1alice": "1200", "cosmos1carol": "50"})
+
+	validators := NewValidatorService(repository.NewGorm(db.DB).Validators)
+	hourly, total, err := validators.FetchHourlyValidatorStatsWithPagination(ctx, entry.ChainID, entry.ValidatorAddress, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+	latest := hourly[0]
+	assert.Equal(t, int64(101), latest.BlockHeight)
+	assert.Equal(t, "1250", latest.TotalDelegated.String())
+	assert.Equal(t, int64(2), latest.DelegatorCount)
+	assert.Equal(t, int64(1), latest.NewDelegators)
+	assert.Equal(t, int64(1), latest.ExitedDelegators)
+	assert.Equal(t, "250", latest.Inflow.String())
+	assert.Equal(t, "500", latest.Outflow.String())
+	assert.Equal(t, "-250", latest.NetFlow.String())
+
+	// The day sums both runs' flows and closes on the second run's totals
+	_, err = testAggregator().aggregateDay(ctx, watchlist, startOfDay(time.Now()))
+	require.NoError(t, err)
+
+	daily, _, err := validators.FetchDailyValidatorStatsWithPagination(ctx, entry.ChainID, entry.ValidatorAddress, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, daily, 1)
+	assert.Equal(t, "1250", daily[0].TotalDelegated.String())
+	assert.Equal(t, int64(3), daily[0].NewDelegators)
+	assert.Equal(t, "1750", daily[0].Inflow.String())
+	assert.Equal(t, "1250", daily[0].NetFlow.String())
+}
+
+// measures one collection write for a validator with existing history. The 5k delegators keep it quick
+// enough for CI; set BENCH_DELEGATORS to measure a larger validator.
+func BenchmarkProcessEntryData(b *testing.B) {
+	delegators := 5000
+	if n, err := strconv.Atoi(os.Getenv("BENCH_DELEGATORS")); err == nil && n > 0 {
+		delegators = n
+	}
+
+	// Keep per-delegator change logging out of the measurement
+	output := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(output)
+
+	entry := useTestDB(b)
+	ctx := context.Background()
+	collector, _ := testServices()
+
+	pages := [2]DelegationResponse{}
+	for i := range pages {
+		amounts := make(map[string]string, delegators)
+		for d := 0; d < delegators; d++ {
+			amounts[fmt.Sprintf("cosmos1delegator%06d", d)] = fmt.Sprintf("%d", 1000000+d*(i+1))
+		}
+		pages[i] = delegationPage(b, amounts)
+	}
+
+	// Seed the previous snapshot so every delegator has state to diff against
+	require.NoError(b, collector.processEntryData(ctx, entry, pages[0], entry.ValidatorAddress, blockRef{Height: 1, Time: time.Now()}, testRun(1)))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		block := blockRef{Height: int64(i + 2), Time: time.Now()}
+		if err := collector.processEntryData(ctx, entry, pages[(i+1)%2], entry.ValidatorAddress, block, testRun(block.Height)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestChangeOnlyStorageCarriesPositionsForward(t *testing.T) {
+	t.Setenv("SNAPSHOT_STORAGE_MODE", "changes")
+	entry := useTestDB(t)
+	ctx := context.Background()
+	collector, delegations := testServices()
+	watchlist := models.Watchlist{ID: uint(entry.ID), ChainID: entry.ChainID, ValidatorAddress: entry.ValidatorAddress}
+	today := startOfDay(time.Now())
+
+	run := func(height int64, amounts map[string]string) {
+		require.NoError(t, collector.processEntryData(ctx, entry, delegationPage(t, amounts), entry.ValidatorAddress, blockRef{Height: height, Time: time.Now()}, testRun(height)))
+	}
+
+	// The first run is moved to yesterday so today starts from carried positions
+	run(100, map[string]string{"cosmos1alice": "1000", "cosmos1bob": "500"})
+	yesterday := today.AddDate(0, 0, -1).Add(12 * time.Hour)
+	require.NoError(t, db.DB.Model(&models.HourlyDelegation{}).Where("block_height = ?", 100).Update("timestamp", yesterday).Error)
+	require.NoError(t, db.DB.Model(&models.CollectionHeartbeat{}).Where("block_height = ?", 100).Update("timestamp", yesterday).Error)
+
+	run(101, map[string]string{"cosmos1alice": "1000", "cosmos1bob": "500"})
+	run(102, map[string]string{"cosmos1alice": "1000", "cosmos1bob": "700"})
+
+	// Only changes are stored, but every run leaves a heartbeat
+	var heartbeats []models.CollectionHeartbeat
+	require.NoError(t, db.DB.Order("id").Find(&heartbeats).Error)
+	require.Len(t, heartbeats, 3)
+	for i, written := range []int64{2, 0, 1} {
+		assert.Equal(t, written, heartbeats[i].RowsWritten)
+		assert.Equal(t, int64(2), heartbeats[i].Delegators)
+	}
+
+	_, stored, err := delegations.FetchHourlyDelegationsWithPagination(ctx, entry.ChainID, entry.ValidatorAddress, 1, 10, dto.DelegationFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stored)
+
+	// The dense series has both delegators at every run
+	dense, total, err := delegations.FetchHourlyDelegationsWithPagination(ctx, entry.ChainID, entry.ValidatorAddress, 1, 2, dto.DelegationFilter{Dense: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), total)
+	require.Len(t, dense, 2)
+	assert.Equal(t, "cosmos1alice", dense[0].DelegatorAddress)
+	assert.True(t, dense[0].CarriedForward)
+	assert.Equal(t, int64(102), dense[0].BlockHeight)
+	assert.Equal(t, "1000", dense[0].DelegationAmount.String())
+	assert.True(t, dense[0].ChangeAmount.IsZero())
+	assert.Equal(t, "cosmos1bob", dense[1].DelegatorAddress)
+	assert.False(t, dense[1].CarriedForward)
+	assert.Equal(t, "200", dense[1].ChangeAmount.String())
+
+	history, total, err := delegations.FetchDelegatorHistoryWithPagination(ctx, entry.ChainID, entry.ValidatorAddress, "cosmos1alice", 1, 10, dto.DelegationFilter{Dense: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	for i, height := range []int64{102, 101, 100} {
+		assert.Equal(t, height, history[i].BlockHeight)
+		assert.Equal(t, "1000", history[i].DelegationAmount.String())
+	}
+
+	// Daily rows and rollups include positions that did not change during the day
+	for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+		_, err := testAggregator().aggregateDay(ctx, watchlist, day)
+		require.NoError(t, err)
+	}
+
+	var daily []models.DailyDelegation
+	require.NoError(t, db.DB.Where("date = ?", calendarDate(today)).Order("delegator_address").Find(&daily).Error)
+	require.Len(t, daily, 2)
+	assert.Equal(t, "1000", daily[0].TotalDelegation.String())
+	assert.Equal(t, "700", daily[1].TotalDelegation.String())
+
+	rollups, _, err := delegations.FetchDelegationRollupsWithPagination(ctx, models.PeriodDay, entry.ChainID, entry.ValidatorAddress, 1, 2)
+	require.NoError(t, err)
+	require.Len(t, rollups, 2)
+	assert.Equal(t, "cosmos1alice", rollups[0].DelegatorAddress)
+	assert.Equal(t, "1000", rollups[0].Open.String())
+	assert.Equal(t, "1000", rollups[0].Close.String())
+	assert.Equal(t, int64(2), rollups[0].Snapshots)  // carried into both of today's runs
+	assert.Equal(t, "500", rollups[1].Open.String()) // carried until Bob's change in the second run
+	assert.Equal(t, "700", rollups[1].Close.String())
+	assert.Equal(t, "500", rollups[1].Min.String())
+	assert.Equal(t, int64(2), rollups[1].Snapshots)
+}
+
+// a paged delegation response listing the delegators, pointing at the next page's key
+func delegationPageJSON(nextKey, total string, delegators ...string) string {
+	items := make([]string, len(delegators))
+	for i, delegator := range delegators {
+		items[i] = fmt.Sprintf(`{"delegation": {"delegator_address": %q, "shares": "100.0"}, "balance": {"denom": "uatom", "amount": "100"}}`, delegator)
+	}
+	return fmt.Sprintf(`{"delegation_responses": [%s], "pagination": {"next_key": %q, "total": %q}}`,
+		strings.Join(items, ","), nextKey, total)
+}
+
+// serves delegation pages by pagination key, answering 404 for unknown keys, and records each request
+func serveDelegationPages(t *testing.T, chainID string, pages map[string]string) (config.ChainConfig, *[]*http.Request) {
+	var mu sync.Mutex
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r)
+		mu.Unlock()
+
+		body, ok := pages[r.URL.Query().Get("pagination.key")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return config.ChainConfig{ChainID: chainID, LCDEndpoints: []string{server.URL}}, &requests
+}
+
+func TestFetchAllDelegationsFollowsNextKey(t *testing.T) {
+	chain, requests := serveDelegationPages(t, "pagination-test-1", map[string]string{
+		"":     delegationPageJSON("key2", "5", "cosmos1alice", "cosmos1bob"),
+		"key2": delegationPageJSON("key3", "", "cosmos1bob", "cosmos1carol"), // bob shifted onto this page too
+		"key3": delegationPageJSON("key4", "", "cosmos1dave"),
+		"key4": delegationPageJSON("", "", "cosmos1erin"),
+	})
+
+	result, pages, err := fetchAllDelegations(context.Background(), chain, "cosmosvaloper1watched", 100)
+	require.NoError(t, err)
+	assert.Equal(t, 4, pages)
+	assert.Equal(t, "5", result.Pagination.Total)
+
+	var delegators []string
+	for _, delegation := range result.Delegations {
+		delegators = append(delegators, delegation.Delegation.DelegatorAddress)
+	}
+	assert.Equal(t, []string{"cosmos1alice", "cosmos1bob", "cosmos1carol", "cosmos1dave", "cosmos1erin"}, delegators)
+
+	// Only the first page asks for the total, every later one passes the previous next_key
+	require.Len(t, *requests, 4)
+	for i, r := range *requests {
+		query := r.URL.Query()
+		assert.Equal(t, strconv.Itoa(DelegationPageLimit), query.Get("pagination.limit"))
+		assert.Equal(t, i == 0, query.Has("pagination.count_total"), "page %d", i+1)
+		if i > 0 {
+			assert.Equal(t, fmt.Sprintf("key%d", i+1), query.Get("pagination.key"))
+		}
+	}
+}
+
+func TestFetchAllDelegationsStopsOnStuckKey(t *testing.T) {
+	chain, requests := serveDelegationPages(t, "pagination-test-2", map[string]string{
+		"":     delegationPageJSON("key2", "3", "cosmos1alice"),
+		"key2": delegationPageJSON("key2", "", "cosmos1bob"), // never advances
+	})
+
+	_, pages, err := fetchAllDelegations(context.Background(), chain, "cosmosvaloper1watched", 100)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `did not advance past key "key2"`)
+	assert.Equal(t, 2, pages)
+	assert.Len(t, *requests, 2)
+}
+
+func TestFetchAllDelegationsFailsOnMiddlePageError(t *testing.T) {
+	// The third of four pages fails with 404
+	chain, requests := serveDelegationPages(t, "pagination-test-3", map[string]string{
+		"":     delegationPageJSON("key2", "3", "cosmos1alice"),
+		"key2": delegationPageJSON("key3", "", "cosmos1bob"),
+		"key4": delegationPageJSON("", "", "cosmos1carol"),
+	})
+
+	result, pages, err := fetchAllDelegations(context.Background(), chain, "cosmosvaloper1watched", 100)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "page 3")
+	assert.Equal(t, 2, pages)
+	assert.Empty(t, result.Delegations, "a partial snapshot is never returned")
+	assert.Len(t, *requests, 3)
+}
@@ -1,35 +1,87 @@
 package services
 
 import (
+	"context"
+	stderrors "errors"
+	"fmt"
 	"log"
 	"time"
 
+	"cosmos-tracker/config"
 	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/errors"
 	"cosmos-tracker/internal/models"
-	"cosmos-tracker/pkg/db"
+	"cosmos-tracker/internal/repository"
+	"cosmos-tracker/pkg/numeric"
 )
 
-// retrieves paginated hourly delegation changes
-func FetchHourlyDelegationsWithPagination(validatorAddress string, page, limit int) ([]dto.HourlyDelegationDTO, int64, error) {
-	var delegations []models.HourlyDelegation
-	var total int64
+// DelegationService serves stored delegation history and current positions
+type DelegationService struct {
+	delegations repository.DelegationRepository
+}
 
-	offset := (page - 1) * limit
+// creates a delegation service on top of a repository
+func NewDelegationService(delegations repository.DelegationRepository) *DelegationService {
+	return &DelegationService{delegations: delegations}
+}
 
-	// Count total records
-	if err := db.DB.Model(&models.HourlyDelegation{}).
-		Where("validator_address = ?", validatorAddress).
-		Count(&total).Error; err != nil {
+// builds the repository query for one page of a validator's data
+func delegationQuery(chainID, validatorAddress, delegatorAddress string, page, limit int, filter dto.DelegationFilter) repository.DelegationQuery {
+	query := repository.DelegationQuery{
+		ChainID:          chainID,
+		ValidatorAddress: validatorAddress,
+		DelegatorAddress: delegatorAddress,
+		Height:           filter.Height,
+		FromTime:         filter.FromTime,
+		FromHeight:       filter.FromHeight,
+		ToTime:           filter.ToTime,
+		ToHeight:         filter.ToHeight,
+		Ascending:        filter.Ascending,
+		MinAmount:        filter.MinAmount,
+		MinChange:        filter.MinChange,
+		Limit:            limit,
+		Offset:           (page - 1) * limit,
+	}
+	switch filter.Change {
+	case dto.ChangePositive:
+		query.ChangeSign = 1
+	case dto.ChangeNegative:
+		query.ChangeSign = -1
+	}
+	return query
+}
+
+// retrieves a dense page of hourly positions, one per delegator and run recorded by a heartbeat,
+// optionally for a single delegator; positions a run did not store are carried from the last row
+func (s *DelegationService) fetchDenseDelegations(ctx context.Context, query repository.DelegationQuery) ([]dto.HourlyDelegationDTO, int64, error) {
+	rows, total, err := s.delegations.DenseDelegations(ctx, query)
+	if err != nil {
 		return nil, 0, err
 	}
 
-	// Get paginated data
-	err := db.DB.Where("validator_address = ?", validatorAddress).
-		Order("timestamp DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&delegations).Error
+	// Convert to DTOs, clearing what only applies to the run a row was written in
+	result := make([]dto.HourlyDelegationDTO, len(rows))
+	for i, r := range rows {
+		result[i] = toHourlyDelegationDTO(r.HourlyDelegation)
+		if !r.Timestamp.Equal(r.RunTimestamp) {
+			result[i].ChangeAmount = numeric.NewInt(0)
+			result[i].Returned = false
+			result[i].BlockHeight = r.RunHeight
+			result[i].BlockTime = r.RunBlockTime
+			result[i].Timestamp = r.RunTimestamp
+			result[i].CarriedForward = true
+		}
+	}
+	return result, total, nil
+}
 
+// retrieves a page of hourly rows, or a dense series when the filter asks for one
+func (s *DelegationService) fetchHourly(ctx context.Context, query repository.DelegationQuery, filter dto.DelegationFilter) ([]dto.HourlyDelegationDTO, int64, error) {
+	if filter.Dense {
+		return s.fetchDenseDelegations(ctx, query)
+	}
+
+	delegations, total, err := s.delegations.HourlyDelegations(ctx, query)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -37,40 +89,51 @@ func FetchHourlyDelegationsWithPagination(validatorAddress string, page, limit i
 	// Convert to DTOs
 	result := make([]dto.HourlyDelegationDTO, len(delegations))
 	for i, d := range delegations {
-		result[i] = dto.HourlyDelegationDTO{
-			ID:               d.ID,
-			ValidatorAddress: d.ValidatorAddress,
-			DelegatorAddress: d.DelegatorAddress,
-			DelegationAmount: d.DelegationAmount,
-			ChangeAmount:     d.ChangeAmount,
-			Timestamp:        d.Timestamp,
-		}
+		result[i] = toHourlyDelegationDTO(d)
 	}
 
 	return result, total, nil
 }
 
-// retrieves paginated daily delegation changes
-func FetchDailyDelegationsWithPagination(validatorAddress string, page, limit int) ([]dto.DailyDelegationDTO, int64, error) {
-	var delegations []models.DailyDelegation
-	var total int64
+// converts a stored snapshot row to its API representation
+func toHourlyDelegationDTO(d models.HourlyDelegation) dto.HourlyDelegationDTO {
+	return dto.HourlyDelegationDTO{
+		ID:               d.ID,
+		ChainID:          d.ChainID,
+		ValidatorAddress: d.ValidatorAddress,
+		DelegatorAddress: d.DelegatorAddress,
+		DelegationAmount: d.DelegationAmount,
+		ChangeAmount:     d.ChangeAmount,
+		Shares:           d.Shares,
+		Exited:           d.Exited,
+		Returned:         d.Returned,
+		BlockHeight:      d.BlockHeight,
+		BlockTime:        d.BlockTime,
+		Timestamp:        d.Timestamp,
+	}
+}
 
-	offset := (page - 1) * limit
+// retrieves paginated hourly delegation changes, or a dense series when the filter asks for one
+func (s *DelegationService) FetchHourlyDelegationsWithPagination(ctx context.Context, chainID, validatorAddress string, page, limit int, filter dto.DelegationFilter) ([]dto.HourlyDelegationDTO, int64, error) {
+	return s.fetchHourly(ctx, delegationQuery(chainID, validatorAddress, "", page, limit, filter), filter)
+}
 
-	// Count total records
-	if err := db.DB.Model(&models.DailyDelegation{}).
-		Where("validator_address = ?", validatorAddress).
-		Count(&total).Error; err != nil {
-		return nil, 0, err
+// retrieves paginated daily delegation changes
+func (s *DelegationService) FetchDailyDelegationsWithPagination(ctx context.Context, chainID, validatorAddress string, page, limit int, filter dto.DelegationFilter) ([]dto.DailyDelegationDTO, int64, error) {
+	if filter.MinChange != nil || filter.Change != "" {
+		return nil, 0, errors.NewBadRequestError("min_change and change only apply to hourly rows", nil)
 	}
 
-	// Get paginated data
-	err := db.DB.Where("validator_address = ?", validatorAddress).
-		Order("date DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&delegations).Error
+	// Daily rows are dated by calendar day, so time bounds match the days that hold them
+	query := delegationQuery(chainID, validatorAddress, "", page, limit, filter)
+	if !query.FromTime.IsZero() {
+		query.FromTime = calendarDate(startOfDay(query.FromTime))
+	}
+	if !query.ToTime.IsZero() {
+		query.ToTime = calendarDate(startOfDay(query.ToTime))
+	}
 
+	delegations, total, err := s.delegations.DailyDelegations(ctx, query)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -80,197 +143,321 @@ func FetchDailyDelegationsWithPagination(validatorAddress string, page, limit in
 	for i, d := range delegations {
 		result[i] = dto.DailyDelegationDTO{
 			ID:               d.ID,
+			ChainID:          d.ChainID,
 			ValidatorAddress: d.ValidatorAddress,
 			DelegatorAddress: d.DelegatorAddress,
 			TotalDelegation:  d.TotalDelegation,
-			Date:             d.Date,
+			TotalShares:      d.TotalShares,
+			BlockHeight:      d.BlockHeight,
+			BlockTime:        d.BlockTime,
+			Date:             d.Date.UTC().Format(DateLayout),
+			Timezone:         d.Timezone,
 		}
 	}
 
 	return result, total, nil
 }
 
-// retrieves paginated delegation history for a specific delegator
-func FetchDelegatorHistoryWithPagination(validatorAddress, delegatorAddress string, page, limit int) ([]dto.HourlyDelegationDTO, int64, error) {
-	var history []models.HourlyDelegation
-	var total int64
+// retrieves paginated delegation history for a specific delegator, or a dense series when the filter asks for one
+func (s *DelegationService) FetchDelegatorHistoryWithPagination(ctx context.Context, chainID, validatorAddress, delegatorAddress string, page, limit int, filter dto.DelegationFilter) ([]dto.HourlyDelegationDTO, int64, error) {
+	return s.fetchHourly(ctx, delegationQuery(chainID, validatorAddress, delegatorAddress, page, limit, filter), filter)
+}
 
-	offset := (page - 1) * limit
+// retrieves a validator's current delegators, largest first, with the count and total stake of all of them
+func (s *DelegationService) FetchCurrentDelegatorsWithPagination(ctx context.Context, chainID, validatorAddress string, page, limit int) (dto.CurrentDelegatorsDTO, error) {
+	result := dto.CurrentDelegatorsDTO{
+		ChainID:          chainID,
+		ValidatorAddress: validatorAddress,
+		Delegators:       []dto.CurrentDelegationDTO{},
+	}
 
-	// Count total records
-	if err := db.DB.Model(&models.HourlyDelegation{}).
-		Where("validator_address = ? AND delegator_address = ?", validatorAddress, delegatorAddress).
-		Count(&total).Error; err != nil {
-		return nil, 0, err
+	positions, totals, err := s.delegations.CurrentDelegators(ctx, delegationQuery(chainID, validatorAddress, "", page, limit, dto.DelegationFilter{}))
+	if err != nil {
+		return result, err
 	}
+	result.TotalDelegators = totals.TotalDelegators
+	result.TotalStake = totals.TotalStake
 
-	// Get paginated data
-	err := db.DB.Where("validator_address = ? AND delegator_address = ?", validatorAddress, delegatorAddress).
-		Order("timestamp DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&history).Error
+	for _, p := range positions {
+		result.Delegators = append(result.Delegators, toCurrentDelegationDTO(p))
+	}
 
+	return result, nil
+}
+
+// retrieves one delegator's current position with a validator
+func (s *DelegationService) FetchCurrentDelegation(ctx context.Context, chainID, validatorAddress, delegatorAddress string) (dto.CurrentDelegationDTO, error) {
+	position, err := s.delegations.CurrentDelegation(ctx, chainID, validatorAddress, delegatorAddress)
+	if stderrors.Is(err, repository.ErrNotFound) {
+		return dto.CurrentDelegationDTO{}, errors.NewNotFoundError("Current delegation", err)
+	}
 	if err != nil {
-		return nil, 0, err
+		return dto.CurrentDelegationDTO{}, err
 	}
 
-	// Convert to DTOs
-	result := make([]dto.HourlyDelegationDTO, len(history))
-	for i, h := range history {
-		result[i] = dto.HourlyDelegationDTO{
-			ID:               h.ID,
-			ValidatorAddress: h.ValidatorAddress,
-			DelegatorAddress: h.DelegatorAddress,
-			DelegationAmount: h.DelegationAmount,
-			ChangeAmount:     h.ChangeAmount,
-			Timestamp:        h.Timestamp,
-		}
+	return toCurrentDelegationDTO(position), nil
+}
+
+// converts a current delegation model to its API representation
+func toCurrentDelegationDTO(p models.CurrentDelegation) dto.CurrentDelegationDTO {
+	return dto.CurrentDelegationDTO{
+		ChainID:          p.ChainID,
+		ValidatorAddress: p.ValidatorAddress,
+		DelegatorAddress: p.DelegatorAddress,
+		DelegationAmount: p.DelegationAmount,
+		Shares:           p.Shares,
+		BlockHeight:      p.BlockHeight,
+		BlockTime:        p.BlockTime,
+		UpdatedAt:        p.UpdatedAt,
 	}
+}
 
-	return result, total, nil
+// Layout of dates accepted by the re-aggregation endpoint and CLI
+const DateLayout = "2006-01-02"
+
+// returns midnight at the start of t's day in the aggregation timezone
+func startOfDay(t time.Time) time.Time {
+	location := config.AggregationLocation()
+	t = t.In(location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+}
+
+// converts a day in the aggregation timezone to the value stored in a date column,
+// midnight UTC of the same calendar date, so no driver shifts it across a boundary
+func calendarDate(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
 }
 
-// compiles hourly data into daily summaries
-func AggregateDailyDelegations() error {
-	// Get current date at midnight for proper grouping
-	now := time.Now()
-	yesterday := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, now.Location())
+// converts a stored date column back to midnight in the aggregation timezone
+func dayFromCalendarDate(date time.Time) time.Time {
+	date = date.UTC()
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, config.AggregationLocation())
+}
+
+// Aggregator rolls hourly snapshots into daily rows, rollups and stats, prunes expired hourly data
+// and maintains its partitions
+type Aggregator struct {
+	watchlist   repository.WatchlistRepository
+	aggregation repository.AggregationRepository
+	retention   repository.RetentionRepository
+	queued      chan dateRange // re-aggregations waiting for the scheduler
+}
+
+// inclusive range of days to re-aggregate
+type dateRange struct {
+	from, to time.Time
+}
+
+// creates an aggregator on top of a storage backend's repositories
+func NewAggregator(repos repository.Repositories) *Aggregator {
+	return &Aggregator{
+		watchlist:   repos.Watchlist,
+		aggregation: repos.Aggregation,
+		retention:   repos.Retention,
+		queued:      make(chan dateRange, 1),
+	}
+}
+
+// compiles hourly data into daily summaries, catching up every day missed since each entry was last aggregated
+func (a *Aggregator) AggregateDailyDelegations(ctx context.Context) error {
+	// Only whole days are aggregated, so the last one is yesterday
+	yesterday := startOfDay(time.Now()).AddDate(0, 0, -1)
 
 	// Find all watchlist entries
-	var watchlistItems []models.Watchlist
-	if err := db.DB.Find(&watchlistItems).Error; err != nil {
+	watchlistItems, err := a.watchlist.ListWatchlist(ctx, "")
+	if err != nil {
 		return err
 	}
 
 	log.Printf("Found %d watchlist items to process", len(watchlistItems))
 
-	tx := db.DB.Begin()
-	if tx.Error != nil {
-		return tx.Error
-	}
-
 	for _, watchlist := range watchlistItems {
-		// Get all unique delegator addresses for this validator
-		var delegatorAddresses []string
-		if err := tx.Model(&models.HourlyDelegation{}).
-			Where("validator_address = ? AND timestamp >= ? AND timestamp < ?",
-				watchlist.ValidatorAddress, yesterday, yesterday.AddDate(0, 0, 1)).
-			Distinct("delegator_address").
-			Pluck("delegator_address", &delegatorAddresses).Error; err != nil {
-			tx.Rollback()
+		from, ok, err := a.nextAggregationDay(ctx, watchlist)
+		if err != nil {
 			return err
 		}
+		if !ok || from.After(yesterday) {
+			continue // no hourly data yet, or already up to date
+		}
 
-		log.Printf("Found %d delegators for validator %s", len(delegatorAddresses), watchlist.ValidatorAddress)
-
-		// Process each delegator for this validator
-		for _, delegatorAddr := range delegatorAddresses {
-			var latestDelegation models.HourlyDelegation
-
-			// Find the latest hourly record for this validator-delegator pair
-			if err := tx.Model(&models.HourlyDelegation{}).
-				Where("validator_address = ? AND delegator_address = ? AND timestamp >= ? AND timestamp < ?",
-					watchlist.ValidatorAddress, delegatorAddr, yesterday, yesterday.AddDate(0, 0, 1)).
-				Order("timestamp DESC").
-				Limit(1).
-				First(&latestDelegation).Error; err != nil {
-				if err.Error() != "record not found" {
-					log.Printf("Error querying hourly delegation: %v", err)
-					tx.Rollback()
-					return err
-				}
-				continue // No data for this delegator yesterday
+		if missing := int(yesterday.Sub(from).Hours()/24) + 1; missing > 1 {
+			log.Printf("⏪ Catching up %d days of aggregation for [%s] %s from %s",
+				missing, watchlist.ChainID, watchlist.ValidatorAddress, from.Format(DateLayout))
+		}
+
+		for day := from; !day.After(yesterday); day = day.AddDate(0, 0, 1) {
+			rows, err := a.aggregateDay(ctx, watchlist, day)
+			if err != nil {
+				return err
 			}
+			log.Printf("✅ Aggregated %d delegators for [%s] %s on %s",
+				rows, watchlist.ChainID, watchlist.ValidatorAddress, day.Format(DateLayout))
+		}
+	}
 
-			log.Printf("Found latest delegation for %s -> %s: %d tokens",
-				watchlist.ValidatorAddress, delegatorAddr, latestDelegation.DelegationAmount)
+	return nil
+}
 
-			// Check if daily record already exists
-			var existingDaily models.DailyDelegation
-			err := tx.Where("validator_address = ? AND delegator_address = ? AND date = ?",
-				watchlist.ValidatorAddress, delegatorAddr, yesterday).
-				First(&existingDaily).Error
+// normalizes a re-aggregation range to whole days and checks it against REAGGREGATION_MAX_DAYS;
+// returns the first and last day and how many days the range covers
+func reaggregationRange(from, to time.Time) (time.Time, time.Time, int, error) {
+	from, to = startOfDay(from), startOfDay(to)
+	days := int(to.Sub(from).Hours()/24) + 1
 
-			if err != nil && err.Error() != "record not found" {
-				tx.Rollback()
-				return err
-			}
+	if to.Before(from) {
+		return from, to, 0, errors.NewBadRequestError("from must not be after to", nil)
+	}
+	if !to.Before(startOfDay(time.Now())) {
+		return from, to, 0, errors.NewBadRequestError("to must be before today; only whole days can be aggregated", nil)
+	}
+	if limit := config.AdminConfig().MaxReaggregationDays; days > limit {
+		return from, to, 0, errors.NewBadRequestError(fmt.Sprintf("range covers %d days, the maximum is %d", days, limit), nil)
+	}
+	return from, to, days, nil
+}
+
+// validates a re-aggregation range and hands it to the running scheduler, which works through it
+// between scheduled passes; only one re-aggregation may wait at a time
+func (a *Aggregator) QueueReaggregation(from, to time.Time) (dto.QueuedAggregationDTO, error) {
+	from, to, days, err := reaggregationRange(from, to)
+	queued := dto.QueuedAggregationDTO{From: from.Format(DateLayout), To: to.Format(DateLayout), Days: days}
+	if err != nil {
+		return queued, err
+	}
+
+	select {
+	case a.queued <- dateRange{from: from, to: to}:
+		log.Printf("📥 Queued re-aggregation of %d days (%s to %s)", days, queued.From, queued.To)
+		return queued, nil
+	default:
+		return queued, errors.NewConflictError("a re-aggregation is already queued; try again once it has started", nil)
+	}
+}
+
+// re-aggregates every watchlist entry for each day in [from, to]; repeating a range yields the same rows
+func (a *Aggregator) ReaggregateDailyDelegations(ctx context.Context, from, to time.Time) (dto.AggregationResultDTO, error) {
+	from, to, _, err := reaggregationRange(from, to)
+	result := dto.AggregationResultDTO{From: from.Format(DateLayout), To: to.Format(DateLayout)}
+	if err != nil {
+		return result, err
+	}
+
+	watchlistItems, err := a.watchlist.ListWatchlist(ctx, "")
+	if err != nil {
+		return result, err
+	}
+	result.Entries = len(watchlistItems)
 
-			// Create or update daily record
-			if existingDaily.ID == 0 {
-				dailyRecord := models.DailyDelegation{
-					WatchlistID:      watchlist.ID,
-					ValidatorAddress: watchlist.ValidatorAddress,
-					DelegatorAddress: delegatorAddr, // Include delegator address
-					TotalDelegation:  latestDelegation.DelegationAmount,
-					TotalShares:      latestDelegation.Shares,
-					Date:             yesterday,
-				}
-				if err := tx.Create(&dailyRecord).Error; err != nil {
-					log.Printf("❌ Error creating daily delegation record: %v", err)
-					tx.Rollback()
-					return err
-				}
-				log.Printf("✅ Created new daily record for %s -> %s",
-					watchlist.ValidatorAddress, delegatorAddr)
-			} else {
-				existingDaily.TotalDelegation = latestDelegation.DelegationAmount
-				existingDaily.TotalShares = latestDelegation.Shares
-				if err := tx.Save(&existingDaily).Error; err != nil {
-					log.Printf("❌ Error updating daily delegation record: %v", err)
-					tx.Rollback()
-					return err
-				}
-				log.Printf("✅ Updated daily record for %s -> %s",
-					watchlist.ValidatorAddress, delegatorAddr)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		for _, watchlist := range watchlistItems {
+			rows, err := a.aggregateDay(ctx, watchlist, day)
+			if err != nil {
+				return result, err
 			}
+			result.Rows += rows
 		}
+		result.Days++
 	}
 
-	log.Println("⭐ Daily aggregation transaction completed, committing changes...")
-	return tx.Commit().Error
+	log.Printf("🔁 Re-aggregated %d days (%s to %s): %d daily rows for %d entries",
+		result.Days, result.From, result.To, result.Rows, result.Entries)
+	return result, nil
 }
 
-// schedules daily aggregation to run at midnight
-func ScheduleDailyAggregation() {
-	// Run aggregation immediately at startup
-	log.Println("🚀 Running initial daily aggregation...")
-	if err := AggregateDailyDelegations(); err != nil {
-		log.Printf("❌ Initial daily aggregation failed: %v", err)
-	} else {
-		log.Println("✅ Initial daily aggregation completed successfully")
+// parses an inclusive YYYY-MM-DD date range for re-aggregation
+func ParseDateRange(from, to string) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation(DateLayout, from, config.AggregationLocation())
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.NewBadRequestError("from must be a date in YYYY-MM-DD format", err)
 	}
+	end, err := time.ParseInLocation(DateLayout, to, config.AggregationLocation())
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.NewBadRequestError("to must be a date in YYYY-MM-DD format", err)
+	}
+	return start, end, nil
+}
 
-	// Calculate time until next midnight
-	now := time.Now()
-	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 5, 0, 0, now.Location())
-	duration := midnight.Sub(now)
+// finds the first day an entry still needs: the day after its last daily row,
+// or the day of its first hourly snapshot if it has never been aggregated
+func (a *Aggregator) nextAggregationDay(ctx context.Context, watchlist models.Watchlist) (time.Time, bool, error) {
+	last, ok, err := a.aggregation.LatestDailyDate(ctx, watchlist.ChainID, watchlist.ValidatorAddress)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if ok {
+		return dayFromCalendarDate(last).AddDate(0, 0, 1), true, nil
+	}
 
-	log.Printf("🕒 Scheduling next daily aggregation to run in %v", duration)
+	first, ok, err := a.aggregation.FirstSnapshotTime(ctx, watchlist.ChainID, watchlist.ValidatorAddress)
+	if err != nil || !ok {
+		return time.Time{}, false, err
+	}
+	return startOfDay(first), true, nil
+}
 
-	// Schedule the next run at midnight because for the first init
-	go func() {
-		time.Sleep(duration)
+// rebuilds one entry's daily rows, rollups and validator stats for one day from each delegator's last
+// hourly snapshot as of the day's end, which in change-only storage may predate the day. Days without
+// a collection run are left untouched so pruned history never wipes existing rows.
+func (a *Aggregator) aggregateDay(ctx context.Context, watchlist models.Watchlist, day time.Time) (int, error) {
+	return a.aggregation.AggregateDay(ctx, dayAggregation(watchlist, day))
+}
 
-		log.Println("⏰ Running scheduled midnight aggregation...")
-		if err := AggregateDailyDelegations(); err != nil {
-			log.Printf("❌ Midnight aggregation failed: %v", err)
-		} else {
-			log.Println("✅ Midnight aggregation completed successfully")
-		}
+// runs one aggregation pass, letting it drain if shutdown starts mid-transaction
+func (a *Aggregator) runDailyAggregation(ctx context.Context, label string) {
+	aggCtx, cancel := drainContext(ctx)
+	defer cancel()
 
-		// Then for every 24 hours
-		ticker := time.NewTicker(24 * time.Hour)
-		defer ticker.Stop()
+	// Keep future months partitioned before anything else, as collection depends on it
+	if err := a.EnsureHourlyPartitions(aggCtx); err != nil {
+		log.Printf("❌ Hourly partition maintenance failed: %v", err)
+	}
 
-		for range ticker.C {
-			log.Println("⏰ Running daily scheduled aggregation...")
-			if err := AggregateDailyDelegations(); err != nil {
-				log.Printf("❌ Daily aggregation failed: %v", err)
-			} else {
-				log.Println("✅ Daily aggregation completed successfully")
-			}
+	log.Printf("⏰ Running %s aggregation...", label)
+	if err := a.AggregateDailyDelegations(aggCtx); err != nil {
+		log.Printf("❌ %s aggregation failed: %v", label, err)
+	} else {
+		log.Printf("✅ %s aggregation completed successfully", label)
+	}
+
+	// Prune after aggregating so the days just rolled up become eligible
+	if err := a.PruneHourlyData(aggCtx); err != nil {
+		log.Printf("❌ Hourly retention failed: %v", err)
+	}
+}
+
+// schedules daily aggregation to run at midnight until ctx is cancelled, running queued
+// re-aggregations in between so they never overlap a scheduled pass
+func (a *Aggregator) ScheduleDailyAggregation(ctx context.Context) {
+	// Run aggregation immediately at startup
+	a.runDailyAggregation(ctx, "Initial daily")
+
+	for {
+		// Recompute the next 00:05 each time so DST shifts in the aggregation timezone don't drift the schedule
+		now := time.Now()
+		next := startOfDay(now).AddDate(0, 0, 1).Add(5 * time.Minute)
+		duration := next.Sub(now)
+
+		log.Printf("🕒 Scheduling next daily aggregation for %s (in %v)", next.Format(time.RFC3339), duration)
+
+		timer := time.NewTimer(duration)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Println("🛑 Daily aggregation scheduler stopped")
+			return
+		case request := <-a.queued:
+			timer.Stop()
+			a.runReaggregation(ctx, request)
+		case <-timer.C:
+			a.runDailyAggregation(ctx, "Midnight")
 		}
-	}()
+	}
+}
+
+// runs one queued re-aggregation; shutdown cancels it, rolling back only the day in progress
+func (a *Aggregator) runReaggregation(ctx context.Context, request dateRange) {
+	if _, err := a.ReaggregateDailyDelegations(ctx, request.from, request.to); err != nil {
+		log.Printf("❌ Re-aggregation of %s to %s failed: %v",
+			request.from.Format(DateLayout), request.to.Format(DateLayout), err)
+	}
 }
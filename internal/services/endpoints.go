@@ -0,0 +1,215 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/dto"
+)
+
+// Tuning for the rolling endpoint health score
+const (
+	endpointSmoothing = 0.3              // weight of the newest sample in the moving averages
+	endpointHalfLife  = 10 * time.Minute // how quickly past errors are forgiven
+	endpointLatencyMs = 500.0            // latency at which the score is halved
+)
+
+// outcome of a single request against an LCD endpoint
+type endpointOutcome int
+
+const (
+	outcomeSuccess endpointOutcome = iota
+	outcomeError
+	outcomeThrottled
+)
+
+// rolling health statistics for one LCD endpoint
+type endpointState struct {
+	url           string
+	latencyMs     float64 // moving average of successful request latency
+	errorRate     float64 // moving average of failed requests
+	throttleRate  float64 // moving average of 429 responses
+	requests      int64
+	updatedAt     time.Time
+	lastError     string
+	lastErrorAt   time.Time
+	lastSuccessAt time.Time
+}
+
+// decays the error and throttle rates so unused endpoints recover over time
+func (e *endpointState) decayed(now time.Time) (float64, float64) {
+	if e.updatedAt.IsZero() {
+		return e.errorRate, e.throttleRate
+	}
+	factor := math.Pow(0.5, now.Sub(e.updatedAt).Seconds()/endpointHalfLife.Seconds())
+	return e.errorRate * factor, e.throttleRate * factor
+}
+
+// scores the endpoint from 0 to 100; higher is better
+func (e *endpointState) score(now time.Time) float64 {
+	errorRate, throttleRate := e.decayed(now)
+
+	reliability := 1 - errorRate - 0.5*throttleRate
+	if reliability < 0 {
+		reliability = 0
+	}
+	return 100 * reliability / (1 + e.latencyMs/endpointLatencyMs)
+}
+
+// folds one request outcome into the moving averages
+func (e *endpointState) record(now time.Time, latency time.Duration, outcome endpointOutcome, err error) {
+	errorRate, throttleRate := e.decayed(now)
+
+	var isError, isThrottled float64
+	switch outcome {
+	case outcomeError:
+		isError = 1
+	case outcomeThrottled:
+		isThrottled = 1
+	}
+
+	e.errorRate = errorRate + endpointSmoothing*(isError-errorRate)
+	e.throttleRate = throttleRate + endpointSmoothing*(isThrottled-throttleRate)
+	e.requests++
+	e.updatedAt = now
+
+	if outcome == outcomeSuccess {
+		ms := float64(latency.Milliseconds())
+		if e.lastSuccessAt.IsZero() {
+			e.latencyMs = ms
+		} else {
+			e.latencyMs += endpointSmoothing * (ms - e.latencyMs)
+		}
+		e.lastSuccessAt = now
+		return
+	}
+
+	if err != nil {
+		e.lastError = err.Error()
+		e.lastErrorAt = now
+	}
+}
+
+// set of LCD endpoints for one chain, ranked by health score
+type endpointPool struct {
+	mu        sync.Mutex
+	chainID   string
+	endpoints []*endpointState
+}
+
+// returns endpoints best-first, keeping configuration order for ties
+func (p *endpointPool) ranked() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	ordered := make([]*endpointState, len(p.endpoints))
+	copy(ordered, p.endpoints)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].score(now) > ordered[j].score(now)
+	})
+
+	urls := make([]string, len(ordered))
+	for i, e := range ordered {
+		urls[i] = e.url
+	}
+	return urls
+}
+
+// picks the best endpoint not yet tried, or the best overall once all were tried
+func (p *endpointPool) next(tried map[string]bool) string {
+	urls := p.ranked()
+	if len(urls) == 0 {
+		return ""
+	}
+	for _, u := range urls {
+		if !tried[u] {
+			return u
+		}
+	}
+	return urls[0]
+}
+
+// returns the number of endpoints in the pool
+func (p *endpointPool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.endpoints)
+}
+
+// records the outcome of a request against one of the pool's endpoints
+func (p *endpointPool) record(endpoint string, latency time.Duration, outcome endpointOutcome, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, e := range p.endpoints {
+		if e.url == endpoint {
+			e.record(time.Now(), latency, outcome, err)
+			return
+		}
+	}
+}
+
+// snapshots the pool's current health for the API
+func (p *endpointPool) health() []dto.EndpointHealthDTO {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	result := make([]dto.EndpointHealthDTO, len(p.endpoints))
+	for i, e := range p.endpoints {
+		errorRate, throttleRate := e.decayed(now)
+		result[i] = dto.EndpointHealthDTO{
+			ChainID:      p.chainID,
+			URL:          e.url,
+			Score:        math.Round(e.score(now)*100) / 100,
+			LatencyMs:    math.Round(e.latencyMs),
+			ErrorRate:    math.Round(errorRate*1000) / 1000,
+			ThrottleRate: math.Round(throttleRate*1000) / 1000,
+			Requests:     e.requests,
+			LastError:    e.lastError,
+		}
+		if !e.lastErrorAt.IsZero() {
+			lastErrorAt := e.lastErrorAt
+			result[i].LastErrorAt = &lastErrorAt
+		}
+		if !e.lastSuccessAt.IsZero() {
+			lastSuccessAt := e.lastSuccessAt
+			result[i].LastSuccessAt = &lastSuccessAt
+		}
+	}
+	return result
+}
+
+var (
+	endpointPools   = make(map[string]*endpointPool)
+	endpointPoolsMu sync.Mutex
+)
+
+// returns the shared endpoint pool for a chain
+func endpointPoolFor(chain config.ChainConfig) *endpointPool {
+	endpointPoolsMu.Lock()
+	defer endpointPoolsMu.Unlock()
+
+	pool, ok := endpointPools[chain.ChainID]
+	if !ok {
+		pool = &endpointPool{chainID: chain.ChainID}
+		for _, u := range chain.LCDEndpoints {
+			pool.endpoints = append(pool.endpoints, &endpointState{url: u})
+		}
+		endpointPools[chain.ChainID] = pool
+	}
+	return pool
+}
+
+// reports the health score of every configured LCD endpoint
+func EndpointHealth() []dto.EndpointHealthDTO {
+	var result []dto.EndpointHealthDTO
+	for _, chain := range config.Chains() {
+		result = append(result, endpointPoolFor(chain).health()...)
+	}
+	return result
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchFailsOverToHealthyEndpoint(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"delegation_responses":[],"pagination":{"next_key":null,"total":"0"}}`))
+	}))
+	defer up.Close()
+
+	chain := config.ChainConfig{ChainID: "failover-test-1", LCDEndpoints: []string{down.URL, up.URL}}
+
+	var page DelegationResponse
+	err := fetchChainJSON(context.Background(), chain, "/cosmos/staking/v1beta1/validators/x/delegations", 0, &page)
+	require.NoError(t, err)
+
+	// The failing endpoint is now ranked below the healthy one
+	pool := endpointPoolFor(chain)
+	assert.Equal(t, []string{up.URL, down.URL}, pool.ranked())
+
+	health := pool.health()
+	require.Len(t, health, 2)
+	assert.Equal(t, "HTTP 502", health[0].LastError)
+	assert.Greater(t, health[1].Score, health[0].Score)
+}
+
+func TestEndpointScoreRecoversOverTime(t *testing.T) {
+	state := &endpointState{url: "http://lcd"}
+	now := time.Now()
+
+	state.record(now, 0, outcomeError, assert.AnError)
+	state.record(now, 0, outcomeError, assert.AnError)
+	failing := state.score(now)
+
+	assert.Less(t, failing, state.score(now.Add(endpointHalfLife)))
+	assert.InDelta(t, 100, state.score(now.Add(20*endpointHalfLife)), 0.1)
+}
+
+func TestChainHealthProbesEndpointsConcurrently(t *testing.T) {
+	// Each stalled endpoint would hold a serial check for the full deadline
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer stalled.Close()
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer up.Close()
+
+	chains := []config.ChainConfig{
+		{ChainID: "probe-test-1", LCDEndpoints: []string{stalled.URL, stalled.URL + "/a", up.URL}},
+		{ChainID: "probe-test-2", LCDEndpoints: []string{stalled.URL, stalled.URL + "/b"}},
+	}
+
+	started := time.Now()
+	status := probeChains(context.Background(), chains)
+	elapsed := time.Since(started)
+
+	assert.Equal(t, map[string]bool{"probe-test-1": true, "probe-test-2": false}, status)
+	assert.Less(t, elapsed, ChainHealthTimeout+time.Second)
+
+	// The stalled endpoints are marked down in their pool
+	for _, endpoint := range endpointPoolFor(chains[1]).health() {
+		assert.NotEmpty(t, endpoint.LastError, endpoint.URL)
+	}
+}
+
+func TestPinnedHeightReachesEveryPageAndQuery(t *testing.T) {
+	var mu sync.Mutex
+	heights := make(map[string][]string) // request path and page key -> pinned heights sent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		key := r.URL.Path + "?" + r.URL.Query().Get("pagination.key")
+		heights[key] = append(heights[key], r.Header.Get(BlockHeightHeader))
+		mu.Unlock()
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/delegations") && r.URL.Query().Get("pagination.key") == "":
+			w.Write([]byte(delegationPageJSON("key2", "2", "cosmos1alice")))
+		case strings.HasSuffix(r.URL.Path, "/delegations"):
+			w.Write([]byte(delegationPageJSON("", "", "cosmos1bob")))
+		case strings.HasSuffix(r.URL.Path, "/unbonding_delegations"):
+			w.Write([]byte(`{"unbonding_responses": [], "pagination": {"next_key": null}}`))
+		case strings.HasSuffix(r.URL.Path, "/pool"):
+			w.Write([]byte(`{"pool": {"bonded_tokens": "1000000"}}`))
+		default:
+			w.Write([]byte(`{"validator": {"status": "BOND_STATUS_BONDED", "tokens": "1000", "delegator_shares": "1000.0"}}`))
+		}
+	}))
+	defer server.Close()
+
+	chain := config.ChainConfig{ChainID: "pinned-test-1", DenomExponent: 6, LCDEndpoints: []string{server.URL}}
+	entry := dto.WatchlistEntry{ChainID: chain.ChainID, ValidatorAddress: "cosmosvaloper1watched"}
+	block := blockRef{Height: 4242, Time: time.Now()}
+	ctx := context.Background()
+
+	_, _, err := fetchAllDelegations(ctx, chain, entry.ValidatorAddress, block.Height)
+	require.NoError(t, err)
+	_, _, err = fetchAllUnbondings(ctx, chain, entry, block.Height)
+	require.NoError(t, err)
+	_, err = fetchValidatorSnapshot(ctx, chain, entry, block)
+	require.NoError(t, err)
+
+	// The second delegation page and every follow-up query read the same block as the first page
+	prefix := "/cosmos/staking/v1beta1/validators/cosmosvaloper1watched"
+	assert.Equal(t, map[string][]string{
+		prefix + "/delegations?":           {"4242"},
+		prefix + "/delegations?key2":       {"4242"},
+		prefix + "/unbonding_delegations?": {"4242"},
+		prefix + "?":                       {"4242"},
+		"/cosmos/staking/v1beta1/pool?":    {"4242"},
+	}, heights)
+}
+
+func TestLaggingEndpointFailsOverAtPinnedHeight(t *testing.T) {
+	var laggingHits, healthyHits atomic.Int64
+	lagging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		laggingHits.Add(1)
+		w.WriteHeader(http.StatusBadRequest) // height is not available on this node
+	}))
+	defer lagging.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		healthyHits.Add(1)
+		w.Write([]byte(delegationPageJSON("", "1", "cosmos1alice")))
+	}))
+	defer healthy.Close()
+
+	path := "/cosmos/staking/v1beta1/validators/x/delegations"
+
+	// At a pinned height the 400 moves on to the next endpoint
+	pinned := config.ChainConfig{ChainID: "lagging-test-1", LCDEndpoints: []string{lagging.URL, healthy.URL}}
+	var page DelegationResponse
+	require.NoError(t, fetchChainJSON(context.Background(), pinned, path, 100, &page))
+	require.Len(t, page.Delegations, 1)
+	assert.Equal(t, int64(1), laggingHits.Load())
+	assert.Equal(t, int64(1), healthyHits.Load())
+
+	// Unpinned, a 400 is the request's fault and is returned without trying elsewhere
+	unpinned := config.ChainConfig{ChainID: "lagging-test-2", LCDEndpoints: []string{lagging.URL, healthy.URL}}
+	err := fetchChainJSON(context.Background(), unpinned, path, 0, &page)
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	assert.Equal(t, int64(2), laggingHits.Load())
+	assert.Equal(t, int64(1), healthyHits.Load())
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/internal/repository"
+)
+
+// HealthService reports on the database and the data collected into it
+type HealthService struct {
+	health repository.HealthRepository
+}
+
+// creates a health service on top of a repository
+func NewHealthService(health repository.HealthRepository) *HealthService {
+	return &HealthService{health: health}
+}
+
+// reports whether the database answers
+func (s *HealthService) PingDatabase(ctx context.Context) error {
+	return s.health.Ping(ctx)
+}
+
+// counts the stored watchlist entries and delegation rows
+func (s *HealthService) RecordCounts(ctx context.Context) (repository.RecordCounts, error) {
+	return s.health.RecordCounts(ctx)
+}
+
+// returns the heartbeat of the latest collection run; false when nothing has been collected yet
+func (s *HealthService) LatestRun(ctx context.Context) (models.CollectionHeartbeat, bool, error) {
+	heartbeat, err := s.health.LatestHeartbeat(ctx)
+	if stderrors.Is(err, repository.ErrNotFound) {
+		return heartbeat, false, nil
+	}
+	return heartbeat, err == nil, err
+}
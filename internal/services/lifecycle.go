@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"cosmos-tracker/config"
+)
+
+// detaches in-flight work from shutdown so it can finish; once ctx is cancelled
+// the returned context is cancelled too after the configured drain deadline
+func drainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	drainCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	stop := context.AfterFunc(ctx, func() {
+		timer := time.AfterFunc(config.ShutdownTimeout(), cancel)
+		context.AfterFunc(drainCtx, func() { timer.Stop() })
+	})
+
+	return drainCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// waits for d or until ctx is cancelled, reporting whether the full duration elapsed
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainContextOutlivesShutdownUntilDeadline(t *testing.T) {
+	t.Setenv("SHUTDOWN_TIMEOUT", "50ms")
+
+	root, stop := context.WithCancel(context.Background())
+	drainCtx, cancel := drainContext(root)
+	defer cancel()
+
+	stop()
+
+	// In-flight work keeps running right after the signal...
+	assert.NoError(t, drainCtx.Err())
+
+	// ...and is cancelled once the drain deadline passes
+	select {
+	case <-drainCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("drain context was not cancelled after the shutdown timeout")
+	}
+}
+
+func TestSleepContextReturnsEarlyOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.False(t, sleepContext(ctx, time.Minute))
+	assert.True(t, sleepContext(context.Background(), time.Millisecond))
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/repository"
+)
+
+// MigrationService reports which schema migrations the storage backend has applied
+type MigrationService struct {
+	migrations repository.MigrationRepository
+}
+
+// creates a migration service on top of a repository
+func NewMigrationService(migrations repository.MigrationRepository) *MigrationService {
+	return &MigrationService{migrations: migrations}
+}
+
+// reports every migration of this build and whether it is applied, with the latest migration events
+func (s *MigrationService) FetchMigrations(ctx context.Context, historyLimit int) (dto.MigrationsDTO, error) {
+	statuses, err := s.migrations.MigrationStatuses(ctx)
+	if err != nil {
+		return dto.MigrationsDTO{}, err
+	}
+	history, err := s.migrations.MigrationHistory(ctx, historyLimit)
+	if err != nil {
+		return dto.MigrationsDTO{}, err
+	}
+
+	result := dto.MigrationsDTO{
+		UpToDate:   true,
+		Migrations: make([]dto.MigrationStatusDTO, len(statuses)),
+		History:    make([]dto.MigrationHistoryDTO, len(history)),
+	}
+	for i, s := range statuses {
+		result.Migrations[i] = dto.MigrationStatusDTO{
+			Version:  s.Version,
+			Name:     s.Name,
+			Checksum: s.Checksum,
+			Applied:  s.Applied,
+			Modified: s.Modified,
+			Unknown:  s.Unknown,
+		}
+		if s.Applied {
+			appliedAt := s.AppliedAt
+			result.Migrations[i].AppliedAt = &appliedAt
+		}
+		if !s.Applied || s.Modified || s.Unknown {
+			result.UpToDate = false
+		}
+	}
+	for i, h := range history {
+		result.History[i] = dto.MigrationHistoryDTO{
+			Version:    h.Version,
+			Name:       h.Name,
+			Direction:  h.Direction,
+			Checksum:   h.Checksum,
+			Status:     h.Status,
+			Error:      h.ErrorMessage,
+			Models:     h.Models,
+			MigratedAt: h.MigratedAt,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/models"
+)
+
+// name prefix of the monthly partitions of hourly_delegations, followed by the month as YYYYMM
+const hourlyPartitionPrefix = "hourly_delegations_p"
+
+// hourlyPartition is one month of hourly_delegations, holding rows from start up to end
+type hourlyPartition struct {
+	name       string
+	start, end time.Time
+}
+
+// identifies a watched validator across chains
+type validatorKey struct {
+	chainID, validatorAddress string
+}
+
+// returns the UTC start of the month holding t and of each of the following months ahead
+func partitionMonths(t time.Time, ahead int) []time.Time {
+	t = t.UTC()
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	months := make([]time.Time, ahead+1)
+	for i := range months {
+		months[i] = first.AddDate(0, i, 0)
+	}
+	return months
+}
+
+// parses the month a partition holds from its name; false for tables that are not monthly partitions
+func parseHourlyPartition(name string) (hourlyPartition, bool) {
+	if !strings.HasPrefix(name, hourlyPartitionPrefix) {
+		return hourlyPartition{}, false
+	}
+	start, err := time.Parse("200601", strings.TrimPrefix(name, hourlyPartitionPrefix))
+	if err != nil {
+		return hourlyPartition{}, false
+	}
+	return hourlyPartition{name: name, start: start, end: start.AddDate(0, 1, 0)}, true
+}
+
+// creates the partitions for the current month and the configured number of months ahead, so
+// snapshots never arrive for a month without one. Does nothing unless the table is partitioned.
+func (a *Aggregator) EnsureHourlyPartitions(ctx context.Context) error {
+	if !a.retention.HourlyPartitioned() {
+		return nil
+	}
+
+	months := partitionMonths(time.Now(), config.HourlyPartitionMonthsAhead())
+	for _, month := range months {
+		if err := a.retention.CreateHourlyPartition(ctx, month); err != nil {
+			return fmt.Errorf("creating hourly_delegations partition for %s: %w", month.Format("2006-01"), err)
+		}
+	}
+	log.Printf("🗂️ Hourly delegation partitions ready through %s", months[len(months)-1].Format("2006-01"))
+	return nil
+}
+
+// returns the monthly partitions attached to hourly_delegations, oldest first
+func (a *Aggregator) hourlyPartitions(ctx context.Context) ([]hourlyPartition, error) {
+	names, err := a.retention.HourlyPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]hourlyPartition, 0, len(names))
+	for _, name := range names {
+		if partition, ok := parseHourlyPartition(name); ok {
+			partitions = append(partitions, partition)
+		}
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].start.Before(partitions[j].start) })
+	return partitions, nil
+}
+
+// detaches and drops the oldest hourly_delegations partitions while every validator in them is
+// past its retention cutoff, which is far cheaper than deleting their rows. A partition stays when
+// it still holds the last row of a delegator who is staked as of the cutoff, since positions are
+// carried forward from it; older partitions are never skipped, so no earlier row can resurface as
+// a delegator's latest. The row-by-row pass prunes whatever remains. Returns the rows dropped.
+func (a *Aggregator) dropExpiredHourlyPartitions(ctx context.Context, retainFrom time.Time, cutoffs map[validatorKey]time.Time) (int64, error) {
+	partitions, err := a.hourlyPartitions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, partition := range partitions {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		if partition.end.After(retainFrom) {
+			break // still inside the retention window, as is everything after it
+		}
+
+		dropped, ok, err := a.dropHourlyPartition(ctx, partition, cutoffs)
+		total += dropped
+		if err != nil || !ok {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// drops one partition if it has expired for every validator with rows in it; false when it was kept
+func (a *Aggregator) dropHourlyPartition(ctx context.Context, partition hourlyPartition, cutoffs map[validatorKey]time.Time) (int64, bool, error) {
+	counts, err := a.retention.PartitionRows(ctx, partition.name)
+	if err != nil {
+		return 0, false, err
+	}
+
+	// Every validator in the partition must be watched and pruned past its end
+	var cutoff time.Time
+	for _, count := range counts {
+		entryCutoff, ok := cutoffs[validatorKey{count.ChainID, count.ValidatorAddress}]
+		if !ok || partition.end.After(entryCutoff) {
+			return 0, false, nil
+		}
+		if cutoff.IsZero() || entryCutoff.Before(cutoff) {
+			cutoff = entryCutoff
+		}
+	}
+
+	// A delegator still staked whose last row before the cutoff is here pins the partition
+	if len(counts) > 0 {
+		pinned, err := a.retention.PinnedPartitionRows(ctx, partition.name, partition.end, cutoff)
+		if err != nil {
+			return 0, false, err
+		}
+		if pinned > 0 {
+			return 0, false, nil
+		}
+	}
+
+	// Record the runs with the drop so later aggregation knows these days can't be rebuilt
+	var total int64
+	now := time.Now()
+	runs := make([]models.RetentionRun, len(counts))
+	for i, count := range counts {
+		runs[i] = models.RetentionRun{
+			ChainID:          count.ChainID,
+			ValidatorAddress: count.ValidatorAddress,
+			Table:            "hourly_delegations",
+			Cutoff:           partition.end,
+			RowsDeleted:      count.RowCount,
+			StartedAt:        now,
+			FinishedAt:       now,
+		}
+		total += count.RowCount
+	}
+	if err := a.retention.DropHourlyPartition(ctx, partition.name, runs); err != nil {
+		log.Printf("❌ Failed to drop hourly partition %s: %v", partition.name, err)
+		return 0, false, err
+	}
+
+	log.Printf("🧹 Dropped hourly partition %s with %d rows before %s", partition.name, total, partition.end.Format(DateLayout))
+	return total, true, nil
+}
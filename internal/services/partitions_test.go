@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/pkg/db"
+	"cosmos-tracker/pkg/numeric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionMonthsAndNames(t *testing.T) {
+	// Late on 31 December in New York is already January in UTC
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	months := partitionMonths(time.Date(2024, 12, 31, 22, 0, 0, 0, newYork), 2)
+	require.Len(t, months, 3)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), months[0])
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), months[2])
+
+	partition, ok := parseHourlyPartition("hourly_delegations_p202402")
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), partition.start)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), partition.end)
+
+	for _, name := range []string{"hourly_delegations_default", "hourly_delegations_p2024", "daily_delegations"} {
+		_, ok := parseHourlyPartition(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestAggregateDayReadsFromLastFullRun(t *testing.T) {
+	entry := useTestDB(t)
+	ctx := context.Background()
+	collector, _ := testServices()
+	watchlist := models.Watchlist{ID: uint(entry.ID), ChainID: entry.ChainID, ValidatorAddress: entry.ValidatorAddress}
+	today := startOfDay(time.Now())
+	yesterday := today.AddDate(0, 0, -1)
+
+	run := func(height int64, at time.Time, amounts map[string]string) {
+		require.NoError(t, collector.processEntryData(ctx, entry, delegationPage(t, amounts), entry.ValidatorAddress, blockRef{Height: height, Time: time.Now()}, testRun(height)))
+		if !at.IsZero() {
+			require.NoError(t, db.DB.Model(&models.HourlyDelegation{}).Where("block_height = ?", height).Update("timestamp", at).Error)
+			require.NoError(t, db.DB.Model(&models.CollectionHeartbeat{}).Where("block_height = ?", height).Update("timestamp", at).Error)
+		}
+	}
+
+	// Carol exits yesterday after the first run, Bob today
+	run(100, yesterday.Add(2*time.Hour), map[string]string{"cosmos1alice": "1000", "cosmos1bob": "500", "cosmos1carol": "300"})
+
+	// A stray row older than every full run stands in for data in partitions that are never read
+	require.NoError(t, db.DB.Create(&models.HourlyDelegation{
+		WatchlistID: watchlist.ID, ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress,
+		DelegatorAddress: "cosmos1dave", DelegationAmount: numeric.NewInt(900), BlockHeight: 90,
+		Timestamp: yesterday.AddDate(0, 0, -2),
+	}).Error)
+
+	run(101, yesterday.Add(10*time.Hour), map[string]string{"cosmos1alice": "1000", "cosmos1bob": "500"})
+	run(102, time.Time{}, map[string]string{"cosmos1alice": "1100"})
+
+	totals := func(day time.Time) map[string]string {
+		_, err := testAggregator().aggregateDay(ctx, watchlist, day)
+		require.NoError(t, err)
+		var daily []models.DailyDelegation
+		require.NoError(t, db.DB.Where("date = ?", calendarDate(day)).Find(&daily).Error)
+		amounts := make(map[string]string, len(daily))
+		for _, d := range daily {
+			amounts[d.DelegatorAddress] = d.TotalDelegation.String()
+		}
+		return amounts
+	}
+
+	// Yesterday's exit is reported even though it precedes the day's last full run
+	assert.Equal(t, map[string]string{"cosmos1alice": "1000", "cosmos1bob": "500", "cosmos1carol": "0"}, totals(yesterday))
+	assert.Equal(t, map[string]string{"cosmos1alice": "1100", "cosmos1bob": "0"}, totals(today))
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"cosmos-tracker/config"
+)
+
+// token bucket shared by every worker talking to the same upstream host
+type hostLimiter struct {
+	mu          sync.Mutex
+	rate        float64 // tokens added per second
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time // set from Retry-After, blocks all workers
+}
+
+// creates a limiter that starts with a full bucket
+func newHostLimiter(ratePerSecond float64, burst int) *hostLimiter {
+	return &hostLimiter{
+		rate:   ratePerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// blocks until a token is available or the context is done
+func (l *hostLimiter) Wait(ctx context.Context) error {
+	for {
+		delay := l.reserve(time.Now())
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// takes a token if one is available, otherwise returns how long to wait
+func (l *hostLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+
+	// Refill based on elapsed time, capped at the burst size
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// stops handing out tokens until the given time, e.g. after a Retry-After
+func (l *hostLimiter) PauseUntil(until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+		// Drain the bucket so workers resume at the steady rate, not a burst
+		l.tokens = 0
+		l.last = until
+	}
+}
+
+var (
+	hostLimiters   = make(map[string]*hostLimiter)
+	hostLimitersMu sync.Mutex
+)
+
+// returns the shared limiter for the host of the given URL
+func limiterFor(rawURL string) *hostLimiter {
+	host := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+
+	hostLimitersMu.Lock()
+	defer hostLimitersMu.Unlock()
+
+	limiter, ok := hostLimiters[host]
+	if !ok {
+		cfg := config.CollectorConfig()
+		limiter = newHostLimiter(cfg.RequestsPerSecond, cfg.Burst)
+		hostLimiters[host] = limiter
+	}
+	return limiter
+}
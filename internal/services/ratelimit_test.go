@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostLimiterBurstThenRate(t *testing.T) {
+	limiter := newHostLimiter(10, 2)
+	now := limiter.last
+
+	// The initial burst is served immediately
+	assert.Zero(t, limiter.reserve(now))
+	assert.Zero(t, limiter.reserve(now))
+
+	// The next token arrives after 1/rate seconds
+	delay := limiter.reserve(now)
+	assert.InDelta(t, float64(100*time.Millisecond), float64(delay), float64(time.Millisecond))
+
+	assert.Zero(t, limiter.reserve(now.Add(100*time.Millisecond)))
+}
+
+func TestHostLimiterPauseUntil(t *testing.T) {
+	limiter := newHostLimiter(100, 5)
+	now := limiter.last
+
+	limiter.PauseUntil(now.Add(2 * time.Second))
+
+	// Every caller is held back until the pause expires
+	assert.Equal(t, 2*time.Second, limiter.reserve(now))
+	assert.Equal(t, time.Second, limiter.reserve(now.Add(time.Second)))
+
+	// An earlier pause never shortens an existing one
+	limiter.PauseUntil(now.Add(time.Second))
+	assert.Equal(t, time.Second, limiter.reserve(now.Add(time.Second)))
+}
+
+func TestHostLimiterWaitHonoursContext(t *testing.T) {
+	limiter := newHostLimiter(1, 1)
+	limiter.PauseUntil(time.Now().Add(time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, limiter.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 24, 12, 0, 0, 0, time.UTC)
+
+	at, ok := parseRetryAfter("30", now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(30*time.Second), at)
+
+	at, ok = parseRetryAfter("Sun, 24 Mar 2024 12:01:00 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(time.Minute), at.UTC())
+
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/errors"
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/internal/repository"
+	"cosmos-tracker/pkg/numeric"
+)
+
+// Redelegation directions relative to a watched validator
+const (
+	RedelegationIn  = models.RedelegationIn
+	RedelegationOut = models.RedelegationOut
+)
+
+// Tx search tuning for redelegation discovery
+const (
+	RedelegationPageLimit    = 100
+	RedelegationScanMaxPages = 20 // caps the backfill of a single run
+)
+
+// API response structure for a tx event search
+type TxSearchResponse struct {
+	TxResponses []struct {
+		Height    string    `json:"height"`
+		TxHash    string    `json:"txhash"`
+		Code      int       `json:"code"`
+		Timestamp time.Time `json:"timestamp"`
+		Events    []TxEvent `json:"events"`
+	} `json:"tx_responses"`
+	Total string `json:"total"`
+}
+
+// ABCI event emitted by a transaction
+type TxEvent struct {
+	Type       string `json:"type"`
+	Attributes []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"attributes"`
+}
+
+// fetches and stores redelegations into and out of a watched validator since the last scanned height
+func (c *Collector) collectRedelegations(ctx context.Context, chain config.ChainConfig, entry dto.WatchlistEntry, block blockRef) error {
+	var found []models.Redelegation
+	var cursors []models.RedelegationScanCursor
+
+	for _, direction := range []string{RedelegationOut, RedelegationIn} {
+		since, err := c.redelegations.RedelegationCursor(ctx, entry.ChainID, entry.ValidatorAddress, direction)
+		if err != nil {
+			return err
+		}
+		if since >= block.Height {
+			continue
+		}
+
+		redelegations, scanned, err := searchRedelegations(ctx, chain, entry.ValidatorAddress, direction, since, block.Height)
+		if err != nil {
+			return fmt.Errorf("searching %s redelegations: %w", direction, err)
+		}
+		found = append(found, redelegations...)
+		cursors = append(cursors, models.RedelegationScanCursor{
+			ChainID:          entry.ChainID,
+			ValidatorAddress: entry.ValidatorAddress,
+			Direction:        direction,
+			ScannedHeight:    scanned,
+		})
+	}
+
+	if len(cursors) == 0 {
+		return nil
+	}
+
+	writeCtx, cancel := drainContext(ctx)
+	defer cancel()
+
+	saved, err := c.redelegations.SaveRedelegations(writeCtx, found, cursors)
+	if err != nil {
+		return err
+	}
+
+	if saved > 0 {
+		log.Printf("🔀 Recorded %d new redelegations for %s", saved, entry.ValidatorAddress)
+	}
+	return nil
+}
+
+// pages through tx search oldest-first above since, up to maxHeight. Returns the redelegations found
+// and the height up to which the scan is complete: maxHeight, or just below the last height seen
+// when the page cap stops the scan, since that height's transactions may continue on the next page.
+func searchRedelegations(ctx context.Context, chain config.ChainConfig, validatorAddress, direction string, since, maxHeight int64) ([]models.Redelegation, int64, error) {
+	attribute := "redelegate.source_validator"
+	if direction == RedelegationIn {
+		attribute = "redelegate.destination_validator"
+	}
+
+	var found []models.Redelegation
+	lastHeight := since
+	for page := 1; page <= RedelegationScanMaxPages; page++ {
+		result, err := fetchTxSearchPage(ctx, chain, attribute, validatorAddress, since, maxHeight, page)
+		if err != nil {
+			return nil, since, err
+		}
+
+		for _, tx := range result.TxResponses {
+			height, err := strconv.ParseInt(tx.Height, 10, 64)
+			if err != nil {
+				continue
+			}
+			if height <= since || height > maxHeight {
+				continue // outside the window; nodes ignoring the height filter return everything
+			}
+			lastHeight = max(lastHeight, height)
+			if tx.Code != 0 {
+				continue // failed transactions don't move stake
+			}
+
+			for i, event := range tx.Events {
+				if event.Type != "redelegate" {
+					continue
+				}
+				redelegation, ok := parseRedelegateEvent(chain, tx.TxHash, i, height, tx.Timestamp, event)
+				if !ok {
+					continue
+				}
+				if redelegation.SrcValidatorAddress != validatorAddress && redelegation.DstValidatorAddress != validatorAddress {
+					continue // another message in the same tx
+				}
+				if redelegation.DelegatorAddress == "" {
+					redelegation.DelegatorAddress = txSender(tx.Events)
+				}
+				found = append(found, redelegation)
+			}
+		}
+
+		if len(result.TxResponses) < RedelegationPageLimit {
+			return found, maxHeight, nil
+		}
+	}
+
+	scanned := max(lastHeight-1, since)
+	log.Printf("⚠️ Redelegation scan for %s (%s) stopped after %d pages at height %d; the rest is picked up next run",
+		validatorAddress, direction, RedelegationScanMaxPages, scanned)
+	return found, scanned, nil
+}
+
+// fetches one page of tx search results in (since, maxHeight], oldest first, falling back to the
+// pre-0.50 events parameter
+func fetchTxSearchPage(ctx context.Context, chain config.ChainConfig, attribute, validatorAddress string, since, maxHeight int64, page int) (TxSearchResponse, error) {
+	params := url.Values{}
+	params.Set("query", fmt.Sprintf("%s='%s' AND tx.height>%d AND tx.height<=%d", attribute, validatorAddress, since, maxHeight))
+	params.Set("order_by", "ORDER_BY_ASC")
+	params.Set("page", strconv.Itoa(page))
+	params.Set("limit", strconv.Itoa(RedelegationPageLimit))
+
+	var result TxSearchResponse
+	err := fetchChainJSON(ctx, chain, "/cosmos/tx/v1beta1/txs?"+params.Encode(), 0, &result)
+
+	var appErr *errors.AppError
+	if err != nil && stderrors.As(err, &appErr) && appErr.Code == http.StatusBadRequest {
+		params.Del("query")
+		params.Set("events", fmt.Sprintf("%s='%s'", attribute, validatorAddress))
+		params.Add("events", fmt.Sprintf("tx.height>=%d", since+1))
+		params.Add("events", fmt.Sprintf("tx.height<=%d", maxHeight))
+		result = TxSearchResponse{}
+		err = fetchChainJSON(ctx, chain, "/cosmos/tx/v1beta1/txs?"+params.Encode(), 0, &result)
+	}
+
+	return result, err
+}
+
+// builds a redelegation from the attributes of a redelegate event
+func parseRedelegateEvent(chain config.ChainConfig, txHash string, index int, height int64, timestamp time.Time, event TxEvent) (models.Redelegation, bool) {
+	redelegation := models.Redelegation{
+		ChainID:    chain.ChainID,
+		TxHash:     txHash,
+		EventIndex: index,
+		Height:     height,
+		Timestamp:  timestamp,
+	}
+
+	for _, attr := range event.Attributes {
+		switch attr.Key {
+		case "source_validator":
+			redelegation.SrcValidatorAddress = attr.Value
+		case "destination_validator":
+			redelegation.DstValidatorAddress = attr.Value
+		case "delegator":
+			redelegation.DelegatorAddress = attr.Value
+		case "completion_time":
+			if completion, err := time.Parse(time.RFC3339, attr.Value); err == nil {
+				redelegation.CompletionTime = completion
+			}
+		case "amount":
+			amount, denom, err := parseCoin(attr.Value)
+			if err != nil {
+				log.Printf("❌ Error parsing redelegation amount '%s': %v", attr.Value, err)
+				return redelegation, false
+			}
+			redelegation.Amount = amount
+			redelegation.Denom = denom
+		}
+	}
+
+	return redelegation, redelegation.SrcValidatorAddress != "" && redelegation.DstValidatorAddress != ""
+}
+
+// returns the sender of the first message event, used by chains that omit the delegator attribute
+func txSender(events []TxEvent) string {
+	for _, event := range events {
+		if event.Type != "message" {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if attr.Key == "sender" {
+				return attr.Value
+			}
+		}
+	}
+	return ""
+}
+
+// splits a coin string such as "1000uatom" into amount and denom
+func parseCoin(coin string) (numeric.Int, string, error) {
+	split := strings.IndexFunc(coin, func(r rune) bool { return r < '0' || r > '9' })
+	if split <= 0 {
+		return numeric.Int{}, "", fmt.Errorf("invalid coin %q", coin)
+	}
+
+	amount, err := numeric.ParseInt(coin[:split])
+	if err != nil {
+		return numeric.Int{}, "", err
+	}
+	return amount, coin[split:], nil
+}
+
+// RedelegationService serves stored redelegations of watched validators
+type RedelegationService struct {
+	redelegations repository.RedelegationRepository
+}
+
+// creates a redelegation service on top of a repository
+func NewRedelegationService(redelegations repository.RedelegationRepository) *RedelegationService {
+	return &RedelegationService{redelegations: redelegations}
+}
+
+// retrieves paginated redelegations into, out of, or both ways for a validator
+func (s *RedelegationService) FetchRedelegationsWithPagination(ctx context.Context, chainID, validatorAddress, direction string, page, limit int) ([]dto.RedelegationDTO, int64, error) {
+	redelegations, total, err := s.redelegations.Redelegations(ctx, repository.RedelegationQuery{
+		ChainID:          chainID,
+		ValidatorAddress: validatorAddress,
+		Direction:        direction,
+		Limit:            limit,
+		Offset:           (page - 1) * limit,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Convert to DTOs
+	result := make([]dto.RedelegationDTO, len(redelegations))
+	for i, r := range redelegations {
+		result[i] = dto.RedelegationDTO{
+			ID:                  r.ID,
+			ChainID:             r.ChainID,
+			TxHash:              r.TxHash,
+			DelegatorAddress:    r.DelegatorAddress,
+			SrcValidatorAddress: r.SrcValidatorAddress,
+			DstValidatorAddress: r.DstValidatorAddress,
+			Amount:              r.Amount,
+			Denom:               r.Denom,
+			CompletionTime:      r.CompletionTime,
+			Height:              r.Height,
+			Timestamp:           r.Timestamp,
+		}
+	}
+
+	return result, total, nil
+}
+
+// totals redelegated stake per counterpart validator, largest first
+func (s *RedelegationService) SummarizeRedelegations(ctx context.Context, chainID, validatorAddress, direction string) ([]dto.RedelegationCounterpartDTO, error) {
+	counterparts, err := s.redelegations.RedelegationSummary(ctx, chainID, validatorAddress, direction)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := make([]dto.RedelegationCounterpartDTO, len(counterparts))
+	for i, c := range counterparts {
+		summary[i] = dto.RedelegationCounterpartDTO{ValidatorAddress: c.ValidatorAddress, Amount: c.Amount, Count: c.Count}
+	}
+	return summary, nil
+}
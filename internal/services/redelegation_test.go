@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const txSearchFixture = `{
+  "tx_responses": [
+    {"height": "100", "txhash": "CCC", "code": 0, "timestamp": "2024-03-24T08:00:00Z", "events": []},
+    {"height": "110", "txhash": "BBB", "code": 5, "timestamp": "2024-03-24T09:00:00Z", "events": []},
+    {
+      "height": "120",
+      "txhash": "AAA",
+      "code": 0,
+      "timestamp": "2024-03-24T10:00:00Z",
+      "events": [
+        {"type": "message", "attributes": [{"key": "sender", "value": "cosmos1alice"}]},
+        {"type": "redelegate", "attributes": [
+          {"key": "source_validator", "value": "cosmosvaloper1watched"},
+          {"key": "destination_validator", "value": "cosmosvaloper1rival"},
+          {"key": "amount", "value": "2500000uatom"},
+          {"key": "completion_time", "value": "2024-04-14T10:00:00Z"}
+        ]},
+        {"type": "redelegate", "attributes": [
+          {"key": "source_validator", "value": "cosmosvaloper1other"},
+          {"key": "destination_validator", "value": "cosmosvaloper1rival"},
+          {"key": "amount", "value": "1uatom"}
+        ]}
+      ]
+    }
+  ],
+  "total": "3"
+}`
+
+func TestSearchRedelegationsScansAboveCursor(t *testing.T) {
+	var queries, orders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("query"))
+		orders = append(orders, r.URL.Query().Get("order_by"))
+		w.Write([]byte(txSearchFixture))
+	}))
+	defer server.Close()
+
+	chain := config.ChainConfig{ChainID: "redelegation-test-1", LCDEndpoints: []string{server.URL}}
+
+	found, scanned, err := searchRedelegations(context.Background(), chain, "cosmosvaloper1watched", RedelegationOut, 100, 150)
+	require.NoError(t, err)
+
+	// One short page completes the scan; height 100 was already scanned and is skipped
+	assert.Equal(t, []string{"redelegate.source_validator='cosmosvaloper1watched' AND tx.height>100 AND tx.height<=150"}, queries)
+	assert.Equal(t, []string{"ORDER_BY_ASC"}, orders)
+	assert.Equal(t, int64(150), scanned)
+
+	// Only the event involving the watched validator is kept, with the sender as delegator
+	require.Len(t, found, 1)
+	assert.Equal(t, "cosmos1alice", found[0].DelegatorAddress)
+	assert.Equal(t, "cosmosvaloper1rival", found[0].DstValidatorAddress)
+	assert.Equal(t, "2500000", found[0].Amount.String())
+	assert.Equal(t, "uatom", found[0].Denom)
+	assert.Equal(t, 1, found[0].EventIndex)
+	assert.Equal(t, int64(120), found[0].Height)
+	assert.Equal(t, 2024, found[0].CompletionTime.Year())
+}
+
+func TestParseCoin(t *testing.T) {
+	amount, denom, err := parseCoin("42ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2")
+	require.NoError(t, err)
+	assert.Equal(t, "42", amount.String())
+	assert.Equal(t, "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", denom)
+
+	_, _, err = parseCoin("uatom")
+	assert.Error(t, err)
+}
+
+func TestCollectRedelegationsResumesAfterPageCap(t *testing.T) {
+	// One redelegation out of the watched validator per height, more than a capped run can page through
+	const txCount = RedelegationScanMaxPages*RedelegationPageLimit + 250
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var since, until int64
+		fmt.Sscanf(r.URL.Query().Get("query"), "redelegate.source_validator='cosmosvaloper1watched' AND tx.height>%d AND tx.height<=%d", &since, &until)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		var txs []string
+		for height := since + 1 + int64((page-1)*limit); height <= min(until, txCount) && len(txs) < limit; height++ {
+			txs = append(txs, fmt.Sprintf(`{"height": "%d", "txhash": "TX%d", "code": 0, "timestamp": "2024-03-24T10:00:00Z", "events": [
+				{"type": "redelegate", "attributes": [
+					{"key": "source_validator", "value": "cosmosvaloper1watched"},
+					{"key": "destination_validator", "value": "cosmosvaloper1rival"},
+					{"key": "delegator", "value": "cosmos1alice"},
+					{"key": "amount", "value": "1uatom"}
+				]}
+			]}`, height, height))
+		}
+		fmt.Fprintf(w, `{"tx_responses": [%s]}`, strings.Join(txs, ","))
+	}))
+	defer server.Close()
+
+	chain := config.ChainConfig{ChainID: "redelegation-test-2", LCDEndpoints: []string{server.URL}}
+	entry := dto.WatchlistEntry{ChainID: chain.ChainID, ValidatorAddress: "cosmosvaloper1watched"}
+	repos := repository.NewMemoryStore().Repositories()
+	collector := NewCollector(repos)
+	ctx := context.Background()
+
+	stored := func() int64 {
+		_, total, err := repos.Redelegations.Redelegations(ctx, repository.RedelegationQuery{
+			ChainID: chain.ChainID, ValidatorAddress: entry.ValidatorAddress, Direction: RedelegationOut, Limit: 1,
+		})
+		require.NoError(t, err)
+		return total
+	}
+
+	// The first run stops at the cap, just below the last height it saw
+	require.NoError(t, collector.collectRedelegations(ctx, chain, entry, blockRef{Height: txCount}))
+	assert.Equal(t, int64(RedelegationScanMaxPages*RedelegationPageLimit), stored())
+	cursor, err := repos.Redelegations.RedelegationCursor(ctx, chain.ChainID, entry.ValidatorAddress, RedelegationOut)
+	require.NoError(t, err)
+	assert.Equal(t, int64(RedelegationScanMaxPages*RedelegationPageLimit-1), cursor)
+
+	// The next run resumes from the cursor and fills the rest of the gap
+	require.NoError(t, collector.collectRedelegations(ctx, chain, entry, blockRef{Height: txCount}))
+	assert.Equal(t, int64(txCount), stored())
+	cursor, err = repos.Redelegations.RedelegationCursor(ctx, chain.ChainID, entry.ValidatorAddress, RedelegationOut)
+	require.NoError(t, err)
+	assert.Equal(t, int64(txCount), cursor)
+}
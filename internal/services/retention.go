@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/models"
+)
+
+// hourly tables that retention may prune once their days are aggregated; hourly_delegations comes
+// first, as its cutoffs decide which of its partitions may be dropped
+var retentionTables = []string{"hourly_delegations", "hourly_validator_stats", "collection_heartbeats"}
+
+// deletes hourly rows older than the configured retention window. Each entry is only pruned up to
+// the day after its last daily row, so hours that were never rolled up are always kept. On Postgres
+// whole monthly partitions of hourly_delegations are dropped first where possible.
+func (a *Aggregator) PruneHourlyData(ctx context.Context) error {
+	cfg := config.RetentionConfig()
+	if cfg.HourlyDays == 0 {
+		return nil // retention disabled, keep everything
+	}
+	retainFrom := startOfDay(time.Now()).AddDate(0, 0, -cfg.HourlyDays)
+
+	watchlistItems, err := a.watchlist.ListWatchlist(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	if a.retention.HourlyPartitioned() {
+		cutoffs := make(map[validatorKey]time.Time, len(watchlistItems))
+		for _, watchlist := range watchlistItems {
+			cutoff, ok, err := a.retentionCutoff(ctx, watchlist, retentionTables[0], retainFrom)
+			if err != nil {
+				return err
+			}
+			if ok {
+				cutoffs[validatorKey{watchlist.ChainID, watchlist.ValidatorAddress}] = cutoff
+			}
+		}
+
+		dropped, err := a.dropExpiredHourlyPartitions(ctx, retainFrom, cutoffs)
+		total += dropped
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, watchlist := range watchlistItems {
+		for _, table := range retentionTables {
+			cutoff, ok, err := a.retentionCutoff(ctx, watchlist, table, retainFrom)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue // nothing rolled up yet, so nothing may be deleted
+			}
+
+			deleted, err := a.pruneHourlyTable(ctx, watchlist, table, cutoff, cfg.BatchSize)
+			total += deleted
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Printf("🧹 Retention pruned %d hourly rows older than %s", total, retainFrom.Format(DateLayout))
+	return nil
+}
+
+// returns the time before which an entry's rows of a table may be pruned: the start of the retention
+// window, or the first day not yet rolled up if earlier; false when nothing has been rolled up
+func (a *Aggregator) retentionCutoff(ctx context.Context, watchlist models.Watchlist, table string, retainFrom time.Time) (time.Time, bool, error) {
+	rolledUp, ok, err := a.retention.LatestRolledUpDate(ctx, table, watchlist.ChainID, watchlist.ValidatorAddress)
+	if err != nil || !ok {
+		return time.Time{}, false, err
+	}
+
+	// The first day not rolled up is the day after the latest daily row
+	aggregatedUntil := dayFromCalendarDate(rolledUp).AddDate(0, 0, 1)
+	if aggregatedUntil.Before(retainFrom) {
+		return aggregatedUntil, true, nil
+	}
+	return retainFrom, true, nil
+}
+
+// deletes one entry's rows older than cutoff in batches and records the pass when it deleted anything or failed
+func (a *Aggregator) pruneHourlyTable(ctx context.Context, watchlist models.Watchlist, table string, cutoff time.Time, batchSize int) (int64, error) {
+	run := models.RetentionRun{
+		ChainID:          watchlist.ChainID,
+		ValidatorAddress: watchlist.ValidatorAddress,
+		Table:            table,
+		Cutoff:           cutoff,
+		StartedAt:        time.Now(),
+	}
+
+	deleted, err := a.retention.PruneHourly(ctx, table, watchlist.ChainID, watchlist.ValidatorAddress, cutoff, batchSize)
+	run.RowsDeleted = deleted
+	run.FinishedAt = time.Now()
+
+	if err != nil {
+		run.ErrorMessage = err.Error()
+		log.Printf("❌ Retention failed for %s of [%s] %s: %v", table, watchlist.ChainID, watchlist.ValidatorAddress, err)
+	}
+	if run.RowsDeleted == 0 && err == nil {
+		return 0, nil
+	}
+
+	// Record without ctx's cancellation so a pass interrupted by shutdown is still logged
+	if recordErr := a.retention.SaveRetentionRun(context.WithoutCancel(ctx), &run); recordErr != nil {
+		log.Printf("⚠️ Failed to record retention run: %v", recordErr)
+	}
+	log.Printf("🧹 Pruned %d rows from %s for [%s] %s before %s",
+		run.RowsDeleted, table, watchlist.ChainID, watchlist.ValidatorAddress, cutoff.Format(DateLayout))
+	return run.RowsDeleted, err
+}
+
+// retrieves paginated retention runs, newest first
+func (a *Aggregator) FetchRetentionRunsWithPagination(ctx context.Context, page, limit int) ([]dto.RetentionRunDTO, int64, error) {
+	runs, total, err := a.retention.RetentionRuns(ctx, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]dto.RetentionRunDTO, len(runs))
+	for i, r := range runs {
+		result[i] = dto.RetentionRunDTO{
+			ChainID:          r.ChainID,
+			ValidatorAddress: r.ValidatorAddress,
+			Table:            r.Table,
+			Cutoff:           r.Cutoff,
+			RowsDeleted:      r.RowsDeleted,
+			Error:            r.ErrorMessage,
+			StartedAt:        r.StartedAt,
+			FinishedAt:       r.FinishedAt,
+		}
+	}
+	return result, total, nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/internal/repository"
+	"cosmos-tracker/pkg/db"
+	"cosmos-tracker/pkg/numeric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneHourlyDataKeepsUnaggregatedHours(t *testing.T) {
+	t.Setenv("RETENTION_HOURLY_DAYS", "30")
+	t.Setenv("RETENTION_BATCH_SIZE", "2") // force several delete batches
+	entry := useTestDB(t)
+	ctx := context.Background()
+	today := startOfDay(time.Now())
+
+	var watchlist models.Watchlist
+	require.NoError(t, db.DB.First(&watchlist, entry.ID).Error)
+
+	// Three hourly snapshots on each of two old days and one recent day
+	for _, daysAgo := range []int{40, 35, 2} {
+		day := today.AddDate(0, 0, -daysAgo)
+		for hour := 1; hour <= 3; hour++ {
+			require.NoError(t, db.DB.Create(&models.HourlyDelegation{
+				WatchlistID:      watchlist.ID,
+				ChainID:          entry.ChainID,
+				ValidatorAddress: entry.ValidatorAddress,
+				DelegatorAddress: "cosmos1alice",
+				DelegationAmount: numeric.NewInt(int64(100 * hour)),
+				Timestamp:        day.Add(time.Duration(hour) * time.Hour),
+			}).Error)
+		}
+	}
+
+	countHours := func() int64 {
+		var count int64
+		require.NoError(t, db.DB.Model(&models.HourlyDelegation{}).Count(&count).Error)
+		return count
+	}
+
+	// Nothing aggregated yet, so nothing may be pruned despite being past retention
+	require.NoError(t, testAggregator().PruneHourlyData(ctx))
+	assert.Equal(t, int64(9), countHours())
+
+	// Only the oldest day is rolled up; the day 35 days ago must survive, and so does
+	// alice's last row before the cutoff, the base her position is carried forward from
+	_, err := testAggregator().aggregateDay(ctx, watchlist, today.AddDate(0, 0, -40))
+	require.NoError(t, err)
+	require.NoError(t, testAggregator().PruneHourlyData(ctx))
+	assert.Equal(t, int64(7), countHours())
+
+	// Once everything is aggregated, only hours inside the window remain
+	for _, daysAgo := range []int{35, 2} {
+		_, err := testAggregator().aggregateDay(ctx, watchlist, today.AddDate(0, 0, -daysAgo))
+		require.NoError(t, err)
+	}
+	require.NoError(t, testAggregator().PruneHourlyData(ctx))
+	assert.Equal(t, int64(4), countHours())
+
+	var daily int64
+	require.NoError(t, db.DB.Model(&models.DailyDelegation{}).Count(&daily).Error)
+	assert.Equal(t, int64(3), daily, "daily rows are never pruned")
+
+	runs, total, err := testAggregator().FetchRetentionRunsWithPagination(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+	for i, deleted := range []int64{3, 2} { // newest first
+		assert.Equal(t, "hourly_delegations", runs[i].Table)
+		assert.Equal(t, deleted, runs[i].RowsDeleted)
+		assert.Empty(t, runs[i].Error)
+	}
+
+	// Pruned days can no longer be rebuilt, so re-aggregating one leaves its daily rows alone
+	rows, err := testAggregator().aggregateDay(ctx, watchlist, today.AddDate(0, 0, -40))
+	require.NoError(t, err)
+	assert.Zero(t, rows)
+	require.NoError(t, db.DB.Model(&models.DailyDelegation{}).Count(&daily).Error)
+	assert.Equal(t, int64(3), daily)
+}
+
+func TestPruneHourlyDataDisabledByDefault(t *testing.T) {
+	entry := useTestDB(t)
+
+	require.NoError(t, db.DB.Create(&models.HourlyDelegation{
+		WatchlistID:      uint(entry.ID),
+		ChainID:          entry.ChainID,
+		ValidatorAddress: entry.ValidatorAddress,
+		DelegatorAddress: "cosmos1alice",
+		DelegationAmount: numeric.NewInt(1),
+		Timestamp:        time.Now().AddDate(-1, 0, 0),
+	}).Error)
+	require.NoError(t, db.DB.Create(&models.DailyDelegation{
+		WatchlistID:      uint(entry.ID),
+		ChainID:          entry.ChainID,
+		ValidatorAddress: entry.ValidatorAddress,
+		DelegatorAddress: "cosmos1alice",
+		Date:             calendarDate(startOfDay(time.Now()).AddDate(0, 0, -1)),
+	}).Error)
+
+	require.NoError(t, testAggregator().PruneHourlyData(context.Background()))
+
+	var count int64
+	require.NoError(t, db.DB.Model(&models.HourlyDelegation{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestAggregateAndPruneOnMemoryStorage(t *testing.T) {
+	t.Setenv("RETENTION_HOURLY_DAYS", "30")
+	ctx := context.Background()
+	today := startOfDay(time.Now())
+	repos := repository.NewMemoryStore().Repositories()
+
+	watchlist := models.Watchlist{ChainID: "cosmoshub-4", ValidatorAddress: "cosmosvaloper1watched"}
+	require.NoError(t, repos.Watchlist.CreateWatchlist(ctx, &watchlist))
+
+	// One run on each of two old days and one recent day
+	for i, daysAgo := range []int{40, 35, 2} {
+		at := today.AddDate(0, 0, -daysAgo).Add(time.Hour)
+		require.NoError(t, repos.Snapshots.SaveSnapshot(ctx, repository.Snapshot{
+			Delegations: []models.HourlyDelegation{{
+				WatchlistID: watchlist.ID, ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress,
+				DelegatorAddress: "cosmos1alice", DelegationAmount: numeric.NewInt(int64(100 * (i + 1))), Timestamp: at,
+			}},
+			Stats: models.HourlyValidatorStats{
+				ValidatorStats: models.ValidatorStats{ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress},
+				Timestamp:      at,
+			},
+			Heartbeat: models.CollectionHeartbeat{
+				ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, RunID: strconv.Itoa(i), Timestamp: at,
+			},
+		}))
+	}
+
+	aggregator := NewAggregator(repos)
+	require.NoError(t, aggregator.AggregateDailyDelegations(ctx))
+	require.NoError(t, aggregator.PruneHourlyData(ctx))
+
+	// Alice's row from 35 days ago is her last before the cutoff, so it stays
+	hourly, total, err := repos.Delegations.HourlyDelegations(ctx, repository.DelegationQuery{ChainID: watchlist.ChainID, ValidatorAddress: watchlist.ValidatorAddress, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "300", hourly[0].DelegationAmount.String())
+
+	runs, total, err := aggregator.FetchRetentionRunsWithPagination(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	deleted := make(map[string]int64, len(runs))
+	for _, r := range runs {
+		deleted[r.Table] = r.RowsDeleted
+	}
+	assert.Equal(t, map[string]int64{"hourly_delegations": 1, "hourly_validator_stats": 2, "collection_heartbeats": 2}, deleted)
+
+	// Pruned days are left alone when re-aggregated
+	rows, err := aggregator.aggregateDay(ctx, watchlist, today.AddDate(0, 0, -40))
+	require.NoError(t, err)
+	assert.Zero(t, rows)
+	rows, err = aggregator.aggregateDay(ctx, watchlist, today.AddDate(0, 0, -2))
+	require.NoError(t, err)
+	assert.Equal(t, 1, rows)
+}
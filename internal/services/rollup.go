@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/internal/repository"
+)
+
+// returns the Monday starting the ISO week that contains day
+func isoWeekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// returns the first day of the calendar month that contains day
+func monthStart(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+}
+
+// formats the human-readable name of a period
+func periodLabel(period string, start time.Time) string {
+	switch period {
+	case models.PeriodWeek:
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case models.PeriodMonth:
+		return start.Format("2006-01")
+	default:
+		return start.Format(DateLayout)
+	}
+}
+
+// describes a rollup period running from start up to end
+func rollupPeriod(period string, start, end time.Time) repository.RollupPeriod {
+	return repository.RollupPeriod{Period: period, Start: start, End: end, Label: periodLabel(period, start)}
+}
+
+// describes the aggregation of one day, whose rollups are rebuilt with those of its ISO week and month
+func dayAggregation(watchlist models.Watchlist, day time.Time) repository.DayAggregation {
+	week, month := isoWeekStart(day), monthStart(day)
+	return repository.DayAggregation{
+		Watchlist: watchlist,
+		Timezone:  config.AggregationLocation().String(),
+		Day:       rollupPeriod(models.PeriodDay, day, day.AddDate(0, 0, 1)),
+		Periods: []repository.RollupPeriod{
+			rollupPeriod(models.PeriodWeek, week, week.AddDate(0, 0, 7)),
+			rollupPeriod(models.PeriodMonth, month, month.AddDate(0, 1, 0)),
+		},
+	}
+}
+
+// retrieves paginated rollups of one period for a validator, newest period first
+func (s *DelegationService) FetchDelegationRollupsWithPagination(ctx context.Context, period, chainID, validatorAddress string, page, limit int) ([]dto.DelegationRollupDTO, int64, error) {
+	rollups, total, err := s.delegations.Rollups(ctx, period, delegationQuery(chainID, validatorAddress, "", page, limit, dto.DelegationFilter{}))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Convert to DTOs
+	result := make([]dto.DelegationRollupDTO, len(rollups))
+	for i, r := range rollups {
+		result[i] = dto.DelegationRollupDTO{
+			ChainID:          r.ChainID,
+			ValidatorAddress: r.ValidatorAddress,
+			DelegatorAddress: r.DelegatorAddress,
+			Period:           period,
+			Label:            r.Label,
+			PeriodStart:      r.PeriodStart.UTC().Format(DateLayout),
+			PeriodEnd:        r.PeriodEnd.UTC().Format(DateLayout),
+			Timezone:         r.Timezone,
+			Open:             r.OpenAmount,
+			Close:            r.CloseAmount,
+			Min:              r.MinAmount,
+			Max:              r.MaxAmount,
+			NetChange:        r.NetChange,
+			OpenHeight:       r.OpenHeight,
+			CloseHeight:      r.CloseHeight,
+			Snapshots:        r.Snapshots,
+		}
+	}
+
+	return result, total, nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/pkg/db"
+	"cosmos-tracker/pkg/numeric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodBoundaries(t *testing.T) {
+	sunday := time.Date(2024, 12, 29, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 12, 23, 0, 0, 0, 0, time.UTC), isoWeekStart(sunday))
+	assert.Equal(t, sunday.AddDate(0, 0, 1), isoWeekStart(sunday.AddDate(0, 0, 1)))
+
+	// The ISO week of Monday 30 December 2024 belongs to 2025
+	assert.Equal(t, "2025-W01", periodLabel(models.PeriodWeek, time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2024-12", periodLabel(models.PeriodMonth, monthStart(sunday)))
+}
+
+func TestRollupsTrackOpenCloseMinMax(t *testing.T) {
+	entry := useTestDB(t)
+	ctx := context.Background()
+	_, delegations := testServices()
+	watchlist := models.Watchlist{ID: uint(entry.ID), ChainID: entry.ChainID, ValidatorAddress: entry.ValidatorAddress}
+
+	// Two days of the same ISO week and month: 100 -> 300 -> 50 on Monday, 50 -> 80 on Tuesday
+	monday := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	hours := []struct {
+		day    time.Time
+		amount int64
+		change int64
+	}{
+		{monday, 100, 100},
+		{monday, 300, 200},
+		{monday, 50, -250},
+		{monday.AddDate(0, 0, 1), 50, 0},
+		{monday.AddDate(0, 0, 1), 80, 30},
+	}
+	for i, h := range hours {
+		require.NoError(t, db.DB.Create(&models.HourlyDelegation{
+			WatchlistID:      watchlist.ID,
+			ChainID:          watchlist.ChainID,
+			ValidatorAddress: watchlist.ValidatorAddress,
+			DelegatorAddress: "cosmos1alice",
+			DelegationAmount: numeric.NewInt(h.amount),
+			ChangeAmount:     numeric.NewInt(h.change),
+			BlockHeight:      int64(100 + i),
+			Timestamp:        h.day.Add(time.Duration(i+1) * time.Hour),
+		}).Error)
+	}
+
+	for _, day := range []time.Time{monday, monday.AddDate(0, 0, 1)} {
+		_, err := testAggregator().aggregateDay(ctx, watchlist, day)
+		require.NoError(t, err)
+	}
+
+	days, total, err := delegations.FetchDelegationRollupsWithPagination(ctx, models.PeriodDay, entry.ChainID, entry.ValidatorAddress, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+	assert.Equal(t, "2024-05-06", days[1].PeriodStart)
+	assert.Equal(t, "100", days[1].Open.String())
+	assert.Equal(t, "50", days[1].Close.String())
+	assert.Equal(t, "300", days[1].Max.String())
+	assert.Equal(t, int64(3), days[1].Snapshots)
+
+	weeks, total, err := delegations.FetchDelegationRollupsWithPagination(ctx, models.PeriodWeek, entry.ChainID, entry.ValidatorAddress, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	week := weeks[0]
+	assert.Equal(t, "2024-W19", week.Label)
+	assert.Equal(t, "2024-05-12", week.PeriodEnd)
+	assert.Equal(t, "100", week.Open.String())
+	assert.Equal(t, "80", week.Close.String())
+	assert.Equal(t, "50", week.Min.String())
+	assert.Equal(t, "300", week.Max.String())
+	assert.Equal(t, "80", week.NetChange.String())
+	assert.Equal(t, int64(5), week.Snapshots)
+	assert.Equal(t, int64(100), week.OpenHeight)
+	assert.Equal(t, int64(104), week.CloseHeight)
+
+	months, _, err := delegations.FetchDelegationRollupsWithPagination(ctx, models.PeriodMonth, entry.ChainID, entry.ValidatorAddress, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, months, 1)
+	assert.Equal(t, "2024-05", months[0].Label)
+	assert.Equal(t, "2024-05-31", months[0].PeriodEnd)
+	assert.Equal(t, "80", months[0].Close.String())
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/pkg/numeric"
+)
+
+// accumulates validator-level totals while a snapshot is processed
+type flowTotals struct {
+	models.ValidatorStats
+}
+
+// starts empty totals for one validator
+func newFlowTotals(watchlist models.Watchlist, height int64) *flowTotals {
+	return &flowTotals{ValidatorStats: models.ValidatorStats{
+		WatchlistID:      watchlist.ID,
+		ChainID:          watchlist.ChainID,
+		ValidatorAddress: watchlist.ValidatorAddress,
+		BlockHeight:      height,
+	}}
+}
+
+// counts a delegator holding stake after the run, with its change since the previous run
+func (t *flowTotals) addHolding(amount, change numeric.Int, joined bool) {
+	t.TotalDelegated = t.TotalDelegated.Add(amount)
+	t.DelegatorCount++
+	if joined {
+		t.NewDelegators++
+	}
+	t.addChange(change)
+}
+
+// counts a delegator that fully undelegated since the previous run
+func (t *flowTotals) addExit(previousAmount numeric.Int) {
+	t.ExitedDelegators++
+	t.addChange(previousAmount.Neg())
+}
+
+// splits a change into gross inflow or outflow
+func (t *flowTotals) addChange(change numeric.Int) {
+	switch change.Sign() {
+	case 1:
+		t.Inflow = t.Inflow.Add(change)
+	case -1:
+		t.Outflow = t.Outflow.Add(change.Abs())
+	}
+	t.NetFlow = t.Inflow.Sub(t.Outflow)
+}
+
+// retrieves paginated per-run validator stats, newest first
+func (s *ValidatorService) FetchHourlyValidatorStatsWithPagination(ctx context.Context, chainID, validatorAddress string, page, limit int) ([]dto.ValidatorStatsDTO, int64, error) {
+	rows, total, err := s.validators.HourlyValidatorStats(ctx, pageQuery(chainID, validatorAddress, page, limit))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]dto.ValidatorStatsDTO, len(rows))
+	for i, r := range rows {
+		timestamp := r.Timestamp
+		result[i] = toValidatorStatsDTO(r.ValidatorStats)
+		result[i].Timestamp = &timestamp
+	}
+	return result, total, nil
+}
+
+// retrieves paginated daily validator stats, newest first
+func (s *ValidatorService) FetchDailyValidatorStatsWithPagination(ctx context.Context, chainID, validatorAddress string, page, limit int) ([]dto.ValidatorStatsDTO, int64, error) {
+	rows, total, err := s.validators.DailyValidatorStats(ctx, pageQuery(chainID, validatorAddress, page, limit))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]dto.ValidatorStatsDTO, len(rows))
+	for i, r := range rows {
+		result[i] = toValidatorStatsDTO(r.ValidatorStats)
+		result[i].Date = r.Date.UTC().Format(DateLayout)
+		result[i].Timezone = r.Timezone
+	}
+	return result, total, nil
+}
+
+// converts validator stats to their API representation
+func toValidatorStatsDTO(s models.ValidatorStats) dto.ValidatorStatsDTO {
+	return dto.ValidatorStatsDTO{
+		ChainID:          s.ChainID,
+		ValidatorAddress: s.ValidatorAddress,
+		BlockHeight:      s.BlockHeight,
+		TotalDelegated:   s.TotalDelegated,
+		DelegatorCount:   s.DelegatorCount,
+		NewDelegators:    s.NewDelegators,
+		ExitedDelegators: s.ExitedDelegators,
+		Inflow:           s.Inflow,
+		Outflow:          s.Outflow,
+		NetFlow:          s.NetFlow,
+	}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/internal/repository"
+	"cosmos-tracker/pkg/numeric"
+)
+
+// API response structure for a validator's unbonding delegations
+type UnbondingResponse struct {
+	UnbondingResponses []struct {
+		DelegatorAddress string `json:"delegator_address"`
+		ValidatorAddress string `json:"validator_address"`
+		Entries          []struct {
+			CreationHeight string    `json:"creation_height"`
+			CompletionTime time.Time `json:"completion_time"`
+			InitialBalance string    `json:"initial_balance"`
+			Balance        string    `json:"balance"`
+		} `json:"entries"`
+	} `json:"unbonding_responses"`
+	Pagination LCDPagination `json:"pagination,omitempty"`
+}
+
+// walks every page of a validator's unbonding delegations at a pinned height
+func fetchAllUnbondings(ctx context.Context, chain config.ChainConfig, entry dto.WatchlistEntry, height int64) ([]models.UnbondingDelegation, int, error) {
+	path := fmt.Sprintf("/cosmos/staking/v1beta1/validators/%s/unbonding_delegations", entry.ValidatorAddress)
+
+	var unbondings []models.UnbondingDelegation
+	pages, _, err := walkPages(path, func(pagePath string) (LCDPagination, error) {
+		var page UnbondingResponse
+		if err := fetchChainJSON(ctx, chain, pagePath, height, &page); err != nil {
+			return LCDPagination{}, err
+		}
+
+		for _, response := range page.UnbondingResponses {
+			for _, e := range response.Entries {
+				creationHeight, err := strconv.ParseInt(e.CreationHeight, 10, 64)
+				if err != nil {
+					log.Printf("❌ Error parsing unbonding creation height '%s': %v", e.CreationHeight, err)
+					continue
+				}
+				initialBalance, err := numeric.ParseInt(e.InitialBalance)
+				if err != nil {
+					log.Printf("❌ Error parsing unbonding initial balance '%s': %v", e.InitialBalance, err)
+					continue
+				}
+				balance, err := numeric.ParseInt(e.Balance)
+				if err != nil {
+					log.Printf("❌ Error parsing unbonding balance '%s': %v", e.Balance, err)
+					continue
+				}
+
+				unbondings = append(unbondings, models.UnbondingDelegation{
+					WatchlistID:      uint(entry.ID),
+					ChainID:          entry.ChainID,
+					ValidatorAddress: entry.ValidatorAddress,
+					DelegatorAddress: response.DelegatorAddress,
+					CreationHeight:   creationHeight,
+					CompletionTime:   e.CompletionTime,
+					InitialBalance:   initialBalance,
+					Balance:          balance,
+					Status:           models.UnbondingPending,
+					LastSeenHeight:   height,
+				})
+			}
+		}
+		return page.Pagination, nil
+	})
+
+	return unbondings, pages, err
+}
+
+// upserts the current unbonding queue and settles entries that left it
+func (c *Collector) storeUnbondings(ctx context.Context, entry dto.WatchlistEntry, unbondings []models.UnbondingDelegation, block blockRef) error {
+	settlement, err := c.unbondings.SaveUnbondings(ctx, entry.ChainID, entry.ValidatorAddress, unbondings, block.Height, block.Time)
+	if err != nil {
+		return err
+	}
+
+	if settlement.Completed > 0 || settlement.Cancelled > 0 {
+		log.Printf("🔓 Settled unbondings for %s: %d completed, %d cancelled",
+			entry.ValidatorAddress, settlement.Completed, settlement.Cancelled)
+	}
+	return nil
+}
+
+// UnbondingService serves the stored unbonding queues of watched validators
+type UnbondingService struct {
+	unbondings repository.UnbondingRepository
+}
+
+// creates an unbonding service on top of a repository
+func NewUnbondingService(unbondings repository.UnbondingRepository) *UnbondingService {
+	return &UnbondingService{unbondings: unbondings}
+}
+
+// builds the repository query for pending unbondings completing within the next days (all when days is 0)
+func pendingUnbondings(chainID, validatorAddress string, days int, now time.Time) repository.UnbondingQuery {
+	query := repository.UnbondingQuery{ChainID: chainID, ValidatorAddress: validatorAddress, After: now, Limit: -1}
+	if days > 0 {
+		query.Until = now.AddDate(0, 0, days)
+	}
+	return query
+}
+
+// retrieves paginated pending unbondings ordered by completion date
+func (s *UnbondingService) FetchUnbondingsWithPagination(ctx context.Context, chainID, validatorAddress string, days, page, limit int) ([]dto.UnbondingDelegationDTO, int64, error) {
+	query := pendingUnbondings(chainID, validatorAddress, days, time.Now())
+	query.Limit, query.Offset = limit, (page-1)*limit
+
+	unbondings, total, err := s.unbondings.PendingUnbondings(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Convert to DTOs
+	result := make([]dto.UnbondingDelegationDTO, len(unbondings))
+	for i, u := range unbondings {
+		result[i] = toUnbondingDTO(u)
+	}
+
+	return result, total, nil
+}
+
+// sums pending unbondings per completion day over the next days
+func (s *UnbondingService) ForecastUnbondings(ctx context.Context, chainID, validatorAddress string, days int) (dto.UnbondingForecastDTO, error) {
+	forecast := dto.UnbondingForecastDTO{
+		ChainID:          chainID,
+		ValidatorAddress: validatorAddress,
+		Days:             days,
+		Timezone:         config.AggregationLocation().String(),
+		Daily:            []dto.UnbondingForecastDayDTO{},
+	}
+
+	unbondings, _, err := s.unbondings.PendingUnbondings(ctx, pendingUnbondings(chainID, validatorAddress, days, time.Now()))
+	if err != nil {
+		return forecast, err
+	}
+
+	// Bucket by completion day in the aggregation timezone, like the daily rows; rows are already in date order
+	for _, u := range unbondings {
+		date := startOfDay(u.CompletionTime).Format(DateLayout)
+		last := len(forecast.Daily) - 1
+		if last < 0 || forecast.Daily[last].Date != date {
+			forecast.Daily = append(forecast.Daily, dto.UnbondingForecastDayDTO{Date: date})
+			last++
+		}
+		forecast.Daily[last].Balance = forecast.Daily[last].Balance.Add(u.Balance)
+		forecast.Daily[last].Entries++
+		forecast.TotalBalance = forecast.TotalBalance.Add(u.Balance)
+		forecast.TotalEntries++
+	}
+
+	return forecast, nil
+}
+
+// converts an unbonding model to its API representation
+func toUnbondingDTO(u models.UnbondingDelegation) dto.UnbondingDelegationDTO {
+	return dto.UnbondingDelegationDTO{
+		ID:               u.ID,
+		ChainID:          u.ChainID,
+		ValidatorAddress: u.ValidatorAddress,
+		DelegatorAddress: u.DelegatorAddress,
+		CreationHeight:   u.CreationHeight,
+		CompletionTime:   u.CompletionTime,
+		InitialBalance:   u.InitialBalance,
+		Balance:          u.Balance,
+		Status:           u.Status,
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/internal/repository"
+	"cosmos-tracker/pkg/db"
+	"cosmos-tracker/pkg/numeric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// builds a pending unbonding entry of the test validator
+func unbondingEntry(delegator string, creationHeight int64, completion time.Time, balance int64) models.UnbondingDelegation {
+	return models.UnbondingDelegation{
+		WatchlistID:      1,
+		ChainID:          "cosmoshub-4",
+		ValidatorAddress: "cosmosvaloper1watched",
+		DelegatorAddress: delegator,
+		CreationHeight:   creationHeight,
+		CompletionTime:   completion,
+		InitialBalance:   numeric.NewInt(balance),
+		Balance:          numeric.NewInt(balance),
+		Status:           models.UnbondingPending,
+	}
+}
+
+// marks entries as listed by the chain at a height, as fetchAllUnbondings does
+func listedAt(height int64, entries ...models.UnbondingDelegation) []models.UnbondingDelegation {
+	for i := range entries {
+		entries[i].LastSeenHeight = height
+	}
+	return entries
+}
+
+func TestStoreUnbondingsSettlesEntriesThatLeaveTheQueue(t *testing.T) {
+	entry := useTestDB(t)
+	ctx := context.Background()
+	collector, _ := testServices()
+
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	alice := unbondingEntry("cosmos1alice", 90, t0.Add(time.Hour), 100)    // matures before the next run
+	bob := unbondingEntry("cosmos1bob", 91, t0.AddDate(0, 0, 21), 200)     // still queued next run
+	carol := unbondingEntry("cosmos1carol", 92, t0.AddDate(0, 0, 14), 300) // cancelled before maturing
+	require.NoError(t, collector.storeUnbondings(ctx, entry, listedAt(100, alice, bob, carol), blockRef{Height: 100, Time: t0}))
+
+	// Two hours later only Bob is still listed
+	require.NoError(t, collector.storeUnbondings(ctx, entry, listedAt(200, bob), blockRef{Height: 200, Time: t0.Add(2 * time.Hour)}))
+
+	statuses := make(map[string]string)
+	var rows []models.UnbondingDelegation
+	require.NoError(t, db.DB.Find(&rows).Error)
+	for _, row := range rows {
+		statuses[row.DelegatorAddress] = row.Status
+	}
+	assert.Equal(t, map[string]string{
+		"cosmos1alice": models.UnbondingCompleted,
+		"cosmos1bob":   models.UnbondingPending,
+		"cosmos1carol": models.UnbondingCancelled,
+	}, statuses)
+}
+
+func TestForecastUnbondingsGroupsByCompletionDay(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemoryStore().Repositories()
+
+	// Whole days ahead in the aggregation timezone, so every entry lands on a predictable date
+	day := startOfDay(time.Now()).AddDate(0, 0, 2)
+	_, err := repos.Unbondings.SaveUnbondings(ctx, "cosmoshub-4", "cosmosvaloper1watched", listedAt(100,
+		unbondingEntry("cosmos1alice", 90, day.Add(1*time.Hour), 100),
+		unbondingEntry("cosmos1bob", 91, day.Add(20*time.Hour), 250),
+		unbondingEntry("cosmos1carol", 92, day.AddDate(0, 0, 3).Add(5*time.Hour), 300),
+		unbondingEntry("cosmos1dave", 93, day.AddDate(0, 0, 40), 999), // beyond the forecast window
+	), 100, time.Now())
+	require.NoError(t, err)
+
+	forecast, err := NewUnbondingService(repos.Unbondings).ForecastUnbondings(ctx, "cosmoshub-4", "cosmosvaloper1watched", 30)
+	require.NoError(t, err)
+
+	assert.Equal(t, config.AggregationLocation().String(), forecast.Timezone)
+	require.Len(t, forecast.Daily, 2)
+	assert.Equal(t, day.Format(DateLayout), forecast.Daily[0].Date)
+	assert.Equal(t, "350", forecast.Daily[0].Balance.String())
+	assert.Equal(t, 2, forecast.Daily[0].Entries)
+	assert.Equal(t, day.AddDate(0, 0, 3).Format(DateLayout), forecast.Daily[1].Date)
+	assert.Equal(t, "300", forecast.Daily[1].Balance.String())
+	assert.Equal(t, "650", forecast.TotalBalance.String())
+	assert.Equal(t, 3, forecast.TotalEntries)
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/errors"
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/internal/repository"
+	"cosmos-tracker/pkg/numeric"
+)
+
+// API response structure for a single validator
+type ValidatorResponse struct {
+	Validator struct {
+		OperatorAddress string `json:"operator_address"`
+		Jailed          bool   `json:"jailed"`
+		Status          string `json:"status"`
+		Tokens          string `json:"tokens"`
+		DelegatorShares string `json:"delegator_shares"`
+		Description     struct {
+			Moniker string `json:"moniker"`
+			Website string `json:"website"`
+		} `json:"description"`
+		Commission struct {
+			CommissionRates struct {
+				Rate          string `json:"rate"`
+				MaxRate       string `json:"max_rate"`
+				MaxChangeRate string `json:"max_change_rate"`
+			} `json:"commission_rates"`
+		} `json:"commission"`
+	} `json:"validator"`
+}
+
+// API response structure for the staking pool
+type StakingPoolResponse struct {
+	Pool struct {
+		BondedTokens    string `json:"bonded_tokens"`
+		NotBondedTokens string `json:"not_bonded_tokens"`
+	} `json:"pool"`
+}
+
+// Bond status reported for validators in the active set
+const BondStatusBonded = "BOND_STATUS_BONDED"
+
+// fetches the validator and staking pool at a pinned height and builds a snapshot
+func fetchValidatorSnapshot(ctx context.Context, chain config.ChainConfig, entry dto.WatchlistEntry, block blockRef) (models.ValidatorSnapshot, error) {
+	var validator ValidatorResponse
+	path := fmt.Sprintf("/cosmos/staking/v1beta1/validators/%s", entry.ValidatorAddress)
+	if err := fetchChainJSON(ctx, chain, path, block.Height, &validator); err != nil {
+		return models.ValidatorSnapshot{}, err
+	}
+
+	v := validator.Validator
+	tokens, err := numeric.ParseInt(v.Tokens)
+	if err != nil {
+		return models.ValidatorSnapshot{}, fmt.Errorf("error parsing validator tokens '%s': %w", v.Tokens, err)
+	}
+
+	delegatorShares, err := numeric.ParseDec(v.DelegatorShares)
+	if err != nil {
+		log.Printf("⚠️ Error parsing delegator shares '%s': %v", v.DelegatorShares, err)
+	}
+
+	snapshot := models.ValidatorSnapshot{
+		WatchlistID:             uint(entry.ID),
+		ChainID:                 entry.ChainID,
+		ValidatorAddress:        entry.ValidatorAddress,
+		Moniker:                 v.Description.Moniker,
+		Website:                 v.Description.Website,
+		Tokens:                  tokens,
+		DelegatorShares:         delegatorShares,
+		CommissionRate:          parseDecimal(v.Commission.CommissionRates.Rate),
+		CommissionMaxRate:       parseDecimal(v.Commission.CommissionRates.MaxRate),
+		CommissionMaxChangeRate: parseDecimal(v.Commission.CommissionRates.MaxChangeRate),
+		Jailed:                  v.Jailed,
+		Status:                  v.Status,
+		BlockHeight:             block.Height,
+		BlockTime:               block.Time,
+	}
+
+	// Only bonded validators carry consensus voting power
+	if v.Status == BondStatusBonded {
+		snapshot.VotingPower = tokens.QuoInt64(chain.ConsensusPowerReduction()).BigInt().Int64()
+
+		var pool StakingPoolResponse
+		if err := fetchChainJSON(ctx, chain, "/cosmos/staking/v1beta1/pool", block.Height, &pool); err != nil {
+			log.Printf("⚠️ Could not fetch staking pool for %s: %v", chain.ChainID, err)
+		} else if bonded, err := numeric.ParseInt(pool.Pool.BondedTokens); err == nil && bonded.Sign() > 0 {
+			share := new(big.Float).Quo(new(big.Float).SetInt(tokens.BigInt()), new(big.Float).SetInt(bonded.BigInt()))
+			snapshot.VotingPowerShare, _ = share.Float64()
+		}
+	}
+
+	return snapshot, nil
+}
+
+// parses an SDK decimal string, returning zero for blanks or bad input
+func parseDecimal(value string) numeric.Dec {
+	if value == "" {
+		return numeric.Dec{}
+	}
+	parsed, err := numeric.ParseDec(value)
+	if err != nil {
+		log.Printf("⚠️ Error parsing decimal value '%s': %v", value, err)
+		return numeric.Dec{}
+	}
+	return parsed
+}
+
+// ValidatorService serves stored validator snapshots and stats
+type ValidatorService struct {
+	validators repository.ValidatorRepository
+}
+
+// creates a validator service on top of a repository
+func NewValidatorService(validators repository.ValidatorRepository) *ValidatorService {
+	return &ValidatorService{validators: validators}
+}
+
+// builds the repository query for one page of a validator's rows
+func pageQuery(chainID, validatorAddress string, page, limit int) repository.PageQuery {
+	return repository.PageQuery{
+		ChainID:          chainID,
+		ValidatorAddress: validatorAddress,
+		Limit:            limit,
+		Offset:           (page - 1) * limit,
+	}
+}
+
+// retrieves paginated validator snapshots, newest first
+func (s *ValidatorService) FetchValidatorSnapshotsWithPagination(ctx context.Context, chainID, validatorAddress string, page, limit int) ([]dto.ValidatorSnapshotDTO, int64, error) {
+	snapshots, total, err := s.validators.ValidatorSnapshots(ctx, pageQuery(chainID, validatorAddress, page, limit))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Convert to DTOs
+	result := make([]dto.ValidatorSnapshotDTO, len(snapshots))
+	for i, s := range snapshots {
+		result[i] = toValidatorSnapshotDTO(s)
+	}
+
+	return result, total, nil
+}
+
+// retrieves the most recent snapshot of a validator
+func (s *ValidatorService) FetchCurrentValidator(ctx context.Context, chainID, validatorAddress string) (dto.ValidatorSnapshotDTO, error) {
+	snapshot, err := s.validators.LatestValidatorSnapshot(ctx, chainID, validatorAddress)
+	if stderrors.Is(err, repository.ErrNotFound) {
+		return dto.ValidatorSnapshotDTO{}, errors.NewNotFoundError("Validator snapshot", err)
+	}
+	if err != nil {
+		return dto.ValidatorSnapshotDTO{}, err
+	}
+
+	return toValidatorSnapshotDTO(snapshot), nil
+}
+
+// converts a validator snapshot model to its API representation
+func toValidatorSnapshotDTO(s models.ValidatorSnapshot) dto.ValidatorSnapshotDTO {
+	return dto.ValidatorSnapshotDTO{
+		ID:                      s.ID,
+		ChainID:                 s.ChainID,
+		ValidatorAddress:        s.ValidatorAddress,
+		Moniker:                 s.Moniker,
+		Website:                 s.Website,
+		Tokens:                  s.Tokens,
+		DelegatorShares:         s.DelegatorShares,
+		CommissionRate:          s.CommissionRate,
+		CommissionMaxRate:       s.CommissionMaxRate,
+		CommissionMaxChangeRate: s.CommissionMaxChangeRate,
+		Jailed:                  s.Jailed,
+		Status:                  s.Status,
+		VotingPower:             s.VotingPower,
+		VotingPowerShare:        s.VotingPowerShare,
+		BlockHeight:             s.BlockHeight,
+		BlockTime:               s.BlockTime,
+		Timestamp:               s.Timestamp,
+	}
+}
+
+// stores a validator snapshot, syncing the watchlist name with the on-chain moniker when none was typed
+func (c *Collector) storeValidatorSnapshot(ctx context.Context, entry dto.WatchlistEntry, snapshot models.ValidatorSnapshot, run collectionRun) error {
+	snapshot.RunID = run.ID
+	snapshot.Timestamp = run.Start
+	rename := snapshot.Moniker != "" && entry.ValidatorName == ""
+	return c.validators.SaveValidatorSnapshot(ctx, snapshot, rename)
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cosmos-tracker/config"
+	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/repository"
+	"cosmos-tracker/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validator response with the given status and tokens and fixed commission rates
+func validatorJSON(status, tokens string) string {
+	return `{"validator": {
+		"operator_address": "cosmosvaloper1watched",
+		"jailed": false,
+		"status": "` + status + `",
+		"tokens": "` + tokens + `",
+		"delegator_shares": "` + tokens + `.500000000000000000",
+		"description": {"moniker": "Watched", "website": "https://watched.example"},
+		"commission": {"commission_rates": {
+			"rate": "0.050000000000000000",
+			"max_rate": "0.200000000000000000",
+			"max_change_rate": "0.010000000000000000"
+		}}
+	}}`
+}
+
+// serves a validator and the staking pool, recording the paths the collector asks for
+func serveValidator(t *testing.T, chain config.ChainConfig, validator, pool string) (config.ChainConfig, *[]string) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		if strings.HasSuffix(r.URL.Path, "/pool") {
+			w.Write([]byte(pool))
+			return
+		}
+		w.Write([]byte(validator))
+	}))
+	t.Cleanup(server.Close)
+	chain.LCDEndpoints = []string{server.URL}
+	return chain, &paths
+}
+
+func TestFetchValidatorSnapshotOfBondedValidator(t *testing.T) {
+	// 18-decimal staking token whose power reduction doesn't follow the denom exponent
+	chain, _ := serveValidator(t,
+		config.ChainConfig{ChainID: "validator-bonded-1", DenomExponent: 18, PowerReduction: 1_000_000_000_000},
+		validatorJSON(BondStatusBonded, "25000000000000000"),
+		`{"pool": {"bonded_tokens": "100000000000000000"}}`)
+	entry := dto.WatchlistEntry{ID: 7, ChainID: chain.ChainID, ValidatorAddress: "cosmosvaloper1watched"}
+	block := blockRef{Height: 100, Time: time.Now()}
+
+	snapshot, err := fetchValidatorSnapshot(context.Background(), chain, entry, block)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint(7), snapshot.WatchlistID)
+	assert.Equal(t, "Watched", snapshot.Moniker)
+	assert.Equal(t, "https://watched.example", snapshot.Website)
+	assert.Equal(t, "25000000000000000", snapshot.Tokens.String())
+	assert.Equal(t, "25000000000000000.500000000000000000", snapshot.DelegatorShares.String())
+	assert.Equal(t, "0.050000000000000000", snapshot.CommissionRate.String())
+	assert.Equal(t, "0.200000000000000000", snapshot.CommissionMaxRate.String())
+	assert.Equal(t, "0.010000000000000000", snapshot.CommissionMaxChangeRate.String())
+	assert.Equal(t, int64(25000), snapshot.VotingPower)
+	assert.InDelta(t, 0.25, snapshot.VotingPowerShare, 1e-9)
+	assert.Equal(t, int64(100), snapshot.BlockHeight)
+}
+
+func TestFetchValidatorSnapshotDefaultsPowerReduction(t *testing.T) {
+	chain, _ := serveValidator(t,
+		config.ChainConfig{ChainID: "validator-default-power-1"},
+		validatorJSON(BondStatusBonded, "3500000"),
+		`{"pool": {"bonded_tokens": "7000000"}}`)
+	entry := dto.WatchlistEntry{ChainID: chain.ChainID, ValidatorAddress: "cosmosvaloper1watched"}
+
+	snapshot, err := fetchValidatorSnapshot(context.Background(), chain, entry, blockRef{Height: 100})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), snapshot.VotingPower)
+	assert.InDelta(t, 0.5, snapshot.VotingPowerShare, 1e-9)
+}
+
+func TestFetchValidatorSnapshotOfUnbondedValidator(t *testing.T) {
+	chain, paths := serveValidator(t,
+		config.ChainConfig{ChainID: "validator-unbonded-1"},
+		validatorJSON("BOND_STATUS_UNBONDED", "3500000"),
+		`{"pool": {"bonded_tokens": "7000000"}}`)
+	entry := dto.WatchlistEntry{ChainID: chain.ChainID, ValidatorAddress: "cosmosvaloper1watched"}
+
+	snapshot, err := fetchValidatorSnapshot(context.Background(), chain, entry, blockRef{Height: 100})
+	require.NoError(t, err)
+
+	// Validators outside the active set have no voting power, so the pool is never queried
+	assert.Zero(t, snapshot.VotingPower)
+	assert.Zero(t, snapshot.VotingPowerShare)
+	assert.Equal(t, []string{"/cosmos/staking/v1beta1/validators/cosmosvaloper1watched"}, *paths)
+}
+
+func TestFetchValidatorSnapshotRejectsBadTokens(t *testing.T) {
+	chain, _ := serveValidator(t,
+		config.ChainConfig{ChainID: "validator-bad-tokens-1"},
+		validatorJSON(BondStatusBonded, "12abc"),
+		`{"pool": {"bonded_tokens": "7000000"}}`)
+	entry := dto.WatchlistEntry{ChainID: chain.ChainID, ValidatorAddress: "cosmosvaloper1watched"}
+
+	_, err := fetchValidatorSnapshot(context.Background(), chain, entry, blockRef{Height: 100})
+	assert.ErrorContains(t, err, "error parsing validator tokens")
+}
+
+func TestStoredCommissionRatesKeepTheirDecimals(t *testing.T) {
+	entry := useTestDB(t)
+	chain, _ := serveValidator(t,
+		config.ChainConfig{ChainID: "validator-stored-1"},
+		validatorJSON(BondStatusBonded, "3500000"),
+		`{"pool": {"bonded_tokens": "7000000"}}`)
+	collector, _ := testServices()
+	ctx := context.Background()
+
+	snapshot, err := fetchValidatorSnapshot(ctx, chain, entry, blockRef{Height: 100, Time: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, collector.storeValidatorSnapshot(ctx, entry, snapshot, testRun(100)))
+
+	stored, err := NewValidatorService(repository.NewGorm(db.DB).Validators).FetchCurrentValidator(ctx, entry.ChainID, entry.ValidatorAddress)
+	require.NoError(t, err)
+	assert.Equal(t, "0.050000000000000000", stored.CommissionRate.String())
+	assert.Equal(t, "0.200000000000000000", stored.CommissionMaxRate.String())
+	assert.Equal(t, "0.010000000000000000", stored.CommissionMaxChangeRate.String())
+	assert.Equal(t, int64(3), stored.VotingPower)
+}
@@ -1,42 +1,72 @@
 package services
 
 import (
+	"context"
+	"strconv"
+
 	"cosmos-tracker/internal/dto"
+	"cosmos-tracker/internal/errors"
 	"cosmos-tracker/internal/models"
-	"cosmos-tracker/pkg/db"
+	"cosmos-tracker/internal/repository"
 )
 
+// WatchlistService manages the validators being tracked
+type WatchlistService struct {
+	watchlist repository.WatchlistRepository
+}
+
+// creates a watchlist service on top of a repository
+func NewWatchlistService(watchlist repository.WatchlistRepository) *WatchlistService {
+	return &WatchlistService{watchlist: watchlist}
+}
+
 // adds a new entry to the watchlist
-func AddWatchlistEntry(entry dto.WatchlistEntry) error {
+func (s *WatchlistService) AddWatchlistEntry(ctx context.Context, entry dto.WatchlistEntry) error {
+	chain, err := ResolveChain(entry.ChainID, entry.ValidatorAddress)
+	if err != nil {
+		return err
+	}
+
 	watchlistItem := models.Watchlist{
+		ChainID:          chain.ChainID,
 		ValidatorName:    entry.ValidatorName,
 		ValidatorAddress: entry.ValidatorAddress,
 	}
 
-	return db.DB.Create(&watchlistItem).Error
+	return s.watchlist.CreateWatchlist(ctx, &watchlistItem)
 }
 
-// returns all entries in the watchlist
-func GetWatchlist() ([]dto.WatchlistEntry, error) {
-	var watchlistItems []models.Watchlist
-	if err := db.DB.Find(&watchlistItems).Error; err != nil {
+// returns all entries in the watchlist, optionally limited to one chain
+func (s *WatchlistService) GetWatchlist(ctx context.Context, chainID string) ([]dto.WatchlistEntry, error) {
+	watchlistItems, err := s.watchlist.ListWatchlist(ctx, chainID)
+	if err != nil {
 		return nil, err
 	}
 
 	// Convert from DB model to DTO
 	entries := make([]dto.WatchlistEntry, len(watchlistItems))
 	for i, item := range watchlistItems {
-		entries[i] = dto.WatchlistEntry{
-			ID:               int(item.ID),
-			ValidatorName:    item.ValidatorName,
-			ValidatorAddress: item.ValidatorAddress,
-		}
+		entries[i] = toWatchlistEntry(item)
 	}
 
 	return entries, nil
 }
 
+// converts a watchlist model to its API representation
+func toWatchlistEntry(item models.Watchlist) dto.WatchlistEntry {
+	return dto.WatchlistEntry{
+		ID:               int(item.ID),
+		ChainID:          item.ChainID,
+		ValidatorName:    item.ValidatorName,
+		ValidatorAddress: item.ValidatorAddress,
+	}
+}
+
 // removes an entry from the watchlist by ID
-func RemoveWatchlistEntry(id string) error {
-	return db.DB.Delete(&models.Watchlist{}, id).Error
+func (s *WatchlistService) RemoveWatchlistEntry(ctx context.Context, id string) error {
+	watchlistID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || watchlistID == 0 {
+		return errors.NewBadRequestError("id must be a positive integer", err)
+	}
+	return s.watchlist.DeleteWatchlist(ctx, uint(watchlistID))
 }
@@ -17,6 +17,20 @@ import (
 
 var DB *gorm.DB
 
+// database backends selected by DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// returns the backend named by DB_DRIVER, defaulting to Postgres
+func Driver() string {
+	if driver := strings.ToLower(strings.TrimSpace(os.Getenv("DB_DRIVER"))); driver != "" {
+		return driver
+	}
+	return DriverPostgres
+}
+
 // establishes a connection to the database with optimized settings
 func ConnectDB() {
 	// Close any existing connection first
@@ -33,6 +47,17 @@ func ConnectDB() {
 		log.Println("Warning: No .env file found")
 	}
 
+	database, err := Open()
+	if err != nil {
+		log.Fatal("❌ Failed to connect to database: ", err)
+	}
+	DB = database
+
+	log.Printf("✅ Database connected successfully (%s)!", Driver())
+}
+
+// opens the database selected by DB_DRIVER, bootstraps the migration history table and verifies the connection
+func Open() (*gorm.DB, error) {
 	// Configure custom logger for better SQL debugging
 	newLogger := logger.New(
 		log.New(os.Stdout, "\r\n", log.LstdFlags),
@@ -44,98 +69,85 @@ func ConnectDB() {
 		},
 	)
 
-	// Add connection parameters to fix the cached plan issue
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s application_name=cosmos_validator_app",
-		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_NAME"), os.Getenv("SSLMODE"),
-	)
-
 	// Apply performance optimizations through gorm config
-	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	gormConfig := &gorm.Config{
 		Logger:                 newLogger,
 		SkipDefaultTransaction: true,
 		PrepareStmt:            false, // Disable prepared statements to avoid caching issues
-	})
-	if err != nil {
-		log.Fatal("❌ Failed to connect to database:", err)
 	}
 
-	// Execute DISCARD ALL to clear any statement cache
-	if err := database.Exec("DISCARD ALL").Error; err != nil {
-		log.Printf("⚠️ Warning: Failed to discard cached plans: %v", err)
-		// Continue anyway as this is just a precaution
+	var database *gorm.DB
+	var err error
+	switch driver := Driver(); driver {
+	case DriverPostgres:
+		database, err = openPostgres(gormConfig)
+	case DriverSQLite:
+		database, err = openSQLite(SQLitePath(), gormConfig)
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q, use %s or %s", driver, DriverPostgres, DriverSQLite)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Configure connection pool settings
 	sqlDB, err := database.DB()
 	if err != nil {
-		log.Fatal("❌ Failed to get database connection:", err)
+		return nil, err
 	}
 
-	// Set connection pool parameters for optimal performance
-	sqlDB.SetMaxIdleConns(10)               // Maximum number of idle connections
-	sqlDB.SetMaxOpenConns(50)               // Maximum number of open connections
-	sqlDB.SetConnMaxLifetime(1 * time.Hour) // Maximum lifetime of a connection
-
-	DB = database
-
-	// First migrate the migration history table itself
-	if err := DB.AutoMigrate(&models.MigrationHistory{}); err != nil {
-		log.Fatal("❌ Failed to migrate migration history table: ", err)
+	// Only the migration history table is managed in code; everything else comes from versioned migrations
+	if err := database.AutoMigrate(&models.MigrationHistory{}); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("migrating migration history table: %w", err)
 	}
 
-	// Prepare to track models being migrated
-	modelsToMigrate := []interface{}{
-		&models.HourlyDelegation{},
-		&models.DailyDelegation{},
-		&models.Watchlist{},
-	}
+	// Verify connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	// Get model names for logging
-	modelNames := make([]string, len(modelsToMigrate))
-	for i, model := range modelsToMigrate {
-		modelNames[i] = fmt.Sprintf("%T", model)
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("verifying connection: %w", err)
 	}
+	return database, nil
+}
 
-	// Perform the migration
-	migrationRecord := models.MigrationHistory{
-		Models: strings.Join(modelNames, ", "),
-		Status: "in_progress",
-	}
+// opens Postgres from the DB_* environment variables with a pool sized for the collector
+func openPostgres(gormConfig *gorm.Config) (*gorm.DB, error) {
+	// Add connection parameters to fix the cached plan issue
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s application_name=cosmos_validator_app",
+		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_NAME"), os.Getenv("SSLMODE"),
+	)
 
-	// Save initial migration record
-	if err := DB.Create(&migrationRecord).Error; err != nil {
-		log.Printf("⚠️ Failed to create migration history record: %v", err)
+	database, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	if err != nil {
+		return nil, err
 	}
 
-	// Auto-migrate tables with indices for better query performance
-	err = DB.AutoMigrate(modelsToMigrate...)
+	// Execute DISCARD ALL to clear any statement cache
+	if err := database.Exec("DISCARD ALL").Error; err != nil {
+		log.Printf("⚠️ Warning: Failed to discard cached plans: %v", err)
+		// Continue anyway as this is just a precaution
+	}
 
-	// Update migration record with result
+	// Configure connection pool settings
+	sqlDB, err := database.DB()
 	if err != nil {
-		migrationRecord.Status = "error"
-		migrationRecord.ErrorMessage = err.Error()
-		DB.Save(&migrationRecord)
-		log.Fatal("❌ Failed to migrate database:", err)
-	} else {
-		migrationRecord.Status = "success"
-		DB.Save(&migrationRecord)
+		return nil, err
 	}
 
-	// Verify connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := sqlDB.PingContext(ctx); err != nil {
-		log.Fatal("❌ Database connection verification failed: ", err)
-	}
+	// Set connection pool parameters for optimal performance
+	sqlDB.SetMaxIdleConns(10)               // Maximum number of idle connections
+	sqlDB.SetMaxOpenConns(50)               // Maximum number of open connections
+	sqlDB.SetConnMaxLifetime(1 * time.Hour) // Maximum lifetime of a connection
 
-	log.Println("✅ Database connected and migrated successfully!")
+	return database, nil
 }
 
-// runs a function within a transaction
-func WithTransaction(fn func(tx *gorm.DB) error) error {
-	tx := DB.Begin()
+// runs a function within a transaction bound to ctx, rolling back if ctx is cancelled
+func WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	tx := DB.WithContext(ctx).Begin()
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -155,11 +167,23 @@ func WithTransaction(fn func(tx *gorm.DB) error) error {
 	return tx.Commit().Error
 }
 
-// returns the migration history records
-func GetMigrationHistory(limit int) ([]models.MigrationHistory, error) {
+// closes the underlying connection pool
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// returns a database's migration history records
+func GetMigrationHistory(database *gorm.DB, limit int) ([]models.MigrationHistory, error) {
 	var history []models.MigrationHistory
 
-	result := DB.Order("migrated_at DESC")
+	result := database.Order("migrated_at DESC")
 
 	if limit > 0 {
 		result = result.Limit(limit)
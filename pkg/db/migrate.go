@@ -0,0 +1,337 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cosmos-tracker/internal/models"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations
+var embeddedMigrations embed.FS
+
+// Migration history directions and statuses
+const (
+	MigrationUp      = "up"
+	MigrationDown    = "down"
+	MigrationSuccess = "success"
+	MigrationError   = "error"
+)
+
+// returned by CheckSchema when the database does not match the migrations of this build
+var ErrSchemaMismatch = errors.New("database schema does not match this build")
+
+// Migration is one numbered schema change with its up and down scripts
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // sha256 of the up script, line endings normalized
+}
+
+// MigrationStatus describes one migration relative to the database
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Checksum  string
+	Applied   bool
+	AppliedAt time.Time
+	Modified  bool // applied from an up script that differs from the embedded one
+	Unknown   bool // applied in the database but missing from this build
+}
+
+// matches files such as 0002_add_run_id.up.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// parses every migration in a directory, ordered by version; each version needs an up script
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		script := strings.ReplaceAll(string(content), "\r\n", "\n")
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == MigrationUp {
+			sum := sha256.Sum256([]byte(script))
+			m.Up, m.Checksum = script, hex.EncodeToString(sum[:])
+		} else {
+			m.Down = script
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// returns the migrations embedded in this build for the connected database's dialect
+func Migrations() ([]Migration, error) {
+	return dialectMigrations(DB)
+}
+
+// returns the migrations embedded in this build for a database's dialect
+func dialectMigrations(database *gorm.DB) ([]Migration, error) {
+	return loadMigrations(embeddedMigrations, path.Join("migrations", database.Dialector.Name()))
+}
+
+// returns the successful up record of every migration currently applied, keyed by version
+func appliedMigrations(database *gorm.DB) (map[int]models.MigrationHistory, error) {
+	var history []models.MigrationHistory
+	if err := database.Where("version > 0 AND status = ?", MigrationSuccess).
+		Order("id ASC").
+		Find(&history).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]models.MigrationHistory)
+	for _, h := range history {
+		if h.Direction == MigrationDown {
+			delete(applied, h.Version)
+		} else {
+			applied[h.Version] = h
+		}
+	}
+	return applied, nil
+}
+
+// compares the migrations of a build with what the database has applied
+func migrationStatuses(database *gorm.DB, migrations []Migration) ([]MigrationStatus, error) {
+	applied, err := appliedMigrations(database)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name, Checksum: m.Checksum}
+		if h, ok := applied[m.Version]; ok {
+			status.Applied, status.AppliedAt = true, h.MigratedAt
+			status.Modified = h.Checksum != m.Checksum
+			delete(applied, m.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	// Versions applied by a newer build
+	for _, h := range applied {
+		statuses = append(statuses, MigrationStatus{
+			Version: h.Version, Name: h.Name, Checksum: h.Checksum,
+			Applied: true, AppliedAt: h.MigratedAt, Unknown: true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// reports every embedded migration and whether a database has applied it
+func MigrationStatuses(database *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := dialectMigrations(database)
+	if err != nil {
+		return nil, err
+	}
+	return migrationStatuses(database, migrations)
+}
+
+// fails when migrations are pending, were edited after being applied, or come from a newer build
+func checkSchema(database *gorm.DB, migrations []Migration) error {
+	statuses, err := migrationStatuses(database, migrations)
+	if err != nil {
+		return err
+	}
+
+	var pending, modified, unknown []string
+	for _, s := range statuses {
+		label := fmt.Sprintf("%04d_%s", s.Version, s.Name)
+		switch {
+		case s.Unknown:
+			unknown = append(unknown, label)
+		case s.Modified:
+			modified = append(modified, label)
+		case !s.Applied:
+			pending = append(pending, label)
+		}
+	}
+
+	switch {
+	case len(unknown) > 0:
+		return fmt.Errorf("%w: applied by a newer build: %s", ErrSchemaMismatch, strings.Join(unknown, ", "))
+	case len(modified) > 0:
+		return fmt.Errorf("%w: changed after being applied: %s", ErrSchemaMismatch, strings.Join(modified, ", "))
+	case len(pending) > 0:
+		return fmt.Errorf("%w: %d pending migration(s), run `migrate up`: %s", ErrSchemaMismatch, len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// reports whether the server applies pending migrations at startup: when MIGRATE_ON_START is true,
+// and always for an in-memory SQLite database, which starts empty in every process
+func MigrateOnStart() bool {
+	if Driver() == DriverSQLite && SQLitePath() == ":memory:" {
+		return true
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv("MIGRATE_ON_START"))
+	return enabled
+}
+
+// verifies the database schema is exactly what this build expects
+func CheckSchema() error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+	return checkSchema(DB, migrations)
+}
+
+// runs one script and records it in the same transaction; a failure is recorded separately
+func runMigration(ctx context.Context, database *gorm.DB, m Migration, direction, script string) error {
+	started := time.Now()
+	record := models.MigrationHistory{
+		Version:   m.Version,
+		Name:      m.Name,
+		Direction: direction,
+		Checksum:  m.Checksum,
+		Status:    MigrationSuccess,
+	}
+
+	err := database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(script).Error; err != nil {
+			return err
+		}
+		return tx.Create(&record).Error
+	})
+	if err != nil {
+		record.ID, record.Status, record.ErrorMessage = 0, MigrationError, err.Error()
+		if recordErr := database.Create(&record).Error; recordErr != nil {
+			log.Printf("⚠️ Failed to record migration failure: %v", recordErr)
+		}
+		return fmt.Errorf("migration %04d_%s %s: %w", m.Version, m.Name, direction, err)
+	}
+
+	log.Printf("✅ Migrated %s %04d_%s in %v", direction, m.Version, m.Name, time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+// applies up to steps pending migrations in version order, or all of them when steps is 0
+func migrateUp(ctx context.Context, database *gorm.DB, migrations []Migration, steps int) (int, error) {
+	statuses, err := migrationStatuses(database, migrations)
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range statuses {
+		if s.Modified || s.Unknown {
+			return 0, checkSchema(database, migrations)
+		}
+	}
+
+	applied := make(map[int]bool, len(statuses))
+	for _, s := range statuses {
+		applied[s.Version] = s.Applied
+	}
+
+	count := 0
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		if steps > 0 && count == steps {
+			break
+		}
+		if err := runMigration(ctx, database, m, MigrationUp, m.Up); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// rolls back the steps most recently applied migrations, newest first
+func migrateDown(ctx context.Context, database *gorm.DB, migrations []Migration, steps int) (int, error) {
+	statuses, err := migrationStatuses(database, migrations)
+	if err != nil {
+		return 0, err
+	}
+
+	byVersion := make(map[int]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	count := 0
+	for i := len(statuses) - 1; i >= 0 && count < steps; i-- {
+		s := statuses[i]
+		if !s.Applied {
+			continue
+		}
+		m, ok := byVersion[s.Version]
+		if !ok || s.Unknown {
+			return count, fmt.Errorf("migration %04d_%s is not part of this build and cannot be rolled back", s.Version, s.Name)
+		}
+		if m.Down == "" {
+			return count, fmt.Errorf("migration %04d_%s has no down script", m.Version, m.Name)
+		}
+		if err := runMigration(ctx, database, m, MigrationDown, m.Down); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// applies up to steps pending embedded migrations, or all of them when steps is 0
+func MigrateUp(ctx context.Context, steps int) (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	return migrateUp(ctx, DB, migrations, steps)
+}
+
+// rolls back the steps most recently applied embedded migrations
+func MigrateDown(ctx context.Context, steps int) (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	return migrateDown(ctx, DB, migrations, steps)
+}
@@ -0,0 +1,414 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"cosmos-tracker/internal/models"
+	"cosmos-tracker/pkg/numeric"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// opens an in-memory database with the migration history table bootstrapped
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	database, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	sqlDB, err := database.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, database.AutoMigrate(&models.MigrationHistory{}))
+	return database
+}
+
+var testMigrations = fstest.MapFS{
+	"m/0001_create_items.up.sql":   {Data: []byte("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT);\r\n")},
+	"m/0001_create_items.down.sql": {Data: []byte("DROP TABLE items;")},
+	"m/0002_add_price.up.sql":      {Data: []byte("ALTER TABLE items ADD COLUMN price INTEGER;\nUPDATE items SET price = 0;")},
+	"m/0002_add_price.down.sql":    {Data: []byte("ALTER TABLE items DROP COLUMN price;")},
+	"m/README.md":                  {Data: []byte("ignored")},
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	postgresMigrations, err := loadMigrations(embeddedMigrations, "migrations/"+DriverPostgres)
+	require.NoError(t, err)
+	require.NotEmpty(t, postgresMigrations)
+	sqliteMigrations, err := loadMigrations(embeddedMigrations, "migrations/"+DriverSQLite)
+	require.NoError(t, err)
+
+	// Every schema change ships for both dialects under the same version and name
+	require.Len(t, sqliteMigrations, len(postgresMigrations))
+	for i, m := range postgresMigrations {
+		assert.Equal(t, i+1, m.Version, "versions are contiguous")
+		assert.NotEmpty(t, m.Down, "%04d_%s needs a down script", m.Version, m.Name)
+		assert.Len(t, m.Checksum, 64)
+		assert.Equal(t, m.Version, sqliteMigrations[i].Version)
+		assert.Equal(t, m.Name, sqliteMigrations[i].Name)
+		assert.NotEmpty(t, sqliteMigrations[i].Down, "sqlite %04d_%s needs a down script", m.Version, m.Name)
+	}
+}
+
+func TestSQLiteRunsEmbeddedMigrationsBothWays(t *testing.T) {
+	t.Setenv("DB_DRIVER", DriverSQLite)
+	t.Setenv("SQLITE_PATH", ":memory:")
+	database, err := Open()
+	require.NoError(t, err)
+	database.Logger = logger.Discard
+	sqlDB, err := database.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrations, err := loadMigrations(embeddedMigrations, "migrations/"+database.Dialector.Name())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = migrateUp(ctx, database, migrations, 0)
+	require.NoError(t, err)
+	require.NoError(t, checkSchema(database, migrations))
+	assert.True(t, database.Migrator().HasTable(&models.HourlyDelegation{}))
+
+	_, err = migrateDown(ctx, database, migrations, len(migrations))
+	require.NoError(t, err)
+	assert.False(t, database.Migrator().HasTable(&models.HourlyDelegation{}))
+}
+
+var (
+	createTableLine = regexp.MustCompile(`^CREATE TABLE (?:IF NOT EXISTS )?"(\w+)" \($`)
+	columnLine      = regexp.MustCompile(`^\s+"(\w+)" ([\w()]+)`)
+	alterTable      = regexp.MustCompile(`^ALTER TABLE "(\w+)"`)
+	addedColumn     = regexp.MustCompile(`ADD COLUMN IF NOT EXISTS "(\w+)" ([\w()]+)`)
+	retypedColumn   = regexp.MustCompile(`ALTER COLUMN "(\w+)" TYPE ([\w()]+)`)
+)
+
+// returns the declared type of every column of the tables a script creates
+func createdColumns(script string) map[string]map[string]string {
+	tables := make(map[string]map[string]string)
+	var columns map[string]string
+	for _, line := range strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n") {
+		if m := createTableLine.FindStringSubmatch(line); m != nil {
+			columns = make(map[string]string)
+			tables[m[1]] = columns
+		} else if strings.HasPrefix(line, ")") {
+			columns = nil
+		} else if m := columnLine.FindStringSubmatch(line); m != nil && columns != nil {
+			columns[m[1]] = m[2]
+		}
+	}
+	return tables
+}
+
+// returns the columns a script's ALTER TABLE statements add if missing and the types they convert to
+func alteredColumns(script string) (added, retyped map[string]map[string]string) {
+	added, retyped = make(map[string]map[string]string), make(map[string]map[string]string)
+	var code []string
+	for _, line := range strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n") {
+		if !strings.HasPrefix(line, "--") {
+			code = append(code, line)
+		}
+	}
+	for _, statement := range strings.Split(strings.Join(code, "\n"), ";") {
+		m := alterTable.FindStringSubmatch(strings.TrimSpace(statement))
+		if m == nil {
+			continue
+		}
+		for pattern, into := range map[*regexp.Regexp]map[string]map[string]string{addedColumn: added, retypedColumn: retyped} {
+			for _, c := range pattern.FindAllStringSubmatch(statement, -1) {
+				if into[m[1]] == nil {
+					into[m[1]] = make(map[string]string)
+				}
+				into[m[1]][c[1]] = c[2]
+			}
+		}
+	}
+	return added, retyped
+}
+
+func TestPostgresBaselineTablesAreBroughtForward(t *testing.T) {
+	baseline, err := os.ReadFile("testdata/postgres_baseline.sql")
+	require.NoError(t, err)
+	migrations, err := loadMigrations(embeddedMigrations, "migrations/"+DriverPostgres)
+	require.NoError(t, err)
+
+	// Every column of a table the old boot path created is added when missing, and every column it
+	// declared with another type is converted
+	initial := migrations[0].Up
+	target := createdColumns(initial)
+	added, retyped := alteredColumns(initial)
+	for table, columns := range createdColumns(string(baseline)) {
+		if table == "migration_histories" {
+			continue // managed by AutoMigrate
+		}
+		require.Contains(t, target, table)
+		for column, columnType := range target[table] {
+			if column == "id" {
+				continue
+			}
+			assert.Equal(t, columnType, added[table][column], "%s.%s must be added when missing", table, column)
+			if old, ok := columns[column]; ok && old != columnType {
+				assert.Equal(t, columnType, retyped[table][column], "%s.%s must become %s", table, column, columnType)
+			}
+		}
+	}
+}
+
+// runs the Postgres migrations over a database as the AutoMigrate boot path left it, in a scratch
+// schema of the database TEST_POSTGRES_DSN points to
+func TestPostgresMigratesTheBaselineSchema(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	sqlDB, err := database.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // the search path belongs to the one session
+	t.Cleanup(func() { sqlDB.Close() })
+
+	schema := fmt.Sprintf("baseline_test_%d", time.Now().UnixNano())
+	require.NoError(t, database.Exec(`CREATE SCHEMA "`+schema+`"`).Error)
+	t.Cleanup(func() { database.Exec(`DROP SCHEMA "` + schema + `" CASCADE`) })
+	require.NoError(t, database.Exec(`SET search_path TO "`+schema+`"`).Error)
+
+	baseline, err := os.ReadFile("testdata/postgres_baseline.sql")
+	require.NoError(t, err)
+	require.NoError(t, database.Exec(string(baseline)).Error)
+	require.NoError(t, database.AutoMigrate(&models.MigrationHistory{}))
+
+	previous := DB
+	DB = database
+	t.Cleanup(func() { DB = previous })
+	migrations, err := Migrations()
+	require.NoError(t, err)
+	count, err := MigrateUp(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), count)
+	require.NoError(t, CheckSchema())
+
+	// Old rows keep their values and take the defaults of the new columns
+	var watchlist models.Watchlist
+	require.NoError(t, database.First(&watchlist).Error)
+	assert.Equal(t, "cosmoshub-4", watchlist.ChainID)
+
+	var hourly models.HourlyDelegation
+	require.NoError(t, database.First(&hourly).Error)
+	assert.Equal(t, "cosmoshub-4", hourly.ChainID)
+	assert.Equal(t, "1500000000", hourly.DelegationAmount.String())
+	assert.Equal(t, "1500000000.500000000000000000", hourly.Shares.String())
+	assert.False(t, hourly.Exited)
+
+	var daily models.DailyDelegation
+	require.NoError(t, database.First(&daily).Error)
+	assert.Equal(t, "1500000000", daily.TotalDelegation.String())
+
+	var types []struct {
+		TableName  string
+		ColumnName string
+		DataType   string
+	}
+	require.NoError(t, database.Raw(`SELECT table_name, column_name, data_type FROM information_schema.columns
+		WHERE table_schema = ? AND table_name IN ('hourly_delegations', 'daily_delegations')
+			AND column_name IN ('delegation_amount', 'change_amount', 'shares', 'total_delegation', 'total_shares', 'date')`,
+		schema).Scan(&types).Error)
+	require.Len(t, types, 6)
+	for _, c := range types {
+		want := "numeric"
+		if c.ColumnName == "date" {
+			want = "date"
+		}
+		assert.Equal(t, want, c.DataType, "%s.%s", c.TableName, c.ColumnName)
+	}
+}
+
+func TestSQLiteMigrationStoresAmountsAsSortableText(t *testing.T) {
+	t.Setenv("DB_DRIVER", DriverSQLite)
+	t.Setenv("SQLITE_PATH", ":memory:")
+	database, err := Open()
+	require.NoError(t, err)
+	database.Logger = logger.Discard
+	sqlDB, err := database.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrations, err := loadMigrations(embeddedMigrations, "migrations/"+database.Dialector.Name())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Rows written as numbers before the text columns, one amount already rounded to a float
+	_, err = migrateUp(ctx, database, migrations, 6)
+	require.NoError(t, err)
+	require.NoError(t, database.Exec(`INSERT INTO hourly_delegations (delegation_amount, change_amount, shares) VALUES
+		(500, -250, 12.5), ('123456789012345678901234567', -7, 0.000001), (NULL, 3, 2)`).Error)
+
+	type amounts struct {
+		DelegationAmount *numeric.Int
+		ChangeAmount     numeric.Int
+		Shares           numeric.Dec
+	}
+	var rows []amounts
+	_, err = migrateUp(ctx, database, migrations, 1)
+	require.NoError(t, err)
+	require.NoError(t, database.Table("hourly_delegations").Order("change_amount").Find(&rows).Error)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "-250", rows[0].ChangeAmount.String())
+	assert.Equal(t, "-7", rows[1].ChangeAmount.String())
+	assert.Equal(t, "500", rows[0].DelegationAmount.String())
+	assert.Equal(t, "123456789012345600000000000", rows[1].DelegationAmount.String())
+	assert.Nil(t, rows[2].DelegationAmount)
+	assert.Equal(t, "12.500000000000000000", rows[0].Shares.String())
+	assert.Equal(t, "0.000001000000000000", rows[1].Shares.String())
+	assert.Equal(t, "2.000000000000000000", rows[2].Shares.String())
+
+	var types []string
+	require.NoError(t, database.Raw("SELECT DISTINCT typeof(change_amount) FROM hourly_delegations").Scan(&types).Error)
+	assert.Equal(t, []string{"text"}, types)
+
+	// Rolling back restores plain numbers
+	_, err = migrateDown(ctx, database, migrations, 1)
+	require.NoError(t, err)
+	var restored []struct {
+		DelegationAmount *float64
+		ChangeAmount     int64
+		Shares           float64
+	}
+	require.NoError(t, database.Table("hourly_delegations").Order("change_amount").Find(&restored).Error)
+	require.Len(t, restored, 3)
+	assert.Equal(t, int64(-250), restored[0].ChangeAmount)
+	assert.Equal(t, 500.0, *restored[0].DelegationAmount)
+	assert.InDelta(t, 1.2345678901234568e26, *restored[1].DelegationAmount, 1e12)
+	assert.Equal(t, 12.5, restored[0].Shares)
+	assert.Equal(t, 0.000001, restored[1].Shares)
+}
+
+func TestSQLiteComparesTimesAcrossZones(t *testing.T) {
+	database, err := openSQLite(":memory:", &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	sqlDB, err := database.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	type event struct {
+		ID        uint
+		Timestamp time.Time
+	}
+	require.NoError(t, database.AutoMigrate(&event{}))
+
+	// 10:00 in UTC+9 is earlier than 02:00 UTC, although its text sorts later
+	tokyo := time.FixedZone("UTC+9", 9*60*60)
+	early := time.Date(2024, 1, 2, 10, 0, 0, 0, tokyo)
+	late := time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC)
+	require.NoError(t, database.Create(&event{Timestamp: late}).Error)
+	require.NoError(t, database.Create(&event{Timestamp: early}).Error)
+
+	var first event
+	require.NoError(t, database.Order("timestamp").First(&first).Error)
+	assert.True(t, first.Timestamp.Equal(early))
+
+	var count int64
+	require.NoError(t, database.Model(&event{}).Where("timestamp < ?", time.Date(2024, 1, 2, 11, 0, 0, 0, tokyo)).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestMigrateUpDownAndStatus(t *testing.T) {
+	database := openTestDB(t)
+	ctx := context.Background()
+
+	migrations, err := loadMigrations(testMigrations, "m")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	// A fresh database is behind
+	assert.True(t, errors.Is(checkSchema(database, migrations), ErrSchemaMismatch))
+
+	count, err := migrateUp(ctx, database, migrations, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Error(t, checkSchema(database, migrations))
+
+	count, err = migrateUp(ctx, database, migrations, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.NoError(t, checkSchema(database, migrations))
+	assert.True(t, database.Migrator().HasColumn("items", "price"))
+
+	// Rolling back one step leaves the first migration applied
+	count, err = migrateDown(ctx, database, migrations, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.False(t, database.Migrator().HasColumn("items", "price"))
+
+	statuses, err := migrationStatuses(database, migrations)
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+
+	// Every step is recorded with its checksum
+	var history []models.MigrationHistory
+	require.NoError(t, database.Order("id").Find(&history).Error)
+	require.Len(t, history, 3)
+	assert.Equal(t, MigrationDown, history[2].Direction)
+	assert.Equal(t, migrations[1].Checksum, history[2].Checksum)
+}
+
+func TestCheckSchemaDetectsEditedAndNewerMigrations(t *testing.T) {
+	database := openTestDB(t)
+	ctx := context.Background()
+
+	migrations, err := loadMigrations(testMigrations, "m")
+	require.NoError(t, err)
+	_, err = migrateUp(ctx, database, migrations, 0)
+	require.NoError(t, err)
+
+	// Line endings alone don't change the checksum
+	crlf := fstest.MapFS{}
+	for name, file := range testMigrations {
+		crlf[name] = file
+	}
+	crlf["m/0002_add_price.up.sql"] = &fstest.MapFile{Data: []byte("ALTER TABLE items ADD COLUMN price INTEGER;\r\nUPDATE items SET price = 0;")}
+	unchanged, err := loadMigrations(crlf, "m")
+	require.NoError(t, err)
+	require.NoError(t, checkSchema(database, unchanged))
+
+	edited := append([]Migration(nil), migrations...)
+	edited[1].Checksum = "edited"
+	assert.ErrorContains(t, checkSchema(database, edited), "changed after being applied")
+	_, err = migrateUp(ctx, database, edited, 0)
+	assert.Error(t, err)
+
+	assert.ErrorContains(t, checkSchema(database, migrations[:1]), "newer build")
+}
+
+func TestFailedMigrationIsRecordedAndRolledBack(t *testing.T) {
+	database := openTestDB(t)
+
+	broken := fstest.MapFS{
+		"m/0001_broken.up.sql": {Data: []byte("CREATE TABLE half (id INTEGER);\nNOT SQL;")},
+	}
+	migrations, err := loadMigrations(broken, "m")
+	require.NoError(t, err)
+
+	_, err = migrateUp(context.Background(), database, migrations, 0)
+	require.Error(t, err)
+	assert.False(t, database.Migrator().HasTable("half"))
+
+	var history []models.MigrationHistory
+	require.NoError(t, database.Find(&history).Error)
+	require.Len(t, history, 1)
+	assert.Equal(t, MigrationError, history[0].Status)
+	assert.Error(t, checkSchema(database, migrations))
+}
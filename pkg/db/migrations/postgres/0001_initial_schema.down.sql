@@ -0,0 +1,16 @@
+-- Drops every table of the baseline schema, referencing tables first.
+
+DROP TABLE IF EXISTS "validator_snapshots";
+DROP TABLE IF EXISTS "redelegations";
+DROP TABLE IF EXISTS "unbonding_delegations";
+DROP TABLE IF EXISTS "retention_runs";
+DROP TABLE IF EXISTS "daily_validator_stats";
+DROP TABLE IF EXISTS "hourly_validator_stats";
+DROP TABLE IF EXISTS "monthly_delegation_rollups";
+DROP TABLE IF EXISTS "weekly_delegation_rollups";
+DROP TABLE IF EXISTS "daily_delegation_rollups";
+DROP TABLE IF EXISTS "collection_heartbeats";
+DROP TABLE IF EXISTS "current_delegations";
+DROP TABLE IF EXISTS "daily_delegations";
+DROP TABLE IF EXISTS "hourly_delegations";
+DROP TABLE IF EXISTS "watchlists";
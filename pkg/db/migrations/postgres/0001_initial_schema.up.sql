@@ -0,0 +1,338 @@
+-- Baseline schema. Every statement is idempotent, so databases created by the old AutoMigrate boot
+-- path adopt this version in place. Their watchlists, hourly_delegations and daily_delegations tables
+-- predate chains, exits and block heights: the missing columns are added, existing rows take the
+-- column defaults, and the integer and float amounts become numeric.
+
+CREATE TABLE IF NOT EXISTS "watchlists" (
+    "id" bigserial,
+    "chain_id" varchar(64) DEFAULT 'cosmoshub-4',
+    "validator_address" text,
+    "validator_name" varchar(100),
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "watchlists"
+    ADD COLUMN IF NOT EXISTS "chain_id" varchar(64) DEFAULT 'cosmoshub-4',
+    ADD COLUMN IF NOT EXISTS "validator_address" text,
+    ADD COLUMN IF NOT EXISTS "validator_name" varchar(100);
+CREATE INDEX IF NOT EXISTS "idx_watchlists_validator_address" ON "watchlists" ("validator_address");
+CREATE INDEX IF NOT EXISTS "idx_watchlists_chain_id" ON "watchlists" ("chain_id");
+
+CREATE TABLE IF NOT EXISTS "hourly_delegations" (
+    "id" bigserial,
+    "watchlist_id" bigint,
+    "chain_id" varchar(64) DEFAULT 'cosmoshub-4',
+    "validator_address" text,
+    "delegator_address" text,
+    "delegation_amount" numeric,
+    "change_amount" numeric,
+    "shares" numeric,
+    "exited" boolean DEFAULT false,
+    "returned" boolean DEFAULT false,
+    "block_height" bigint,
+    "block_time" timestamptz,
+    "timestamp" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_hourly_delegations_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+);
+ALTER TABLE "hourly_delegations"
+    ADD COLUMN IF NOT EXISTS "watchlist_id" bigint,
+    ADD COLUMN IF NOT EXISTS "chain_id" varchar(64) DEFAULT 'cosmoshub-4',
+    ADD COLUMN IF NOT EXISTS "validator_address" text,
+    ADD COLUMN IF NOT EXISTS "delegator_address" text,
+    ADD COLUMN IF NOT EXISTS "delegation_amount" numeric,
+    ADD COLUMN IF NOT EXISTS "change_amount" numeric,
+    ADD COLUMN IF NOT EXISTS "shares" numeric,
+    ADD COLUMN IF NOT EXISTS "exited" boolean DEFAULT false,
+    ADD COLUMN IF NOT EXISTS "returned" boolean DEFAULT false,
+    ADD COLUMN IF NOT EXISTS "block_height" bigint,
+    ADD COLUMN IF NOT EXISTS "block_time" timestamptz,
+    ADD COLUMN IF NOT EXISTS "timestamp" timestamptz;
+ALTER TABLE "hourly_delegations"
+    ALTER COLUMN "delegation_amount" TYPE numeric,
+    ALTER COLUMN "change_amount" TYPE numeric,
+    ALTER COLUMN "shares" TYPE numeric;
+CREATE INDEX IF NOT EXISTS "idx_hourly_delegations_validator_address" ON "hourly_delegations" ("validator_address");
+CREATE INDEX IF NOT EXISTS "idx_hourly_delegations_chain_id" ON "hourly_delegations" ("chain_id");
+CREATE INDEX IF NOT EXISTS "idx_hourly_delegations_watchlist_id" ON "hourly_delegations" ("watchlist_id");
+CREATE INDEX IF NOT EXISTS "idx_hourly_delegations_timestamp" ON "hourly_delegations" ("timestamp");
+CREATE INDEX IF NOT EXISTS "idx_hourly_delegations_block_height" ON "hourly_delegations" ("block_height");
+CREATE INDEX IF NOT EXISTS "idx_hourly_delegations_exited" ON "hourly_delegations" ("exited");
+CREATE INDEX IF NOT EXISTS "idx_hourly_delegations_delegator_address" ON "hourly_delegations" ("delegator_address");
+
+CREATE TABLE IF NOT EXISTS "daily_delegations" (
+    "id" bigserial,
+    "watchlist_id" bigint,
+    "chain_id" varchar(64) DEFAULT 'cosmoshub-4',
+    "validator_address" text,
+    "delegator_address" text,
+    "total_delegation" numeric,
+    "total_shares" numeric,
+    "block_height" bigint,
+    "block_time" timestamptz,
+    "date" date,
+    "timezone" varchar(64),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_daily_delegations_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+);
+ALTER TABLE "daily_delegations"
+    ADD COLUMN IF NOT EXISTS "watchlist_id" bigint,
+    ADD COLUMN IF NOT EXISTS "chain_id" varchar(64) DEFAULT 'cosmoshub-4',
+    ADD COLUMN IF NOT EXISTS "validator_address" text,
+    ADD COLUMN IF NOT EXISTS "delegator_address" text,
+    ADD COLUMN IF NOT EXISTS "total_delegation" numeric,
+    ADD COLUMN IF NOT EXISTS "total_shares" numeric,
+    ADD COLUMN IF NOT EXISTS "block_height" bigint,
+    ADD COLUMN IF NOT EXISTS "block_time" timestamptz,
+    ADD COLUMN IF NOT EXISTS "date" date,
+    ADD COLUMN IF NOT EXISTS "timezone" varchar(64);
+-- The old boot path stored the day as the time the row was written
+ALTER TABLE "daily_delegations"
+    ALTER COLUMN "total_delegation" TYPE numeric,
+    ALTER COLUMN "total_shares" TYPE numeric,
+    ALTER COLUMN "date" TYPE date;
+CREATE INDEX IF NOT EXISTS "idx_daily_delegations_chain_id" ON "daily_delegations" ("chain_id");
+CREATE INDEX IF NOT EXISTS "idx_daily_delegations_watchlist_id" ON "daily_delegations" ("watchlist_id");
+CREATE INDEX IF NOT EXISTS "idx_daily_delegations_date" ON "daily_delegations" ("date");
+CREATE INDEX IF NOT EXISTS "idx_daily_delegations_block_height" ON "daily_delegations" ("block_height");
+CREATE INDEX IF NOT EXISTS "idx_daily_delegations_delegator_address" ON "daily_delegations" ("delegator_address");
+CREATE INDEX IF NOT EXISTS "idx_daily_delegations_validator_address" ON "daily_delegations" ("validator_address");
+
+CREATE TABLE IF NOT EXISTS "current_delegations" (
+    "id" bigserial,
+    "watchlist_id" bigint,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "delegator_address" text,
+    "delegation_amount" numeric,
+    "shares" numeric,
+    "exited" boolean DEFAULT false,
+    "snapshot_id" bigint,
+    "block_height" bigint,
+    "block_time" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_current_delegations_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_current_delegations_delegator_address" ON "current_delegations" ("delegator_address");
+CREATE INDEX IF NOT EXISTS "idx_current_delegation_amount" ON "current_delegations" ("chain_id","validator_address","delegation_amount");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_current_delegation" ON "current_delegations" ("chain_id","validator_address","delegator_address");
+CREATE INDEX IF NOT EXISTS "idx_current_delegations_watchlist_id" ON "current_delegations" ("watchlist_id");
+CREATE INDEX IF NOT EXISTS "idx_current_delegations_updated_at" ON "current_delegations" ("updated_at");
+
+CREATE TABLE IF NOT EXISTS "collection_heartbeats" (
+    "id" bigserial,
+    "watchlist_id" bigint,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "timestamp" timestamptz,
+    "block_height" bigint,
+    "block_time" timestamptz,
+    "storage_mode" varchar(16),
+    "delegators" bigint,
+    "rows_written" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_collection_heartbeat" ON "collection_heartbeats" ("chain_id","validator_address","timestamp");
+CREATE INDEX IF NOT EXISTS "idx_collection_heartbeats_watchlist_id" ON "collection_heartbeats" ("watchlist_id");
+
+CREATE TABLE IF NOT EXISTS "daily_delegation_rollups" (
+    "id" bigserial,
+    "watchlist_id" bigint,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "period_start" date,
+    "delegator_address" text,
+    "period_end" date,
+    "label" varchar(16),
+    "timezone" varchar(64),
+    "open_amount" numeric,
+    "close_amount" numeric,
+    "min_amount" numeric,
+    "max_amount" numeric,
+    "net_change" numeric,
+    "open_height" bigint,
+    "close_height" bigint,
+    "snapshots" bigint,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_daily_delegation_rollups_delegator_address" ON "daily_delegation_rollups" ("delegator_address");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_daily_delegation_rollups_rollup_period" ON "daily_delegation_rollups" ("chain_id","validator_address","period_start","delegator_address");
+CREATE INDEX IF NOT EXISTS "idx_daily_delegation_rollups_watchlist_id" ON "daily_delegation_rollups" ("watchlist_id");
+
+CREATE TABLE IF NOT EXISTS "weekly_delegation_rollups" (
+    "id" bigserial,
+    "watchlist_id" bigint,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "period_start" date,
+    "delegator_address" text,
+    "period_end" date,
+    "label" varchar(16),
+    "timezone" varchar(64),
+    "open_amount" numeric,
+    "close_amount" numeric,
+    "min_amount" numeric,
+    "max_amount" numeric,
+    "net_change" numeric,
+    "open_height" bigint,
+    "close_height" bigint,
+    "snapshots" bigint,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_weekly_delegation_rollups_delegator_address" ON "weekly_delegation_rollups" ("delegator_address");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_weekly_delegation_rollups_rollup_period" ON "weekly_delegation_rollups" ("chain_id","validator_address","period_start","delegator_address");
+CREATE INDEX IF NOT EXISTS "idx_weekly_delegation_rollups_watchlist_id" ON "weekly_delegation_rollups" ("watchlist_id");
+
+CREATE TABLE IF NOT EXISTS "monthly_delegation_rollups" (
+    "id" bigserial,
+    "watchlist_id" bigint,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "period_start" date,
+    "delegator_address" text,
+    "period_end" date,
+    "label" varchar(16),
+    "timezone" varchar(64),
+    "open_amount" numeric,
+    "close_amount" numeric,
+    "min_amount" numeric,
+    "max_amount" numeric,
+    "net_change" numeric,
+    "open_height" bigint,
+    "close_height" bigint,
+    "snapshots" bigint,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_monthly_delegation_rollups_delegator_address" ON "monthly_delegation_rollups" ("delegator_address");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_monthly_delegation_rollups_rollup_period" ON "monthly_delegation_rollups" ("chain_id","validator_address","period_start","delegator_address");
+CREATE INDEX IF NOT EXISTS "idx_monthly_delegation_rollups_watchlist_id" ON "monthly_delegation_rollups" ("watchlist_id");
+
+CREATE TABLE IF NOT EXISTS "hourly_validator_stats" (
+    "id" bigserial,
+    "watchlist_id" bigint,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "block_height" bigint,
+    "total_delegated" numeric,
+    "delegator_count" bigint,
+    "new_delegators" bigint,
+    "exited_delegators" bigint,
+    "inflow" numeric,
+    "outflow" numeric,
+    "net_flow" numeric,
+    "timestamp" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_hourly_validator_stats_watchlist_id" ON "hourly_validator_stats" ("watchlist_id");
+CREATE INDEX IF NOT EXISTS "idx_hourly_validator_stats_validator_stats" ON "hourly_validator_stats" ("chain_id","validator_address","timestamp");
+
+CREATE TABLE IF NOT EXISTS "daily_validator_stats" (
+    "id" bigserial,
+    "watchlist_id" bigint,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "block_height" bigint,
+    "total_delegated" numeric,
+    "delegator_count" bigint,
+    "new_delegators" bigint,
+    "exited_delegators" bigint,
+    "inflow" numeric,
+    "outflow" numeric,
+    "net_flow" numeric,
+    "date" date,
+    "timezone" varchar(64),
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_daily_validator_stats_validator_stats" ON "daily_validator_stats" ("chain_id","validator_address","date");
+CREATE INDEX IF NOT EXISTS "idx_daily_validator_stats_watchlist_id" ON "daily_validator_stats" ("watchlist_id");
+
+CREATE TABLE IF NOT EXISTS "retention_runs" (
+    "id" bigserial,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "table" varchar(64),
+    "cutoff" timestamptz,
+    "rows_deleted" bigint,
+    "error_message" text,
+    "started_at" timestamptz,
+    "finished_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_retention_runs_started_at" ON "retention_runs" ("started_at");
+CREATE INDEX IF NOT EXISTS "idx_retention_runs_validator_address" ON "retention_runs" ("validator_address");
+CREATE INDEX IF NOT EXISTS "idx_retention_runs_chain_id" ON "retention_runs" ("chain_id");
+
+CREATE TABLE IF NOT EXISTS "unbonding_delegations" (
+    "id" bigserial,
+    "watchlist_id" bigint,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "delegator_address" text,
+    "creation_height" bigint,
+    "completion_time" timestamptz,
+    "initial_balance" numeric,
+    "balance" numeric,
+    "status" varchar(20),
+    "last_seen_height" bigint,
+    "first_seen_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_unbonding_delegations_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_unbonding_delegations_delegator_address" ON "unbonding_delegations" ("delegator_address");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_unbonding_entry" ON "unbonding_delegations" ("chain_id","validator_address","delegator_address","creation_height");
+CREATE INDEX IF NOT EXISTS "idx_unbonding_delegations_watchlist_id" ON "unbonding_delegations" ("watchlist_id");
+CREATE INDEX IF NOT EXISTS "idx_unbonding_delegations_status" ON "unbonding_delegations" ("status");
+CREATE INDEX IF NOT EXISTS "idx_unbonding_delegations_completion_time" ON "unbonding_delegations" ("completion_time");
+
+CREATE TABLE IF NOT EXISTS "redelegations" (
+    "id" bigserial,
+    "chain_id" varchar(64),
+    "tx_hash" varchar(64),
+    "event_index" bigint,
+    "delegator_address" text,
+    "src_validator_address" text,
+    "dst_validator_address" text,
+    "amount" numeric,
+    "denom" varchar(128),
+    "completion_time" timestamptz,
+    "height" bigint,
+    "timestamp" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_redelegation_event" ON "redelegations" ("chain_id","tx_hash","event_index");
+CREATE INDEX IF NOT EXISTS "idx_redelegations_height" ON "redelegations" ("height");
+CREATE INDEX IF NOT EXISTS "idx_redelegations_completion_time" ON "redelegations" ("completion_time");
+CREATE INDEX IF NOT EXISTS "idx_redelegations_delegator_address" ON "redelegations" ("delegator_address");
+CREATE INDEX IF NOT EXISTS "idx_redelegation_dst" ON "redelegations" ("chain_id","dst_validator_address");
+CREATE INDEX IF NOT EXISTS "idx_redelegation_src" ON "redelegations" ("chain_id","src_validator_address");
+
+CREATE TABLE IF NOT EXISTS "validator_snapshots" (
+    "id" bigserial,
+    "watchlist_id" bigint,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "moniker" varchar(255),
+    "website" varchar(255),
+    "tokens" numeric,
+    "delegator_shares" numeric,
+    "commission_rate" decimal,
+    "commission_max_rate" decimal,
+    "commission_max_change_rate" decimal,
+    "jailed" boolean,
+    "status" varchar(40),
+    "voting_power" bigint,
+    "voting_power_share" decimal,
+    "block_height" bigint,
+    "block_time" timestamptz,
+    "timestamp" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_validator_snapshots_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_validator_snapshots_block_height" ON "validator_snapshots" ("block_height");
+CREATE INDEX IF NOT EXISTS "idx_validator_snapshot" ON "validator_snapshots" ("chain_id","validator_address","timestamp");
+CREATE INDEX IF NOT EXISTS "idx_validator_snapshots_watchlist_id" ON "validator_snapshots" ("watchlist_id");
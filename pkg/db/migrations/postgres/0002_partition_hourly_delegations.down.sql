@@ -0,0 +1,44 @@
+-- Folds the monthly partitions back into one plain hourly_delegations table with the
+-- baseline primary key and indexes.
+
+CREATE TABLE "hourly_delegations_unpartitioned" (
+    "id" bigint NOT NULL DEFAULT nextval('hourly_delegations_id_seq'),
+    "watchlist_id" bigint,
+    "chain_id" varchar(64) DEFAULT 'cosmoshub-4',
+    "validator_address" text,
+    "delegator_address" text,
+    "delegation_amount" numeric,
+    "change_amount" numeric,
+    "shares" numeric,
+    "exited" boolean DEFAULT false,
+    "returned" boolean DEFAULT false,
+    "block_height" bigint,
+    "block_time" timestamptz,
+    "timestamp" timestamptz,
+    CONSTRAINT "hourly_delegations_unpartitioned_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "fk_hourly_delegations_unpartitioned_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+);
+
+INSERT INTO "hourly_delegations_unpartitioned" (
+    "id", "watchlist_id", "chain_id", "validator_address", "delegator_address", "delegation_amount",
+    "change_amount", "shares", "exited", "returned", "block_height", "block_time", "timestamp"
+)
+SELECT
+    "id", "watchlist_id", "chain_id", "validator_address", "delegator_address", "delegation_amount",
+    "change_amount", "shares", "exited", "returned", "block_height", "block_time", "timestamp"
+FROM "hourly_delegations";
+
+ALTER SEQUENCE "hourly_delegations_id_seq" OWNED BY "hourly_delegations_unpartitioned"."id";
+DROP TABLE "hourly_delegations";
+DROP FUNCTION "create_hourly_delegation_partition"(date);
+
+ALTER TABLE "hourly_delegations_unpartitioned" RENAME TO "hourly_delegations";
+ALTER TABLE "hourly_delegations" RENAME CONSTRAINT "hourly_delegations_unpartitioned_pkey" TO "hourly_delegations_pkey";
+ALTER TABLE "hourly_delegations" RENAME CONSTRAINT "fk_hourly_delegations_unpartitioned_watchlist" TO "fk_hourly_delegations_watchlist";
+CREATE INDEX "idx_hourly_delegations_validator_address" ON "hourly_delegations" ("validator_address");
+CREATE INDEX "idx_hourly_delegations_chain_id" ON "hourly_delegations" ("chain_id");
+CREATE INDEX "idx_hourly_delegations_watchlist_id" ON "hourly_delegations" ("watchlist_id");
+CREATE INDEX "idx_hourly_delegations_timestamp" ON "hourly_delegations" ("timestamp");
+CREATE INDEX "idx_hourly_delegations_block_height" ON "hourly_delegations" ("block_height");
+CREATE INDEX "idx_hourly_delegations_exited" ON "hourly_delegations" ("exited");
+CREATE INDEX "idx_hourly_delegations_delegator_address" ON "hourly_delegations" ("delegator_address");
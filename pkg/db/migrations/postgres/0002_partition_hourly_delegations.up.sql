@@ -0,0 +1,87 @@
+-- Rebuilds hourly_delegations as a table range-partitioned by month on "timestamp", so
+-- time-bounded reads only scan the months they cover and retention can drop whole months.
+-- Unique constraints must include the partition key, so the primary key becomes (id, timestamp).
+-- Partitions are named hourly_delegations_pYYYYMM; the server creates future ones through
+-- create_hourly_delegation_partition.
+
+ALTER TABLE "hourly_delegations" RENAME TO "hourly_delegations_unpartitioned";
+ALTER TABLE "hourly_delegations_unpartitioned" RENAME CONSTRAINT "hourly_delegations_pkey" TO "hourly_delegations_unpartitioned_pkey";
+ALTER TABLE "hourly_delegations_unpartitioned" RENAME CONSTRAINT "fk_hourly_delegations_watchlist" TO "fk_hourly_delegations_unpartitioned_watchlist";
+DROP INDEX IF EXISTS "idx_hourly_delegations_validator_address";
+DROP INDEX IF EXISTS "idx_hourly_delegations_chain_id";
+DROP INDEX IF EXISTS "idx_hourly_delegations_watchlist_id";
+DROP INDEX IF EXISTS "idx_hourly_delegations_timestamp";
+DROP INDEX IF EXISTS "idx_hourly_delegations_block_height";
+DROP INDEX IF EXISTS "idx_hourly_delegations_exited";
+DROP INDEX IF EXISTS "idx_hourly_delegations_delegator_address";
+
+CREATE TABLE "hourly_delegations" (
+    "id" bigint NOT NULL DEFAULT nextval('hourly_delegations_id_seq'),
+    "watchlist_id" bigint,
+    "chain_id" varchar(64) DEFAULT 'cosmoshub-4',
+    "validator_address" text,
+    "delegator_address" text,
+    "delegation_amount" numeric,
+    "change_amount" numeric,
+    "shares" numeric,
+    "exited" boolean DEFAULT false,
+    "returned" boolean DEFAULT false,
+    "block_height" bigint,
+    "block_time" timestamptz,
+    "timestamp" timestamptz NOT NULL,
+    PRIMARY KEY ("id", "timestamp"),
+    CONSTRAINT "fk_hourly_delegations_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+) PARTITION BY RANGE ("timestamp");
+ALTER SEQUENCE "hourly_delegations_id_seq" OWNED BY "hourly_delegations"."id";
+
+-- Paginated reads and the aggregation filter on validator and order by time, so one composite
+-- index per access path replaces the single-column ones
+CREATE INDEX "idx_hourly_delegations_validator_time" ON "hourly_delegations" ("chain_id", "validator_address", "timestamp", "id");
+CREATE INDEX "idx_hourly_delegations_delegator_time" ON "hourly_delegations" ("chain_id", "validator_address", "delegator_address", "timestamp", "id");
+CREATE INDEX "idx_hourly_delegations_watchlist_id" ON "hourly_delegations" ("watchlist_id");
+CREATE INDEX "idx_hourly_delegations_block_height" ON "hourly_delegations" ("block_height");
+
+-- Creates the partition holding one UTC calendar month, if missing, and returns its name
+CREATE OR REPLACE FUNCTION "create_hourly_delegation_partition"(for_month date) RETURNS text AS $$
+DECLARE
+    month_start timestamp := date_trunc('month', for_month::timestamp);
+    partition_name text := 'hourly_delegations_p' || to_char(month_start, 'YYYYMM');
+BEGIN
+    EXECUTE format(
+        'CREATE TABLE IF NOT EXISTS %I PARTITION OF "hourly_delegations" FOR VALUES FROM (%L) TO (%L)',
+        partition_name,
+        month_start AT TIME ZONE 'UTC',
+        (month_start + interval '1 month') AT TIME ZONE 'UTC'
+    );
+    RETURN partition_name;
+END;
+$$ LANGUAGE plpgsql;
+
+-- One partition per month from the oldest row through three months past the newest row or now
+DO $$
+DECLARE
+    partition_month date;
+    last_month date;
+BEGIN
+    SELECT
+        date_trunc('month', COALESCE(MIN("timestamp"), now()) AT TIME ZONE 'UTC')::date,
+        (date_trunc('month', GREATEST(COALESCE(MAX("timestamp"), now()), now()) AT TIME ZONE 'UTC') + interval '3 months')::date
+    INTO partition_month, last_month
+    FROM "hourly_delegations_unpartitioned";
+
+    WHILE partition_month <= last_month LOOP
+        PERFORM "create_hourly_delegation_partition"(partition_month);
+        partition_month := (partition_month + interval '1 month')::date;
+    END LOOP;
+END $$;
+
+INSERT INTO "hourly_delegations" (
+    "id", "watchlist_id", "chain_id", "validator_address", "delegator_address", "delegation_amount",
+    "change_amount", "shares", "exited", "returned", "block_height", "block_time", "timestamp"
+)
+SELECT
+    "id", "watchlist_id", "chain_id", "validator_address", "delegator_address", "delegation_amount",
+    "change_amount", "shares", "exited", "returned", "block_height", "block_time", "timestamp"
+FROM "hourly_delegations_unpartitioned";
+
+DROP TABLE "hourly_delegations_unpartitioned";
@@ -0,0 +1,23 @@
+-- Tags snapshot rows and heartbeats with the collection run that wrote them and adds unique keys
+-- so that repeating a run or a daily aggregation pass never duplicates rows. Rows written before
+-- this version keep a NULL run ID, which never conflicts.
+
+ALTER TABLE "hourly_delegations" ADD COLUMN "run_id" varchar(32);
+ALTER TABLE "collection_heartbeats" ADD COLUMN "run_id" varchar(32);
+
+-- Partitioned tables need the partition key in every unique index, so the snapshot key includes
+-- "timestamp"; the heartbeat key is what stops a second write of the same run.
+CREATE UNIQUE INDEX "idx_hourly_delegations_run" ON "hourly_delegations" ("chain_id", "validator_address", "delegator_address", "run_id", "timestamp");
+CREATE UNIQUE INDEX "idx_collection_heartbeats_run" ON "collection_heartbeats" ("chain_id", "validator_address", "run_id");
+
+-- Concurrent aggregation passes may have written a day twice; keep the newest row of each
+DELETE FROM "daily_delegations"
+WHERE EXISTS (
+    SELECT 1 FROM "daily_delegations" newer
+    WHERE newer."chain_id" = "daily_delegations"."chain_id"
+        AND newer."validator_address" = "daily_delegations"."validator_address"
+        AND newer."delegator_address" = "daily_delegations"."delegator_address"
+        AND newer."date" = "daily_delegations"."date"
+        AND newer."id" > "daily_delegations"."id"
+);
+CREATE UNIQUE INDEX "idx_daily_delegations_delegator_date" ON "daily_delegations" ("chain_id", "validator_address", "delegator_address", "date");
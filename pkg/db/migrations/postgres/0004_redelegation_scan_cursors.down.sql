@@ -0,0 +1,3 @@
+-- Drops the scan cursors; scans fall back to starting from the first block.
+
+DROP TABLE IF EXISTS "redelegation_scan_cursors";
@@ -0,0 +1,15 @@
+-- Records how far tx search has been scanned for each watched validator and redelegation
+-- direction. Scans run oldest first from the cursor, so a run that stops at its page cap resumes
+-- where it left off. Validators without a cursor are rescanned from the first block, which fills
+-- gaps left by the earlier newest-first scan; redelegations already stored are skipped.
+
+CREATE TABLE IF NOT EXISTS "redelegation_scan_cursors" (
+    "id" bigserial,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "direction" varchar(8),
+    "scanned_height" bigint,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_redelegation_scan_cursor" ON "redelegation_scan_cursors" ("chain_id","validator_address","direction");
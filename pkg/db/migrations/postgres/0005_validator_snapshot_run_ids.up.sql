@@ -0,0 +1,9 @@
+-- Tags validator snapshots with the collection run that wrote them and adds a unique key, so a
+-- repeated run stores one snapshot per validator like the delegation snapshots. Rows written
+-- before this version keep a NULL run ID, which never conflicts.
+--
+-- Unbonding entries and redelegations need no run ID: they are keyed by the chain's own
+-- identifiers (delegator and creation height, tx hash and event index) and written idempotently.
+
+ALTER TABLE "validator_snapshots" ADD COLUMN "run_id" varchar(32);
+CREATE UNIQUE INDEX "idx_validator_snapshots_run" ON "validator_snapshots" ("chain_id", "validator_address", "run_id");
@@ -0,0 +1,5 @@
+-- Restores the original decimal declaration of the commission rate columns.
+
+ALTER TABLE "validator_snapshots" ALTER COLUMN "commission_rate" TYPE decimal;
+ALTER TABLE "validator_snapshots" ALTER COLUMN "commission_max_rate" TYPE decimal;
+ALTER TABLE "validator_snapshots" ALTER COLUMN "commission_max_change_rate" TYPE decimal;
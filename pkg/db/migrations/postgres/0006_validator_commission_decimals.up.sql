@@ -0,0 +1,7 @@
+-- Stores validator commission rates as exact numeric decimals, like delegator shares, instead of
+-- floats. Postgres decimal columns already are numeric; the type is restated so both backends
+-- declare the same column type the model does.
+
+ALTER TABLE "validator_snapshots" ALTER COLUMN "commission_rate" TYPE numeric;
+ALTER TABLE "validator_snapshots" ALTER COLUMN "commission_max_rate" TYPE numeric;
+ALTER TABLE "validator_snapshots" ALTER COLUMN "commission_max_change_rate" TYPE numeric;
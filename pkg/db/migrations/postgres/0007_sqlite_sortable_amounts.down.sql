@@ -0,0 +1,3 @@
+-- Nothing to undo on Postgres.
+
+SELECT 1;
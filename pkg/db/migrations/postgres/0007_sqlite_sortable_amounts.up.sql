@@ -0,0 +1,5 @@
+-- Stores amounts as sortable text on SQLite, whose numbers lose precision beyond 64-bit integers.
+-- Postgres numeric columns are already exact, so nothing changes here; the version exists so both
+-- backends share one migration history.
+
+SELECT 1;
@@ -0,0 +1,285 @@
+-- Baseline schema for SQLite, matching the Postgres baseline column for column.
+-- SQLite compares the text it stores times as, so the connection writes every time in UTC.
+
+CREATE TABLE "watchlists" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "chain_id" varchar(64) DEFAULT 'cosmoshub-4',
+    "validator_address" text,
+    "validator_name" varchar(100)
+);
+CREATE INDEX "idx_watchlists_chain_id" ON "watchlists" ("chain_id");
+CREATE INDEX "idx_watchlists_validator_address" ON "watchlists" ("validator_address");
+
+CREATE TABLE "hourly_delegations" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "watchlist_id" integer,
+    "chain_id" varchar(64) DEFAULT 'cosmoshub-4',
+    "validator_address" text,
+    "delegator_address" text,
+    "delegation_amount" numeric,
+    "change_amount" numeric,
+    "shares" numeric,
+    "exited" numeric DEFAULT false,
+    "returned" numeric DEFAULT false,
+    "block_height" integer,
+    "block_time" datetime,
+    "timestamp" datetime,
+    CONSTRAINT "fk_hourly_delegations_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+);
+CREATE INDEX "idx_hourly_delegations_watchlist_id" ON "hourly_delegations" ("watchlist_id");
+CREATE INDEX "idx_hourly_delegations_timestamp" ON "hourly_delegations" ("timestamp");
+CREATE INDEX "idx_hourly_delegations_block_height" ON "hourly_delegations" ("block_height");
+CREATE INDEX "idx_hourly_delegations_exited" ON "hourly_delegations" ("exited");
+CREATE INDEX "idx_hourly_delegations_delegator_address" ON "hourly_delegations" ("delegator_address");
+CREATE INDEX "idx_hourly_delegations_validator_address" ON "hourly_delegations" ("validator_address");
+CREATE INDEX "idx_hourly_delegations_chain_id" ON "hourly_delegations" ("chain_id");
+
+CREATE TABLE "daily_delegations" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "watchlist_id" integer,
+    "chain_id" varchar(64) DEFAULT 'cosmoshub-4',
+    "validator_address" text,
+    "delegator_address" text,
+    "total_delegation" numeric,
+    "total_shares" numeric,
+    "block_height" integer,
+    "block_time" datetime,
+    "date" date,
+    "timezone" varchar(64),
+    CONSTRAINT "fk_daily_delegations_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+);
+CREATE INDEX "idx_daily_delegations_block_height" ON "daily_delegations" ("block_height");
+CREATE INDEX "idx_daily_delegations_delegator_address" ON "daily_delegations" ("delegator_address");
+CREATE INDEX "idx_daily_delegations_validator_address" ON "daily_delegations" ("validator_address");
+CREATE INDEX "idx_daily_delegations_chain_id" ON "daily_delegations" ("chain_id");
+CREATE INDEX "idx_daily_delegations_watchlist_id" ON "daily_delegations" ("watchlist_id");
+CREATE INDEX "idx_daily_delegations_date" ON "daily_delegations" ("date");
+
+CREATE TABLE "current_delegations" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "watchlist_id" integer,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "delegator_address" text,
+    "delegation_amount" numeric,
+    "shares" numeric,
+    "exited" numeric DEFAULT false,
+    "snapshot_id" integer,
+    "block_height" integer,
+    "block_time" datetime,
+    "updated_at" datetime,
+    CONSTRAINT "fk_current_delegations_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+);
+CREATE INDEX "idx_current_delegations_delegator_address" ON "current_delegations" ("delegator_address");
+CREATE INDEX "idx_current_delegation_amount" ON "current_delegations" ("chain_id","validator_address","delegation_amount");
+CREATE UNIQUE INDEX "idx_current_delegation" ON "current_delegations" ("chain_id","validator_address","delegator_address");
+CREATE INDEX "idx_current_delegations_watchlist_id" ON "current_delegations" ("watchlist_id");
+CREATE INDEX "idx_current_delegations_updated_at" ON "current_delegations" ("updated_at");
+
+CREATE TABLE "collection_heartbeats" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "watchlist_id" integer,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "timestamp" datetime,
+    "block_height" integer,
+    "block_time" datetime,
+    "storage_mode" varchar(16),
+    "delegators" integer,
+    "rows_written" integer
+);
+CREATE INDEX "idx_collection_heartbeat" ON "collection_heartbeats" ("chain_id","validator_address","timestamp");
+CREATE INDEX "idx_collection_heartbeats_watchlist_id" ON "collection_heartbeats" ("watchlist_id");
+
+CREATE TABLE "daily_delegation_rollups" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "watchlist_id" integer,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "period_start" date,
+    "delegator_address" text,
+    "period_end" date,
+    "label" varchar(16),
+    "timezone" varchar(64),
+    "open_amount" numeric,
+    "close_amount" numeric,
+    "min_amount" numeric,
+    "max_amount" numeric,
+    "net_change" numeric,
+    "open_height" integer,
+    "close_height" integer,
+    "snapshots" integer,
+    "updated_at" datetime
+);
+CREATE INDEX "idx_daily_delegation_rollups_delegator_address" ON "daily_delegation_rollups" ("delegator_address");
+CREATE UNIQUE INDEX "idx_daily_delegation_rollups_rollup_period" ON "daily_delegation_rollups" ("chain_id","validator_address","period_start","delegator_address");
+CREATE INDEX "idx_daily_delegation_rollups_watchlist_id" ON "daily_delegation_rollups" ("watchlist_id");
+
+CREATE TABLE "weekly_delegation_rollups" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "watchlist_id" integer,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "period_start" date,
+    "delegator_address" text,
+    "period_end" date,
+    "label" varchar(16),
+    "timezone" varchar(64),
+    "open_amount" numeric,
+    "close_amount" numeric,
+    "min_amount" numeric,
+    "max_amount" numeric,
+    "net_change" numeric,
+    "open_height" integer,
+    "close_height" integer,
+    "snapshots" integer,
+    "updated_at" datetime
+);
+CREATE INDEX "idx_weekly_delegation_rollups_delegator_address" ON "weekly_delegation_rollups" ("delegator_address");
+CREATE UNIQUE INDEX "idx_weekly_delegation_rollups_rollup_period" ON "weekly_delegation_rollups" ("chain_id","validator_address","period_start","delegator_address");
+CREATE INDEX "idx_weekly_delegation_rollups_watchlist_id" ON "weekly_delegation_rollups" ("watchlist_id");
+
+CREATE TABLE "monthly_delegation_rollups" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "watchlist_id" integer,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "period_start" date,
+    "delegator_address" text,
+    "period_end" date,
+    "label" varchar(16),
+    "timezone" varchar(64),
+    "open_amount" numeric,
+    "close_amount" numeric,
+    "min_amount" numeric,
+    "max_amount" numeric,
+    "net_change" numeric,
+    "open_height" integer,
+    "close_height" integer,
+    "snapshots" integer,
+    "updated_at" datetime
+);
+CREATE UNIQUE INDEX "idx_monthly_delegation_rollups_rollup_period" ON "monthly_delegation_rollups" ("chain_id","validator_address","period_start","delegator_address");
+CREATE INDEX "idx_monthly_delegation_rollups_watchlist_id" ON "monthly_delegation_rollups" ("watchlist_id");
+CREATE INDEX "idx_monthly_delegation_rollups_delegator_address" ON "monthly_delegation_rollups" ("delegator_address");
+
+CREATE TABLE "hourly_validator_stats" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "watchlist_id" integer,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "block_height" integer,
+    "total_delegated" numeric,
+    "delegator_count" integer,
+    "new_delegators" integer,
+    "exited_delegators" integer,
+    "inflow" numeric,
+    "outflow" numeric,
+    "net_flow" numeric,
+    "timestamp" datetime
+);
+CREATE INDEX "idx_hourly_validator_stats_validator_stats" ON "hourly_validator_stats" ("chain_id","validator_address","timestamp");
+CREATE INDEX "idx_hourly_validator_stats_watchlist_id" ON "hourly_validator_stats" ("watchlist_id");
+
+CREATE TABLE "daily_validator_stats" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "watchlist_id" integer,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "block_height" integer,
+    "total_delegated" numeric,
+    "delegator_count" integer,
+    "new_delegators" integer,
+    "exited_delegators" integer,
+    "inflow" numeric,
+    "outflow" numeric,
+    "net_flow" numeric,
+    "date" date,
+    "timezone" varchar(64)
+);
+CREATE INDEX "idx_daily_validator_stats_validator_stats" ON "daily_validator_stats" ("chain_id","validator_address","date");
+CREATE INDEX "idx_daily_validator_stats_watchlist_id" ON "daily_validator_stats" ("watchlist_id");
+
+CREATE TABLE "retention_runs" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "table" varchar(64),
+    "cutoff" datetime,
+    "rows_deleted" integer,
+    "error_message" text,
+    "started_at" datetime,
+    "finished_at" datetime
+);
+CREATE INDEX "idx_retention_runs_started_at" ON "retention_runs" ("started_at");
+CREATE INDEX "idx_retention_runs_validator_address" ON "retention_runs" ("validator_address");
+CREATE INDEX "idx_retention_runs_chain_id" ON "retention_runs" ("chain_id");
+
+CREATE TABLE "unbonding_delegations" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "watchlist_id" integer,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "delegator_address" text,
+    "creation_height" integer,
+    "completion_time" datetime,
+    "initial_balance" numeric,
+    "balance" numeric,
+    "status" varchar(20),
+    "last_seen_height" integer,
+    "first_seen_at" datetime,
+    "updated_at" datetime,
+    CONSTRAINT "fk_unbonding_delegations_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+);
+CREATE INDEX "idx_unbonding_delegations_status" ON "unbonding_delegations" ("status");
+CREATE INDEX "idx_unbonding_delegations_completion_time" ON "unbonding_delegations" ("completion_time");
+CREATE INDEX "idx_unbonding_delegations_delegator_address" ON "unbonding_delegations" ("delegator_address");
+CREATE UNIQUE INDEX "idx_unbonding_entry" ON "unbonding_delegations" ("chain_id","validator_address","delegator_address","creation_height");
+CREATE INDEX "idx_unbonding_delegations_watchlist_id" ON "unbonding_delegations" ("watchlist_id");
+
+CREATE TABLE "redelegations" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "chain_id" varchar(64),
+    "tx_hash" varchar(64),
+    "event_index" integer,
+    "delegator_address" text,
+    "src_validator_address" text,
+    "dst_validator_address" text,
+    "amount" numeric,
+    "denom" varchar(128),
+    "completion_time" datetime,
+    "height" integer,
+    "timestamp" datetime,
+    "created_at" datetime
+);
+CREATE INDEX "idx_redelegation_dst" ON "redelegations" ("chain_id","dst_validator_address");
+CREATE INDEX "idx_redelegation_src" ON "redelegations" ("chain_id","src_validator_address");
+CREATE UNIQUE INDEX "idx_redelegation_event" ON "redelegations" ("chain_id","tx_hash","event_index");
+CREATE INDEX "idx_redelegations_height" ON "redelegations" ("height");
+CREATE INDEX "idx_redelegations_completion_time" ON "redelegations" ("completion_time");
+CREATE INDEX "idx_redelegations_delegator_address" ON "redelegations" ("delegator_address");
+
+CREATE TABLE "validator_snapshots" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "watchlist_id" integer,
+    "chain_id" varchar(64),
+    "validator_address" text,
+    "moniker" varchar(255),
+    "website" varchar(255),
+    "tokens" numeric,
+    "delegator_shares" numeric,
+    "commission_rate" real,
+    "commission_max_rate" real,
+    "commission_max_change_rate" real,
+    "jailed" numeric,
+    "status" varchar(40),
+    "voting_power" integer,
+    "voting_power_share" real,
+    "block_height" integer,
+    "block_time" datetime,
+    "timestamp" datetime,
+    CONSTRAINT "fk_validator_snapshots_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+);
+CREATE INDEX "idx_validator_snapshots_block_height" ON "validator_snapshots" ("block_height");
+CREATE INDEX "idx_validator_snapshot" ON "validator_snapshots" ("chain_id","validator_address","timestamp");
+CREATE INDEX "idx_validator_snapshots_watchlist_id" ON "validator_snapshots" ("watchlist_id");
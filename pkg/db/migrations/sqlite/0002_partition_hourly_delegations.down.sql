@@ -0,0 +1,9 @@
+-- Restores the baseline single-column indexes.
+
+DROP INDEX "idx_hourly_delegations_validator_time";
+DROP INDEX "idx_hourly_delegations_delegator_time";
+CREATE INDEX "idx_hourly_delegations_validator_address" ON "hourly_delegations" ("validator_address");
+CREATE INDEX "idx_hourly_delegations_chain_id" ON "hourly_delegations" ("chain_id");
+CREATE INDEX "idx_hourly_delegations_timestamp" ON "hourly_delegations" ("timestamp");
+CREATE INDEX "idx_hourly_delegations_exited" ON "hourly_delegations" ("exited");
+CREATE INDEX "idx_hourly_delegations_delegator_address" ON "hourly_delegations" ("delegator_address");
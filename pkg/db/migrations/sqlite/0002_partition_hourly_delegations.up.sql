@@ -0,0 +1,10 @@
+-- SQLite has no table partitioning; it only gets the composite indexes that the
+-- partitioned Postgres table uses in place of the single-column ones.
+
+DROP INDEX "idx_hourly_delegations_validator_address";
+DROP INDEX "idx_hourly_delegations_chain_id";
+DROP INDEX "idx_hourly_delegations_timestamp";
+DROP INDEX "idx_hourly_delegations_exited";
+DROP INDEX "idx_hourly_delegations_delegator_address";
+CREATE INDEX "idx_hourly_delegations_validator_time" ON "hourly_delegations" ("chain_id", "validator_address", "timestamp", "id");
+CREATE INDEX "idx_hourly_delegations_delegator_time" ON "hourly_delegations" ("chain_id", "validator_address", "delegator_address", "timestamp", "id");
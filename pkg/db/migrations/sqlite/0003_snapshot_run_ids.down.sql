@@ -0,0 +1,7 @@
+-- Drops the run IDs and unique keys; duplicates removed by the up script are not restored.
+
+DROP INDEX "idx_daily_delegations_delegator_date";
+DROP INDEX "idx_collection_heartbeats_run";
+DROP INDEX "idx_hourly_delegations_run";
+ALTER TABLE "collection_heartbeats" DROP COLUMN "run_id";
+ALTER TABLE "hourly_delegations" DROP COLUMN "run_id";
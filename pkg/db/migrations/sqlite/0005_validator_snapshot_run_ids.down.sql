@@ -0,0 +1,4 @@
+-- Drops the validator snapshot run IDs and their unique key.
+
+DROP INDEX "idx_validator_snapshots_run";
+ALTER TABLE "validator_snapshots" DROP COLUMN "run_id";
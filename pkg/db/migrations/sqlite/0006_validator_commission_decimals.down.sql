@@ -0,0 +1,15 @@
+-- Moves the commission rates back into real columns.
+
+ALTER TABLE "validator_snapshots" ADD COLUMN "commission_rate_real" real;
+ALTER TABLE "validator_snapshots" ADD COLUMN "commission_max_rate_real" real;
+ALTER TABLE "validator_snapshots" ADD COLUMN "commission_max_change_rate_real" real;
+UPDATE "validator_snapshots" SET
+    "commission_rate_real" = "commission_rate",
+    "commission_max_rate_real" = "commission_max_rate",
+    "commission_max_change_rate_real" = "commission_max_change_rate";
+ALTER TABLE "validator_snapshots" DROP COLUMN "commission_rate";
+ALTER TABLE "validator_snapshots" DROP COLUMN "commission_max_rate";
+ALTER TABLE "validator_snapshots" DROP COLUMN "commission_max_change_rate";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "commission_rate_real" TO "commission_rate";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "commission_max_rate_real" TO "commission_max_rate";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "commission_max_change_rate_real" TO "commission_max_change_rate";
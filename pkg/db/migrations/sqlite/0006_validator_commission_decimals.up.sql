@@ -0,0 +1,17 @@
+-- Stores validator commission rates as exact numeric decimals, like delegator shares, instead of
+-- floats. SQLite can't change a column's type in place, so each rate is copied into a numeric
+-- column that then takes the old column's name.
+
+ALTER TABLE "validator_snapshots" ADD COLUMN "commission_rate_dec" numeric;
+ALTER TABLE "validator_snapshots" ADD COLUMN "commission_max_rate_dec" numeric;
+ALTER TABLE "validator_snapshots" ADD COLUMN "commission_max_change_rate_dec" numeric;
+UPDATE "validator_snapshots" SET
+    "commission_rate_dec" = "commission_rate",
+    "commission_max_rate_dec" = "commission_max_rate",
+    "commission_max_change_rate_dec" = "commission_max_change_rate";
+ALTER TABLE "validator_snapshots" DROP COLUMN "commission_rate";
+ALTER TABLE "validator_snapshots" DROP COLUMN "commission_max_rate";
+ALTER TABLE "validator_snapshots" DROP COLUMN "commission_max_change_rate";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "commission_rate_dec" TO "commission_rate";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "commission_max_rate_dec" TO "commission_max_rate";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "commission_max_change_rate_dec" TO "commission_max_change_rate";
@@ -0,0 +1,395 @@
+-- Moves amounts, shares and commission rates back into numeric columns. Negative values are
+-- complemented back to their digits first; values beyond 64-bit integers become floating point again.
+
+ALTER TABLE "hourly_delegations" ADD COLUMN "delegation_amount_numeric" numeric;
+ALTER TABLE "hourly_delegations" ADD COLUMN "change_amount_numeric" numeric;
+ALTER TABLE "hourly_delegations" ADD COLUMN "shares_numeric" numeric;
+UPDATE "hourly_delegations" SET "delegation_amount" = 'n' || printf('%016d', 9999999999999999 - substr("delegation_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("delegation_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("delegation_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("delegation_amount", 50, 16))
+    WHERE "delegation_amount" LIKE 'n%';
+UPDATE "hourly_delegations" SET "delegation_amount_numeric" = CASE WHEN "delegation_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("delegation_amount", 2)
+    WHERE "delegation_amount" IS NOT NULL;
+UPDATE "hourly_delegations" SET "change_amount" = 'n' || printf('%016d', 9999999999999999 - substr("change_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("change_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("change_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("change_amount", 50, 16))
+    WHERE "change_amount" LIKE 'n%';
+UPDATE "hourly_delegations" SET "change_amount_numeric" = CASE WHEN "change_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("change_amount", 2)
+    WHERE "change_amount" IS NOT NULL;
+UPDATE "hourly_delegations" SET "shares" = 'n' || printf('%016d', 9999999999999999 - substr("shares", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("shares", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("shares", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("shares", 50, 16))
+    WHERE "shares" LIKE 'n%';
+UPDATE "hourly_delegations" SET "shares_numeric" = CASE WHEN "shares" LIKE 'n%' THEN '-' ELSE '' END || substr("shares", 2, 46) || '.' || substr("shares", 48)
+    WHERE "shares" IS NOT NULL;
+ALTER TABLE "hourly_delegations" DROP COLUMN "delegation_amount";
+ALTER TABLE "hourly_delegations" DROP COLUMN "change_amount";
+ALTER TABLE "hourly_delegations" DROP COLUMN "shares";
+ALTER TABLE "hourly_delegations" RENAME COLUMN "delegation_amount_numeric" TO "delegation_amount";
+ALTER TABLE "hourly_delegations" RENAME COLUMN "change_amount_numeric" TO "change_amount";
+ALTER TABLE "hourly_delegations" RENAME COLUMN "shares_numeric" TO "shares";
+
+ALTER TABLE "daily_delegations" ADD COLUMN "total_delegation_numeric" numeric;
+ALTER TABLE "daily_delegations" ADD COLUMN "total_shares_numeric" numeric;
+UPDATE "daily_delegations" SET "total_delegation" = 'n' || printf('%016d', 9999999999999999 - substr("total_delegation", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegation", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegation", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegation", 50, 16))
+    WHERE "total_delegation" LIKE 'n%';
+UPDATE "daily_delegations" SET "total_delegation_numeric" = CASE WHEN "total_delegation" LIKE 'n%' THEN '-' ELSE '' END || substr("total_delegation", 2)
+    WHERE "total_delegation" IS NOT NULL;
+UPDATE "daily_delegations" SET "total_shares" = 'n' || printf('%016d', 9999999999999999 - substr("total_shares", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("total_shares", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("total_shares", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("total_shares", 50, 16))
+    WHERE "total_shares" LIKE 'n%';
+UPDATE "daily_delegations" SET "total_shares_numeric" = CASE WHEN "total_shares" LIKE 'n%' THEN '-' ELSE '' END || substr("total_shares", 2, 46) || '.' || substr("total_shares", 48)
+    WHERE "total_shares" IS NOT NULL;
+ALTER TABLE "daily_delegations" DROP COLUMN "total_delegation";
+ALTER TABLE "daily_delegations" DROP COLUMN "total_shares";
+ALTER TABLE "daily_delegations" RENAME COLUMN "total_delegation_numeric" TO "total_delegation";
+ALTER TABLE "daily_delegations" RENAME COLUMN "total_shares_numeric" TO "total_shares";
+
+DROP INDEX "idx_current_delegation_amount";
+ALTER TABLE "current_delegations" ADD COLUMN "delegation_amount_numeric" numeric;
+ALTER TABLE "current_delegations" ADD COLUMN "shares_numeric" numeric;
+UPDATE "current_delegations" SET "delegation_amount" = 'n' || printf('%016d', 9999999999999999 - substr("delegation_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("delegation_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("delegation_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("delegation_amount", 50, 16))
+    WHERE "delegation_amount" LIKE 'n%';
+UPDATE "current_delegations" SET "delegation_amount_numeric" = CASE WHEN "delegation_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("delegation_amount", 2)
+    WHERE "delegation_amount" IS NOT NULL;
+UPDATE "current_delegations" SET "shares" = 'n' || printf('%016d', 9999999999999999 - substr("shares", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("shares", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("shares", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("shares", 50, 16))
+    WHERE "shares" LIKE 'n%';
+UPDATE "current_delegations" SET "shares_numeric" = CASE WHEN "shares" LIKE 'n%' THEN '-' ELSE '' END || substr("shares", 2, 46) || '.' || substr("shares", 48)
+    WHERE "shares" IS NOT NULL;
+ALTER TABLE "current_delegations" DROP COLUMN "delegation_amount";
+ALTER TABLE "current_delegations" DROP COLUMN "shares";
+ALTER TABLE "current_delegations" RENAME COLUMN "delegation_amount_numeric" TO "delegation_amount";
+ALTER TABLE "current_delegations" RENAME COLUMN "shares_numeric" TO "shares";
+CREATE INDEX "idx_current_delegation_amount" ON "current_delegations" ("chain_id","validator_address","delegation_amount");
+
+ALTER TABLE "daily_delegation_rollups" ADD COLUMN "open_amount_numeric" numeric;
+ALTER TABLE "daily_delegation_rollups" ADD COLUMN "close_amount_numeric" numeric;
+ALTER TABLE "daily_delegation_rollups" ADD COLUMN "min_amount_numeric" numeric;
+ALTER TABLE "daily_delegation_rollups" ADD COLUMN "max_amount_numeric" numeric;
+ALTER TABLE "daily_delegation_rollups" ADD COLUMN "net_change_numeric" numeric;
+UPDATE "daily_delegation_rollups" SET "open_amount" = 'n' || printf('%016d', 9999999999999999 - substr("open_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount", 50, 16))
+    WHERE "open_amount" LIKE 'n%';
+UPDATE "daily_delegation_rollups" SET "open_amount_numeric" = CASE WHEN "open_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("open_amount", 2)
+    WHERE "open_amount" IS NOT NULL;
+UPDATE "daily_delegation_rollups" SET "close_amount" = 'n' || printf('%016d', 9999999999999999 - substr("close_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount", 50, 16))
+    WHERE "close_amount" LIKE 'n%';
+UPDATE "daily_delegation_rollups" SET "close_amount_numeric" = CASE WHEN "close_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("close_amount", 2)
+    WHERE "close_amount" IS NOT NULL;
+UPDATE "daily_delegation_rollups" SET "min_amount" = 'n' || printf('%016d', 9999999999999999 - substr("min_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount", 50, 16))
+    WHERE "min_amount" LIKE 'n%';
+UPDATE "daily_delegation_rollups" SET "min_amount_numeric" = CASE WHEN "min_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("min_amount", 2)
+    WHERE "min_amount" IS NOT NULL;
+UPDATE "daily_delegation_rollups" SET "max_amount" = 'n' || printf('%016d', 9999999999999999 - substr("max_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount", 50, 16))
+    WHERE "max_amount" LIKE 'n%';
+UPDATE "daily_delegation_rollups" SET "max_amount_numeric" = CASE WHEN "max_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("max_amount", 2)
+    WHERE "max_amount" IS NOT NULL;
+UPDATE "daily_delegation_rollups" SET "net_change" = 'n' || printf('%016d', 9999999999999999 - substr("net_change", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change", 50, 16))
+    WHERE "net_change" LIKE 'n%';
+UPDATE "daily_delegation_rollups" SET "net_change_numeric" = CASE WHEN "net_change" LIKE 'n%' THEN '-' ELSE '' END || substr("net_change", 2)
+    WHERE "net_change" IS NOT NULL;
+ALTER TABLE "daily_delegation_rollups" DROP COLUMN "open_amount";
+ALTER TABLE "daily_delegation_rollups" DROP COLUMN "close_amount";
+ALTER TABLE "daily_delegation_rollups" DROP COLUMN "min_amount";
+ALTER TABLE "daily_delegation_rollups" DROP COLUMN "max_amount";
+ALTER TABLE "daily_delegation_rollups" DROP COLUMN "net_change";
+ALTER TABLE "daily_delegation_rollups" RENAME COLUMN "open_amount_numeric" TO "open_amount";
+ALTER TABLE "daily_delegation_rollups" RENAME COLUMN "close_amount_numeric" TO "close_amount";
+ALTER TABLE "daily_delegation_rollups" RENAME COLUMN "min_amount_numeric" TO "min_amount";
+ALTER TABLE "daily_delegation_rollups" RENAME COLUMN "max_amount_numeric" TO "max_amount";
+ALTER TABLE "daily_delegation_rollups" RENAME COLUMN "net_change_numeric" TO "net_change";
+
+ALTER TABLE "weekly_delegation_rollups" ADD COLUMN "open_amount_numeric" numeric;
+ALTER TABLE "weekly_delegation_rollups" ADD COLUMN "close_amount_numeric" numeric;
+ALTER TABLE "weekly_delegation_rollups" ADD COLUMN "min_amount_numeric" numeric;
+ALTER TABLE "weekly_delegation_rollups" ADD COLUMN "max_amount_numeric" numeric;
+ALTER TABLE "weekly_delegation_rollups" ADD COLUMN "net_change_numeric" numeric;
+UPDATE "weekly_delegation_rollups" SET "open_amount" = 'n' || printf('%016d', 9999999999999999 - substr("open_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount", 50, 16))
+    WHERE "open_amount" LIKE 'n%';
+UPDATE "weekly_delegation_rollups" SET "open_amount_numeric" = CASE WHEN "open_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("open_amount", 2)
+    WHERE "open_amount" IS NOT NULL;
+UPDATE "weekly_delegation_rollups" SET "close_amount" = 'n' || printf('%016d', 9999999999999999 - substr("close_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount", 50, 16))
+    WHERE "close_amount" LIKE 'n%';
+UPDATE "weekly_delegation_rollups" SET "close_amount_numeric" = CASE WHEN "close_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("close_amount", 2)
+    WHERE "close_amount" IS NOT NULL;
+UPDATE "weekly_delegation_rollups" SET "min_amount" = 'n' || printf('%016d', 9999999999999999 - substr("min_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount", 50, 16))
+    WHERE "min_amount" LIKE 'n%';
+UPDATE "weekly_delegation_rollups" SET "min_amount_numeric" = CASE WHEN "min_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("min_amount", 2)
+    WHERE "min_amount" IS NOT NULL;
+UPDATE "weekly_delegation_rollups" SET "max_amount" = 'n' || printf('%016d', 9999999999999999 - substr("max_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount", 50, 16))
+    WHERE "max_amount" LIKE 'n%';
+UPDATE "weekly_delegation_rollups" SET "max_amount_numeric" = CASE WHEN "max_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("max_amount", 2)
+    WHERE "max_amount" IS NOT NULL;
+UPDATE "weekly_delegation_rollups" SET "net_change" = 'n' || printf('%016d', 9999999999999999 - substr("net_change", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change", 50, 16))
+    WHERE "net_change" LIKE 'n%';
+UPDATE "weekly_delegation_rollups" SET "net_change_numeric" = CASE WHEN "net_change" LIKE 'n%' THEN '-' ELSE '' END || substr("net_change", 2)
+    WHERE "net_change" IS NOT NULL;
+ALTER TABLE "weekly_delegation_rollups" DROP COLUMN "open_amount";
+ALTER TABLE "weekly_delegation_rollups" DROP COLUMN "close_amount";
+ALTER TABLE "weekly_delegation_rollups" DROP COLUMN "min_amount";
+ALTER TABLE "weekly_delegation_rollups" DROP COLUMN "max_amount";
+ALTER TABLE "weekly_delegation_rollups" DROP COLUMN "net_change";
+ALTER TABLE "weekly_delegation_rollups" RENAME COLUMN "open_amount_numeric" TO "open_amount";
+ALTER TABLE "weekly_delegation_rollups" RENAME COLUMN "close_amount_numeric" TO "close_amount";
+ALTER TABLE "weekly_delegation_rollups" RENAME COLUMN "min_amount_numeric" TO "min_amount";
+ALTER TABLE "weekly_delegation_rollups" RENAME COLUMN "max_amount_numeric" TO "max_amount";
+ALTER TABLE "weekly_delegation_rollups" RENAME COLUMN "net_change_numeric" TO "net_change";
+
+ALTER TABLE "monthly_delegation_rollups" ADD COLUMN "open_amount_numeric" numeric;
+ALTER TABLE "monthly_delegation_rollups" ADD COLUMN "close_amount_numeric" numeric;
+ALTER TABLE "monthly_delegation_rollups" ADD COLUMN "min_amount_numeric" numeric;
+ALTER TABLE "monthly_delegation_rollups" ADD COLUMN "max_amount_numeric" numeric;
+ALTER TABLE "monthly_delegation_rollups" ADD COLUMN "net_change_numeric" numeric;
+UPDATE "monthly_delegation_rollups" SET "open_amount" = 'n' || printf('%016d', 9999999999999999 - substr("open_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount", 50, 16))
+    WHERE "open_amount" LIKE 'n%';
+UPDATE "monthly_delegation_rollups" SET "open_amount_numeric" = CASE WHEN "open_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("open_amount", 2)
+    WHERE "open_amount" IS NOT NULL;
+UPDATE "monthly_delegation_rollups" SET "close_amount" = 'n' || printf('%016d', 9999999999999999 - substr("close_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount", 50, 16))
+    WHERE "close_amount" LIKE 'n%';
+UPDATE "monthly_delegation_rollups" SET "close_amount_numeric" = CASE WHEN "close_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("close_amount", 2)
+    WHERE "close_amount" IS NOT NULL;
+UPDATE "monthly_delegation_rollups" SET "min_amount" = 'n' || printf('%016d', 9999999999999999 - substr("min_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount", 50, 16))
+    WHERE "min_amount" LIKE 'n%';
+UPDATE "monthly_delegation_rollups" SET "min_amount_numeric" = CASE WHEN "min_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("min_amount", 2)
+    WHERE "min_amount" IS NOT NULL;
+UPDATE "monthly_delegation_rollups" SET "max_amount" = 'n' || printf('%016d', 9999999999999999 - substr("max_amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount", 50, 16))
+    WHERE "max_amount" LIKE 'n%';
+UPDATE "monthly_delegation_rollups" SET "max_amount_numeric" = CASE WHEN "max_amount" LIKE 'n%' THEN '-' ELSE '' END || substr("max_amount", 2)
+    WHERE "max_amount" IS NOT NULL;
+UPDATE "monthly_delegation_rollups" SET "net_change" = 'n' || printf('%016d', 9999999999999999 - substr("net_change", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change", 50, 16))
+    WHERE "net_change" LIKE 'n%';
+UPDATE "monthly_delegation_rollups" SET "net_change_numeric" = CASE WHEN "net_change" LIKE 'n%' THEN '-' ELSE '' END || substr("net_change", 2)
+    WHERE "net_change" IS NOT NULL;
+ALTER TABLE "monthly_delegation_rollups" DROP COLUMN "open_amount";
+ALTER TABLE "monthly_delegation_rollups" DROP COLUMN "close_amount";
+ALTER TABLE "monthly_delegation_rollups" DROP COLUMN "min_amount";
+ALTER TABLE "monthly_delegation_rollups" DROP COLUMN "max_amount";
+ALTER TABLE "monthly_delegation_rollups" DROP COLUMN "net_change";
+ALTER TABLE "monthly_delegation_rollups" RENAME COLUMN "open_amount_numeric" TO "open_amount";
+ALTER TABLE "monthly_delegation_rollups" RENAME COLUMN "close_amount_numeric" TO "close_amount";
+ALTER TABLE "monthly_delegation_rollups" RENAME COLUMN "min_amount_numeric" TO "min_amount";
+ALTER TABLE "monthly_delegation_rollups" RENAME COLUMN "max_amount_numeric" TO "max_amount";
+ALTER TABLE "monthly_delegation_rollups" RENAME COLUMN "net_change_numeric" TO "net_change";
+
+ALTER TABLE "hourly_validator_stats" ADD COLUMN "total_delegated_numeric" numeric;
+ALTER TABLE "hourly_validator_stats" ADD COLUMN "inflow_numeric" numeric;
+ALTER TABLE "hourly_validator_stats" ADD COLUMN "outflow_numeric" numeric;
+ALTER TABLE "hourly_validator_stats" ADD COLUMN "net_flow_numeric" numeric;
+UPDATE "hourly_validator_stats" SET "total_delegated" = 'n' || printf('%016d', 9999999999999999 - substr("total_delegated", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegated", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegated", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegated", 50, 16))
+    WHERE "total_delegated" LIKE 'n%';
+UPDATE "hourly_validator_stats" SET "total_delegated_numeric" = CASE WHEN "total_delegated" LIKE 'n%' THEN '-' ELSE '' END || substr("total_delegated", 2)
+    WHERE "total_delegated" IS NOT NULL;
+UPDATE "hourly_validator_stats" SET "inflow" = 'n' || printf('%016d', 9999999999999999 - substr("inflow", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("inflow", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("inflow", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("inflow", 50, 16))
+    WHERE "inflow" LIKE 'n%';
+UPDATE "hourly_validator_stats" SET "inflow_numeric" = CASE WHEN "inflow" LIKE 'n%' THEN '-' ELSE '' END || substr("inflow", 2)
+    WHERE "inflow" IS NOT NULL;
+UPDATE "hourly_validator_stats" SET "outflow" = 'n' || printf('%016d', 9999999999999999 - substr("outflow", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("outflow", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("outflow", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("outflow", 50, 16))
+    WHERE "outflow" LIKE 'n%';
+UPDATE "hourly_validator_stats" SET "outflow_numeric" = CASE WHEN "outflow" LIKE 'n%' THEN '-' ELSE '' END || substr("outflow", 2)
+    WHERE "outflow" IS NOT NULL;
+UPDATE "hourly_validator_stats" SET "net_flow" = 'n' || printf('%016d', 9999999999999999 - substr("net_flow", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("net_flow", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("net_flow", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("net_flow", 50, 16))
+    WHERE "net_flow" LIKE 'n%';
+UPDATE "hourly_validator_stats" SET "net_flow_numeric" = CASE WHEN "net_flow" LIKE 'n%' THEN '-' ELSE '' END || substr("net_flow", 2)
+    WHERE "net_flow" IS NOT NULL;
+ALTER TABLE "hourly_validator_stats" DROP COLUMN "total_delegated";
+ALTER TABLE "hourly_validator_stats" DROP COLUMN "inflow";
+ALTER TABLE "hourly_validator_stats" DROP COLUMN "outflow";
+ALTER TABLE "hourly_validator_stats" DROP COLUMN "net_flow";
+ALTER TABLE "hourly_validator_stats" RENAME COLUMN "total_delegated_numeric" TO "total_delegated";
+ALTER TABLE "hourly_validator_stats" RENAME COLUMN "inflow_numeric" TO "inflow";
+ALTER TABLE "hourly_validator_stats" RENAME COLUMN "outflow_numeric" TO "outflow";
+ALTER TABLE "hourly_validator_stats" RENAME COLUMN "net_flow_numeric" TO "net_flow";
+
+ALTER TABLE "daily_validator_stats" ADD COLUMN "total_delegated_numeric" numeric;
+ALTER TABLE "daily_validator_stats" ADD COLUMN "inflow_numeric" numeric;
+ALTER TABLE "daily_validator_stats" ADD COLUMN "outflow_numeric" numeric;
+ALTER TABLE "daily_validator_stats" ADD COLUMN "net_flow_numeric" numeric;
+UPDATE "daily_validator_stats" SET "total_delegated" = 'n' || printf('%016d', 9999999999999999 - substr("total_delegated", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegated", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegated", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegated", 50, 16))
+    WHERE "total_delegated" LIKE 'n%';
+UPDATE "daily_validator_stats" SET "total_delegated_numeric" = CASE WHEN "total_delegated" LIKE 'n%' THEN '-' ELSE '' END || substr("total_delegated", 2)
+    WHERE "total_delegated" IS NOT NULL;
+UPDATE "daily_validator_stats" SET "inflow" = 'n' || printf('%016d', 9999999999999999 - substr("inflow", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("inflow", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("inflow", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("inflow", 50, 16))
+    WHERE "inflow" LIKE 'n%';
+UPDATE "daily_validator_stats" SET "inflow_numeric" = CASE WHEN "inflow" LIKE 'n%' THEN '-' ELSE '' END || substr("inflow", 2)
+    WHERE "inflow" IS NOT NULL;
+UPDATE "daily_validator_stats" SET "outflow" = 'n' || printf('%016d', 9999999999999999 - substr("outflow", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("outflow", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("outflow", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("outflow", 50, 16))
+    WHERE "outflow" LIKE 'n%';
+UPDATE "daily_validator_stats" SET "outflow_numeric" = CASE WHEN "outflow" LIKE 'n%' THEN '-' ELSE '' END || substr("outflow", 2)
+    WHERE "outflow" IS NOT NULL;
+UPDATE "daily_validator_stats" SET "net_flow" = 'n' || printf('%016d', 9999999999999999 - substr("net_flow", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("net_flow", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("net_flow", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("net_flow", 50, 16))
+    WHERE "net_flow" LIKE 'n%';
+UPDATE "daily_validator_stats" SET "net_flow_numeric" = CASE WHEN "net_flow" LIKE 'n%' THEN '-' ELSE '' END || substr("net_flow", 2)
+    WHERE "net_flow" IS NOT NULL;
+ALTER TABLE "daily_validator_stats" DROP COLUMN "total_delegated";
+ALTER TABLE "daily_validator_stats" DROP COLUMN "inflow";
+ALTER TABLE "daily_validator_stats" DROP COLUMN "outflow";
+ALTER TABLE "daily_validator_stats" DROP COLUMN "net_flow";
+ALTER TABLE "daily_validator_stats" RENAME COLUMN "total_delegated_numeric" TO "total_delegated";
+ALTER TABLE "daily_validator_stats" RENAME COLUMN "inflow_numeric" TO "inflow";
+ALTER TABLE "daily_validator_stats" RENAME COLUMN "outflow_numeric" TO "outflow";
+ALTER TABLE "daily_validator_stats" RENAME COLUMN "net_flow_numeric" TO "net_flow";
+
+ALTER TABLE "unbonding_delegations" ADD COLUMN "initial_balance_numeric" numeric;
+ALTER TABLE "unbonding_delegations" ADD COLUMN "balance_numeric" numeric;
+UPDATE "unbonding_delegations" SET "initial_balance" = 'n' || printf('%016d', 9999999999999999 - substr("initial_balance", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("initial_balance", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("initial_balance", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("initial_balance", 50, 16))
+    WHERE "initial_balance" LIKE 'n%';
+UPDATE "unbonding_delegations" SET "initial_balance_numeric" = CASE WHEN "initial_balance" LIKE 'n%' THEN '-' ELSE '' END || substr("initial_balance", 2)
+    WHERE "initial_balance" IS NOT NULL;
+UPDATE "unbonding_delegations" SET "balance" = 'n' || printf('%016d', 9999999999999999 - substr("balance", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("balance", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("balance", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("balance", 50, 16))
+    WHERE "balance" LIKE 'n%';
+UPDATE "unbonding_delegations" SET "balance_numeric" = CASE WHEN "balance" LIKE 'n%' THEN '-' ELSE '' END || substr("balance", 2)
+    WHERE "balance" IS NOT NULL;
+ALTER TABLE "unbonding_delegations" DROP COLUMN "initial_balance";
+ALTER TABLE "unbonding_delegations" DROP COLUMN "balance";
+ALTER TABLE "unbonding_delegations" RENAME COLUMN "initial_balance_numeric" TO "initial_balance";
+ALTER TABLE "unbonding_delegations" RENAME COLUMN "balance_numeric" TO "balance";
+
+ALTER TABLE "redelegations" ADD COLUMN "amount_numeric" numeric;
+UPDATE "redelegations" SET "amount" = 'n' || printf('%016d', 9999999999999999 - substr("amount", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("amount", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("amount", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("amount", 50, 16))
+    WHERE "amount" LIKE 'n%';
+UPDATE "redelegations" SET "amount_numeric" = CASE WHEN "amount" LIKE 'n%' THEN '-' ELSE '' END || substr("amount", 2)
+    WHERE "amount" IS NOT NULL;
+ALTER TABLE "redelegations" DROP COLUMN "amount";
+ALTER TABLE "redelegations" RENAME COLUMN "amount_numeric" TO "amount";
+
+ALTER TABLE "validator_snapshots" ADD COLUMN "tokens_numeric" numeric;
+ALTER TABLE "validator_snapshots" ADD COLUMN "delegator_shares_numeric" numeric;
+ALTER TABLE "validator_snapshots" ADD COLUMN "commission_rate_numeric" numeric;
+ALTER TABLE "validator_snapshots" ADD COLUMN "commission_max_rate_numeric" numeric;
+ALTER TABLE "validator_snapshots" ADD COLUMN "commission_max_change_rate_numeric" numeric;
+UPDATE "validator_snapshots" SET "tokens" = 'n' || printf('%016d', 9999999999999999 - substr("tokens", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("tokens", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("tokens", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("tokens", 50, 16))
+    WHERE "tokens" LIKE 'n%';
+UPDATE "validator_snapshots" SET "tokens_numeric" = CASE WHEN "tokens" LIKE 'n%' THEN '-' ELSE '' END || substr("tokens", 2)
+    WHERE "tokens" IS NOT NULL;
+UPDATE "validator_snapshots" SET "delegator_shares" = 'n' || printf('%016d', 9999999999999999 - substr("delegator_shares", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("delegator_shares", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("delegator_shares", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("delegator_shares", 50, 16))
+    WHERE "delegator_shares" LIKE 'n%';
+UPDATE "validator_snapshots" SET "delegator_shares_numeric" = CASE WHEN "delegator_shares" LIKE 'n%' THEN '-' ELSE '' END || substr("delegator_shares", 2, 46) || '.' || substr("delegator_shares", 48)
+    WHERE "delegator_shares" IS NOT NULL;
+UPDATE "validator_snapshots" SET "commission_rate" = 'n' || printf('%016d', 9999999999999999 - substr("commission_rate", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_rate", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_rate", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_rate", 50, 16))
+    WHERE "commission_rate" LIKE 'n%';
+UPDATE "validator_snapshots" SET "commission_rate_numeric" = CASE WHEN "commission_rate" LIKE 'n%' THEN '-' ELSE '' END || substr("commission_rate", 2, 46) || '.' || substr("commission_rate", 48)
+    WHERE "commission_rate" IS NOT NULL;
+UPDATE "validator_snapshots" SET "commission_max_rate" = 'n' || printf('%016d', 9999999999999999 - substr("commission_max_rate", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_max_rate", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_max_rate", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_max_rate", 50, 16))
+    WHERE "commission_max_rate" LIKE 'n%';
+UPDATE "validator_snapshots" SET "commission_max_rate_numeric" = CASE WHEN "commission_max_rate" LIKE 'n%' THEN '-' ELSE '' END || substr("commission_max_rate", 2, 46) || '.' || substr("commission_max_rate", 48)
+    WHERE "commission_max_rate" IS NOT NULL;
+UPDATE "validator_snapshots" SET "commission_max_change_rate" = 'n' || printf('%016d', 9999999999999999 - substr("commission_max_change_rate", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_max_change_rate", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_max_change_rate", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_max_change_rate", 50, 16))
+    WHERE "commission_max_change_rate" LIKE 'n%';
+UPDATE "validator_snapshots" SET "commission_max_change_rate_numeric" = CASE WHEN "commission_max_change_rate" LIKE 'n%' THEN '-' ELSE '' END || substr("commission_max_change_rate", 2, 46) || '.' || substr("commission_max_change_rate", 48)
+    WHERE "commission_max_change_rate" IS NOT NULL;
+ALTER TABLE "validator_snapshots" DROP COLUMN "tokens";
+ALTER TABLE "validator_snapshots" DROP COLUMN "delegator_shares";
+ALTER TABLE "validator_snapshots" DROP COLUMN "commission_rate";
+ALTER TABLE "validator_snapshots" DROP COLUMN "commission_max_rate";
+ALTER TABLE "validator_snapshots" DROP COLUMN "commission_max_change_rate";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "tokens_numeric" TO "tokens";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "delegator_shares_numeric" TO "delegator_shares";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "commission_rate_numeric" TO "commission_rate";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "commission_max_rate_numeric" TO "commission_max_rate";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "commission_max_change_rate_numeric" TO "commission_max_change_rate";
@@ -0,0 +1,438 @@
+-- Stores amounts, shares and commission rates on SQLite as text that sorts in numeric order. SQLite
+-- keeps numbers beyond 64-bit integers only as floating point, so large amounts lost precision. Each
+-- value becomes "p" and its 64 zero-padded digits, or for negative values "n" and the nines'
+-- complement of those digits; decimals are scaled by 10^18 first. The application writes and reads
+-- the same encoding. Values stored as floating point before this version were already rounded and
+-- keep about 16 significant digits.
+--
+-- SQLite can't change a column's type in place, so each value is copied into a text column that then
+-- takes the old column's name. Negative values are written as their digits first and complemented in
+-- 16-digit chunks afterwards.
+
+ALTER TABLE "hourly_delegations" ADD COLUMN "delegation_amount_text" text;
+ALTER TABLE "hourly_delegations" ADD COLUMN "change_amount_text" text;
+ALTER TABLE "hourly_delegations" ADD COLUMN "shares_text" text;
+UPDATE "hourly_delegations" SET "delegation_amount_text" = CASE WHEN "delegation_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("delegation_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("delegation_amount"))
+    WHERE "delegation_amount" IS NOT NULL;
+UPDATE "hourly_delegations" SET "delegation_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("delegation_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("delegation_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("delegation_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("delegation_amount_text", 50, 16))
+    WHERE "delegation_amount_text" LIKE 'n%';
+UPDATE "hourly_delegations" SET "change_amount_text" = CASE WHEN "change_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("change_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("change_amount"))
+    WHERE "change_amount" IS NOT NULL;
+UPDATE "hourly_delegations" SET "change_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("change_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("change_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("change_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("change_amount_text", 50, 16))
+    WHERE "change_amount_text" LIKE 'n%';
+UPDATE "hourly_delegations" SET "shares_text" = CASE WHEN "shares" < 0 THEN 'n' ELSE 'p' END || CASE
+        WHEN typeof("shares") = 'integer' THEN printf('%046d', abs("shares")) || '000000000000000000'
+        WHEN instr(CAST(abs("shares") AS TEXT), 'e') = 0 THEN printf('%046d', substr(CAST(abs("shares") AS TEXT), 1, instr(CAST(abs("shares") AS TEXT), '.') - 1))
+            || substr(substr(CAST(abs("shares") AS TEXT), instr(CAST(abs("shares") AS TEXT), '.') + 1) || '000000000000000000', 1, 18)
+        ELSE replace(printf('%065.18f', abs("shares")), '.', '')
+    END
+    WHERE "shares" IS NOT NULL;
+UPDATE "hourly_delegations" SET "shares_text" = 'n' || printf('%016d', 9999999999999999 - substr("shares_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("shares_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("shares_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("shares_text", 50, 16))
+    WHERE "shares_text" LIKE 'n%';
+ALTER TABLE "hourly_delegations" DROP COLUMN "delegation_amount";
+ALTER TABLE "hourly_delegations" DROP COLUMN "change_amount";
+ALTER TABLE "hourly_delegations" DROP COLUMN "shares";
+ALTER TABLE "hourly_delegations" RENAME COLUMN "delegation_amount_text" TO "delegation_amount";
+ALTER TABLE "hourly_delegations" RENAME COLUMN "change_amount_text" TO "change_amount";
+ALTER TABLE "hourly_delegations" RENAME COLUMN "shares_text" TO "shares";
+
+ALTER TABLE "daily_delegations" ADD COLUMN "total_delegation_text" text;
+ALTER TABLE "daily_delegations" ADD COLUMN "total_shares_text" text;
+UPDATE "daily_delegations" SET "total_delegation_text" = CASE WHEN "total_delegation" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("total_delegation") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("total_delegation"))
+    WHERE "total_delegation" IS NOT NULL;
+UPDATE "daily_delegations" SET "total_delegation_text" = 'n' || printf('%016d', 9999999999999999 - substr("total_delegation_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegation_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegation_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegation_text", 50, 16))
+    WHERE "total_delegation_text" LIKE 'n%';
+UPDATE "daily_delegations" SET "total_shares_text" = CASE WHEN "total_shares" < 0 THEN 'n' ELSE 'p' END || CASE
+        WHEN typeof("total_shares") = 'integer' THEN printf('%046d', abs("total_shares")) || '000000000000000000'
+        WHEN instr(CAST(abs("total_shares") AS TEXT), 'e') = 0 THEN printf('%046d', substr(CAST(abs("total_shares") AS TEXT), 1, instr(CAST(abs("total_shares") AS TEXT), '.') - 1))
+            || substr(substr(CAST(abs("total_shares") AS TEXT), instr(CAST(abs("total_shares") AS TEXT), '.') + 1) || '000000000000000000', 1, 18)
+        ELSE replace(printf('%065.18f', abs("total_shares")), '.', '')
+    END
+    WHERE "total_shares" IS NOT NULL;
+UPDATE "daily_delegations" SET "total_shares_text" = 'n' || printf('%016d', 9999999999999999 - substr("total_shares_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("total_shares_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("total_shares_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("total_shares_text", 50, 16))
+    WHERE "total_shares_text" LIKE 'n%';
+ALTER TABLE "daily_delegations" DROP COLUMN "total_delegation";
+ALTER TABLE "daily_delegations" DROP COLUMN "total_shares";
+ALTER TABLE "daily_delegations" RENAME COLUMN "total_delegation_text" TO "total_delegation";
+ALTER TABLE "daily_delegations" RENAME COLUMN "total_shares_text" TO "total_shares";
+
+DROP INDEX "idx_current_delegation_amount";
+ALTER TABLE "current_delegations" ADD COLUMN "delegation_amount_text" text;
+ALTER TABLE "current_delegations" ADD COLUMN "shares_text" text;
+UPDATE "current_delegations" SET "delegation_amount_text" = CASE WHEN "delegation_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("delegation_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("delegation_amount"))
+    WHERE "delegation_amount" IS NOT NULL;
+UPDATE "current_delegations" SET "delegation_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("delegation_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("delegation_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("delegation_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("delegation_amount_text", 50, 16))
+    WHERE "delegation_amount_text" LIKE 'n%';
+UPDATE "current_delegations" SET "shares_text" = CASE WHEN "shares" < 0 THEN 'n' ELSE 'p' END || CASE
+        WHEN typeof("shares") = 'integer' THEN printf('%046d', abs("shares")) || '000000000000000000'
+        WHEN instr(CAST(abs("shares") AS TEXT), 'e') = 0 THEN printf('%046d', substr(CAST(abs("shares") AS TEXT), 1, instr(CAST(abs("shares") AS TEXT), '.') - 1))
+            || substr(substr(CAST(abs("shares") AS TEXT), instr(CAST(abs("shares") AS TEXT), '.') + 1) || '000000000000000000', 1, 18)
+        ELSE replace(printf('%065.18f', abs("shares")), '.', '')
+    END
+    WHERE "shares" IS NOT NULL;
+UPDATE "current_delegations" SET "shares_text" = 'n' || printf('%016d', 9999999999999999 - substr("shares_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("shares_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("shares_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("shares_text", 50, 16))
+    WHERE "shares_text" LIKE 'n%';
+ALTER TABLE "current_delegations" DROP COLUMN "delegation_amount";
+ALTER TABLE "current_delegations" DROP COLUMN "shares";
+ALTER TABLE "current_delegations" RENAME COLUMN "delegation_amount_text" TO "delegation_amount";
+ALTER TABLE "current_delegations" RENAME COLUMN "shares_text" TO "shares";
+CREATE INDEX "idx_current_delegation_amount" ON "current_delegations" ("chain_id","validator_address","delegation_amount");
+
+ALTER TABLE "daily_delegation_rollups" ADD COLUMN "open_amount_text" text;
+ALTER TABLE "daily_delegation_rollups" ADD COLUMN "close_amount_text" text;
+ALTER TABLE "daily_delegation_rollups" ADD COLUMN "min_amount_text" text;
+ALTER TABLE "daily_delegation_rollups" ADD COLUMN "max_amount_text" text;
+ALTER TABLE "daily_delegation_rollups" ADD COLUMN "net_change_text" text;
+UPDATE "daily_delegation_rollups" SET "open_amount_text" = CASE WHEN "open_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("open_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("open_amount"))
+    WHERE "open_amount" IS NOT NULL;
+UPDATE "daily_delegation_rollups" SET "open_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("open_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount_text", 50, 16))
+    WHERE "open_amount_text" LIKE 'n%';
+UPDATE "daily_delegation_rollups" SET "close_amount_text" = CASE WHEN "close_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("close_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("close_amount"))
+    WHERE "close_amount" IS NOT NULL;
+UPDATE "daily_delegation_rollups" SET "close_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("close_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount_text", 50, 16))
+    WHERE "close_amount_text" LIKE 'n%';
+UPDATE "daily_delegation_rollups" SET "min_amount_text" = CASE WHEN "min_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("min_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("min_amount"))
+    WHERE "min_amount" IS NOT NULL;
+UPDATE "daily_delegation_rollups" SET "min_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("min_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount_text", 50, 16))
+    WHERE "min_amount_text" LIKE 'n%';
+UPDATE "daily_delegation_rollups" SET "max_amount_text" = CASE WHEN "max_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("max_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("max_amount"))
+    WHERE "max_amount" IS NOT NULL;
+UPDATE "daily_delegation_rollups" SET "max_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("max_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount_text", 50, 16))
+    WHERE "max_amount_text" LIKE 'n%';
+UPDATE "daily_delegation_rollups" SET "net_change_text" = CASE WHEN "net_change" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("net_change") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("net_change"))
+    WHERE "net_change" IS NOT NULL;
+UPDATE "daily_delegation_rollups" SET "net_change_text" = 'n' || printf('%016d', 9999999999999999 - substr("net_change_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change_text", 50, 16))
+    WHERE "net_change_text" LIKE 'n%';
+ALTER TABLE "daily_delegation_rollups" DROP COLUMN "open_amount";
+ALTER TABLE "daily_delegation_rollups" DROP COLUMN "close_amount";
+ALTER TABLE "daily_delegation_rollups" DROP COLUMN "min_amount";
+ALTER TABLE "daily_delegation_rollups" DROP COLUMN "max_amount";
+ALTER TABLE "daily_delegation_rollups" DROP COLUMN "net_change";
+ALTER TABLE "daily_delegation_rollups" RENAME COLUMN "open_amount_text" TO "open_amount";
+ALTER TABLE "daily_delegation_rollups" RENAME COLUMN "close_amount_text" TO "close_amount";
+ALTER TABLE "daily_delegation_rollups" RENAME COLUMN "min_amount_text" TO "min_amount";
+ALTER TABLE "daily_delegation_rollups" RENAME COLUMN "max_amount_text" TO "max_amount";
+ALTER TABLE "daily_delegation_rollups" RENAME COLUMN "net_change_text" TO "net_change";
+
+ALTER TABLE "weekly_delegation_rollups" ADD COLUMN "open_amount_text" text;
+ALTER TABLE "weekly_delegation_rollups" ADD COLUMN "close_amount_text" text;
+ALTER TABLE "weekly_delegation_rollups" ADD COLUMN "min_amount_text" text;
+ALTER TABLE "weekly_delegation_rollups" ADD COLUMN "max_amount_text" text;
+ALTER TABLE "weekly_delegation_rollups" ADD COLUMN "net_change_text" text;
+UPDATE "weekly_delegation_rollups" SET "open_amount_text" = CASE WHEN "open_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("open_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("open_amount"))
+    WHERE "open_amount" IS NOT NULL;
+UPDATE "weekly_delegation_rollups" SET "open_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("open_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount_text", 50, 16))
+    WHERE "open_amount_text" LIKE 'n%';
+UPDATE "weekly_delegation_rollups" SET "close_amount_text" = CASE WHEN "close_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("close_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("close_amount"))
+    WHERE "close_amount" IS NOT NULL;
+UPDATE "weekly_delegation_rollups" SET "close_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("close_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount_text", 50, 16))
+    WHERE "close_amount_text" LIKE 'n%';
+UPDATE "weekly_delegation_rollups" SET "min_amount_text" = CASE WHEN "min_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("min_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("min_amount"))
+    WHERE "min_amount" IS NOT NULL;
+UPDATE "weekly_delegation_rollups" SET "min_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("min_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount_text", 50, 16))
+    WHERE "min_amount_text" LIKE 'n%';
+UPDATE "weekly_delegation_rollups" SET "max_amount_text" = CASE WHEN "max_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("max_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("max_amount"))
+    WHERE "max_amount" IS NOT NULL;
+UPDATE "weekly_delegation_rollups" SET "max_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("max_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount_text", 50, 16))
+    WHERE "max_amount_text" LIKE 'n%';
+UPDATE "weekly_delegation_rollups" SET "net_change_text" = CASE WHEN "net_change" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("net_change") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("net_change"))
+    WHERE "net_change" IS NOT NULL;
+UPDATE "weekly_delegation_rollups" SET "net_change_text" = 'n' || printf('%016d', 9999999999999999 - substr("net_change_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change_text", 50, 16))
+    WHERE "net_change_text" LIKE 'n%';
+ALTER TABLE "weekly_delegation_rollups" DROP COLUMN "open_amount";
+ALTER TABLE "weekly_delegation_rollups" DROP COLUMN "close_amount";
+ALTER TABLE "weekly_delegation_rollups" DROP COLUMN "min_amount";
+ALTER TABLE "weekly_delegation_rollups" DROP COLUMN "max_amount";
+ALTER TABLE "weekly_delegation_rollups" DROP COLUMN "net_change";
+ALTER TABLE "weekly_delegation_rollups" RENAME COLUMN "open_amount_text" TO "open_amount";
+ALTER TABLE "weekly_delegation_rollups" RENAME COLUMN "close_amount_text" TO "close_amount";
+ALTER TABLE "weekly_delegation_rollups" RENAME COLUMN "min_amount_text" TO "min_amount";
+ALTER TABLE "weekly_delegation_rollups" RENAME COLUMN "max_amount_text" TO "max_amount";
+ALTER TABLE "weekly_delegation_rollups" RENAME COLUMN "net_change_text" TO "net_change";
+
+ALTER TABLE "monthly_delegation_rollups" ADD COLUMN "open_amount_text" text;
+ALTER TABLE "monthly_delegation_rollups" ADD COLUMN "close_amount_text" text;
+ALTER TABLE "monthly_delegation_rollups" ADD COLUMN "min_amount_text" text;
+ALTER TABLE "monthly_delegation_rollups" ADD COLUMN "max_amount_text" text;
+ALTER TABLE "monthly_delegation_rollups" ADD COLUMN "net_change_text" text;
+UPDATE "monthly_delegation_rollups" SET "open_amount_text" = CASE WHEN "open_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("open_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("open_amount"))
+    WHERE "open_amount" IS NOT NULL;
+UPDATE "monthly_delegation_rollups" SET "open_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("open_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("open_amount_text", 50, 16))
+    WHERE "open_amount_text" LIKE 'n%';
+UPDATE "monthly_delegation_rollups" SET "close_amount_text" = CASE WHEN "close_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("close_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("close_amount"))
+    WHERE "close_amount" IS NOT NULL;
+UPDATE "monthly_delegation_rollups" SET "close_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("close_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("close_amount_text", 50, 16))
+    WHERE "close_amount_text" LIKE 'n%';
+UPDATE "monthly_delegation_rollups" SET "min_amount_text" = CASE WHEN "min_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("min_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("min_amount"))
+    WHERE "min_amount" IS NOT NULL;
+UPDATE "monthly_delegation_rollups" SET "min_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("min_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("min_amount_text", 50, 16))
+    WHERE "min_amount_text" LIKE 'n%';
+UPDATE "monthly_delegation_rollups" SET "max_amount_text" = CASE WHEN "max_amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("max_amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("max_amount"))
+    WHERE "max_amount" IS NOT NULL;
+UPDATE "monthly_delegation_rollups" SET "max_amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("max_amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("max_amount_text", 50, 16))
+    WHERE "max_amount_text" LIKE 'n%';
+UPDATE "monthly_delegation_rollups" SET "net_change_text" = CASE WHEN "net_change" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("net_change") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("net_change"))
+    WHERE "net_change" IS NOT NULL;
+UPDATE "monthly_delegation_rollups" SET "net_change_text" = 'n' || printf('%016d', 9999999999999999 - substr("net_change_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("net_change_text", 50, 16))
+    WHERE "net_change_text" LIKE 'n%';
+ALTER TABLE "monthly_delegation_rollups" DROP COLUMN "open_amount";
+ALTER TABLE "monthly_delegation_rollups" DROP COLUMN "close_amount";
+ALTER TABLE "monthly_delegation_rollups" DROP COLUMN "min_amount";
+ALTER TABLE "monthly_delegation_rollups" DROP COLUMN "max_amount";
+ALTER TABLE "monthly_delegation_rollups" DROP COLUMN "net_change";
+ALTER TABLE "monthly_delegation_rollups" RENAME COLUMN "open_amount_text" TO "open_amount";
+ALTER TABLE "monthly_delegation_rollups" RENAME COLUMN "close_amount_text" TO "close_amount";
+ALTER TABLE "monthly_delegation_rollups" RENAME COLUMN "min_amount_text" TO "min_amount";
+ALTER TABLE "monthly_delegation_rollups" RENAME COLUMN "max_amount_text" TO "max_amount";
+ALTER TABLE "monthly_delegation_rollups" RENAME COLUMN "net_change_text" TO "net_change";
+
+ALTER TABLE "hourly_validator_stats" ADD COLUMN "total_delegated_text" text;
+ALTER TABLE "hourly_validator_stats" ADD COLUMN "inflow_text" text;
+ALTER TABLE "hourly_validator_stats" ADD COLUMN "outflow_text" text;
+ALTER TABLE "hourly_validator_stats" ADD COLUMN "net_flow_text" text;
+UPDATE "hourly_validator_stats" SET "total_delegated_text" = CASE WHEN "total_delegated" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("total_delegated") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("total_delegated"))
+    WHERE "total_delegated" IS NOT NULL;
+UPDATE "hourly_validator_stats" SET "total_delegated_text" = 'n' || printf('%016d', 9999999999999999 - substr("total_delegated_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegated_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegated_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegated_text", 50, 16))
+    WHERE "total_delegated_text" LIKE 'n%';
+UPDATE "hourly_validator_stats" SET "inflow_text" = CASE WHEN "inflow" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("inflow") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("inflow"))
+    WHERE "inflow" IS NOT NULL;
+UPDATE "hourly_validator_stats" SET "inflow_text" = 'n' || printf('%016d', 9999999999999999 - substr("inflow_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("inflow_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("inflow_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("inflow_text", 50, 16))
+    WHERE "inflow_text" LIKE 'n%';
+UPDATE "hourly_validator_stats" SET "outflow_text" = CASE WHEN "outflow" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("outflow") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("outflow"))
+    WHERE "outflow" IS NOT NULL;
+UPDATE "hourly_validator_stats" SET "outflow_text" = 'n' || printf('%016d', 9999999999999999 - substr("outflow_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("outflow_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("outflow_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("outflow_text", 50, 16))
+    WHERE "outflow_text" LIKE 'n%';
+UPDATE "hourly_validator_stats" SET "net_flow_text" = CASE WHEN "net_flow" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("net_flow") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("net_flow"))
+    WHERE "net_flow" IS NOT NULL;
+UPDATE "hourly_validator_stats" SET "net_flow_text" = 'n' || printf('%016d', 9999999999999999 - substr("net_flow_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("net_flow_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("net_flow_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("net_flow_text", 50, 16))
+    WHERE "net_flow_text" LIKE 'n%';
+ALTER TABLE "hourly_validator_stats" DROP COLUMN "total_delegated";
+ALTER TABLE "hourly_validator_stats" DROP COLUMN "inflow";
+ALTER TABLE "hourly_validator_stats" DROP COLUMN "outflow";
+ALTER TABLE "hourly_validator_stats" DROP COLUMN "net_flow";
+ALTER TABLE "hourly_validator_stats" RENAME COLUMN "total_delegated_text" TO "total_delegated";
+ALTER TABLE "hourly_validator_stats" RENAME COLUMN "inflow_text" TO "inflow";
+ALTER TABLE "hourly_validator_stats" RENAME COLUMN "outflow_text" TO "outflow";
+ALTER TABLE "hourly_validator_stats" RENAME COLUMN "net_flow_text" TO "net_flow";
+
+ALTER TABLE "daily_validator_stats" ADD COLUMN "total_delegated_text" text;
+ALTER TABLE "daily_validator_stats" ADD COLUMN "inflow_text" text;
+ALTER TABLE "daily_validator_stats" ADD COLUMN "outflow_text" text;
+ALTER TABLE "daily_validator_stats" ADD COLUMN "net_flow_text" text;
+UPDATE "daily_validator_stats" SET "total_delegated_text" = CASE WHEN "total_delegated" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("total_delegated") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("total_delegated"))
+    WHERE "total_delegated" IS NOT NULL;
+UPDATE "daily_validator_stats" SET "total_delegated_text" = 'n' || printf('%016d', 9999999999999999 - substr("total_delegated_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegated_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegated_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("total_delegated_text", 50, 16))
+    WHERE "total_delegated_text" LIKE 'n%';
+UPDATE "daily_validator_stats" SET "inflow_text" = CASE WHEN "inflow" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("inflow") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("inflow"))
+    WHERE "inflow" IS NOT NULL;
+UPDATE "daily_validator_stats" SET "inflow_text" = 'n' || printf('%016d', 9999999999999999 - substr("inflow_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("inflow_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("inflow_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("inflow_text", 50, 16))
+    WHERE "inflow_text" LIKE 'n%';
+UPDATE "daily_validator_stats" SET "outflow_text" = CASE WHEN "outflow" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("outflow") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("outflow"))
+    WHERE "outflow" IS NOT NULL;
+UPDATE "daily_validator_stats" SET "outflow_text" = 'n' || printf('%016d', 9999999999999999 - substr("outflow_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("outflow_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("outflow_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("outflow_text", 50, 16))
+    WHERE "outflow_text" LIKE 'n%';
+UPDATE "daily_validator_stats" SET "net_flow_text" = CASE WHEN "net_flow" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("net_flow") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("net_flow"))
+    WHERE "net_flow" IS NOT NULL;
+UPDATE "daily_validator_stats" SET "net_flow_text" = 'n' || printf('%016d', 9999999999999999 - substr("net_flow_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("net_flow_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("net_flow_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("net_flow_text", 50, 16))
+    WHERE "net_flow_text" LIKE 'n%';
+ALTER TABLE "daily_validator_stats" DROP COLUMN "total_delegated";
+ALTER TABLE "daily_validator_stats" DROP COLUMN "inflow";
+ALTER TABLE "daily_validator_stats" DROP COLUMN "outflow";
+ALTER TABLE "daily_validator_stats" DROP COLUMN "net_flow";
+ALTER TABLE "daily_validator_stats" RENAME COLUMN "total_delegated_text" TO "total_delegated";
+ALTER TABLE "daily_validator_stats" RENAME COLUMN "inflow_text" TO "inflow";
+ALTER TABLE "daily_validator_stats" RENAME COLUMN "outflow_text" TO "outflow";
+ALTER TABLE "daily_validator_stats" RENAME COLUMN "net_flow_text" TO "net_flow";
+
+ALTER TABLE "unbonding_delegations" ADD COLUMN "initial_balance_text" text;
+ALTER TABLE "unbonding_delegations" ADD COLUMN "balance_text" text;
+UPDATE "unbonding_delegations" SET "initial_balance_text" = CASE WHEN "initial_balance" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("initial_balance") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("initial_balance"))
+    WHERE "initial_balance" IS NOT NULL;
+UPDATE "unbonding_delegations" SET "initial_balance_text" = 'n' || printf('%016d', 9999999999999999 - substr("initial_balance_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("initial_balance_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("initial_balance_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("initial_balance_text", 50, 16))
+    WHERE "initial_balance_text" LIKE 'n%';
+UPDATE "unbonding_delegations" SET "balance_text" = CASE WHEN "balance" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("balance") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("balance"))
+    WHERE "balance" IS NOT NULL;
+UPDATE "unbonding_delegations" SET "balance_text" = 'n' || printf('%016d', 9999999999999999 - substr("balance_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("balance_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("balance_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("balance_text", 50, 16))
+    WHERE "balance_text" LIKE 'n%';
+ALTER TABLE "unbonding_delegations" DROP COLUMN "initial_balance";
+ALTER TABLE "unbonding_delegations" DROP COLUMN "balance";
+ALTER TABLE "unbonding_delegations" RENAME COLUMN "initial_balance_text" TO "initial_balance";
+ALTER TABLE "unbonding_delegations" RENAME COLUMN "balance_text" TO "balance";
+
+ALTER TABLE "redelegations" ADD COLUMN "amount_text" text;
+UPDATE "redelegations" SET "amount_text" = CASE WHEN "amount" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("amount") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("amount"))
+    WHERE "amount" IS NOT NULL;
+UPDATE "redelegations" SET "amount_text" = 'n' || printf('%016d', 9999999999999999 - substr("amount_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("amount_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("amount_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("amount_text", 50, 16))
+    WHERE "amount_text" LIKE 'n%';
+ALTER TABLE "redelegations" DROP COLUMN "amount";
+ALTER TABLE "redelegations" RENAME COLUMN "amount_text" TO "amount";
+
+ALTER TABLE "validator_snapshots" ADD COLUMN "tokens_text" text;
+ALTER TABLE "validator_snapshots" ADD COLUMN "delegator_shares_text" text;
+ALTER TABLE "validator_snapshots" ADD COLUMN "commission_rate_text" text;
+ALTER TABLE "validator_snapshots" ADD COLUMN "commission_max_rate_text" text;
+ALTER TABLE "validator_snapshots" ADD COLUMN "commission_max_change_rate_text" text;
+UPDATE "validator_snapshots" SET "tokens_text" = CASE WHEN "tokens" < 0 THEN 'n' ELSE 'p' END || printf(CASE typeof("tokens") WHEN 'integer' THEN '%064d' ELSE '%064.0f' END, abs("tokens"))
+    WHERE "tokens" IS NOT NULL;
+UPDATE "validator_snapshots" SET "tokens_text" = 'n' || printf('%016d', 9999999999999999 - substr("tokens_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("tokens_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("tokens_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("tokens_text", 50, 16))
+    WHERE "tokens_text" LIKE 'n%';
+UPDATE "validator_snapshots" SET "delegator_shares_text" = CASE WHEN "delegator_shares" < 0 THEN 'n' ELSE 'p' END || CASE
+        WHEN typeof("delegator_shares") = 'integer' THEN printf('%046d', abs("delegator_shares")) || '000000000000000000'
+        WHEN instr(CAST(abs("delegator_shares") AS TEXT), 'e') = 0 THEN printf('%046d', substr(CAST(abs("delegator_shares") AS TEXT), 1, instr(CAST(abs("delegator_shares") AS TEXT), '.') - 1))
+            || substr(substr(CAST(abs("delegator_shares") AS TEXT), instr(CAST(abs("delegator_shares") AS TEXT), '.') + 1) || '000000000000000000', 1, 18)
+        ELSE replace(printf('%065.18f', abs("delegator_shares")), '.', '')
+    END
+    WHERE "delegator_shares" IS NOT NULL;
+UPDATE "validator_snapshots" SET "delegator_shares_text" = 'n' || printf('%016d', 9999999999999999 - substr("delegator_shares_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("delegator_shares_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("delegator_shares_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("delegator_shares_text", 50, 16))
+    WHERE "delegator_shares_text" LIKE 'n%';
+UPDATE "validator_snapshots" SET "commission_rate_text" = CASE WHEN "commission_rate" < 0 THEN 'n' ELSE 'p' END || CASE
+        WHEN typeof("commission_rate") = 'integer' THEN printf('%046d', abs("commission_rate")) || '000000000000000000'
+        WHEN instr(CAST(abs("commission_rate") AS TEXT), 'e') = 0 THEN printf('%046d', substr(CAST(abs("commission_rate") AS TEXT), 1, instr(CAST(abs("commission_rate") AS TEXT), '.') - 1))
+            || substr(substr(CAST(abs("commission_rate") AS TEXT), instr(CAST(abs("commission_rate") AS TEXT), '.') + 1) || '000000000000000000', 1, 18)
+        ELSE replace(printf('%065.18f', abs("commission_rate")), '.', '')
+    END
+    WHERE "commission_rate" IS NOT NULL;
+UPDATE "validator_snapshots" SET "commission_rate_text" = 'n' || printf('%016d', 9999999999999999 - substr("commission_rate_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_rate_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_rate_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_rate_text", 50, 16))
+    WHERE "commission_rate_text" LIKE 'n%';
+UPDATE "validator_snapshots" SET "commission_max_rate_text" = CASE WHEN "commission_max_rate" < 0 THEN 'n' ELSE 'p' END || CASE
+        WHEN typeof("commission_max_rate") = 'integer' THEN printf('%046d', abs("commission_max_rate")) || '000000000000000000'
+        WHEN instr(CAST(abs("commission_max_rate") AS TEXT), 'e') = 0 THEN printf('%046d', substr(CAST(abs("commission_max_rate") AS TEXT), 1, instr(CAST(abs("commission_max_rate") AS TEXT), '.') - 1))
+            || substr(substr(CAST(abs("commission_max_rate") AS TEXT), instr(CAST(abs("commission_max_rate") AS TEXT), '.') + 1) || '000000000000000000', 1, 18)
+        ELSE replace(printf('%065.18f', abs("commission_max_rate")), '.', '')
+    END
+    WHERE "commission_max_rate" IS NOT NULL;
+UPDATE "validator_snapshots" SET "commission_max_rate_text" = 'n' || printf('%016d', 9999999999999999 - substr("commission_max_rate_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_max_rate_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_max_rate_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_max_rate_text", 50, 16))
+    WHERE "commission_max_rate_text" LIKE 'n%';
+UPDATE "validator_snapshots" SET "commission_max_change_rate_text" = CASE WHEN "commission_max_change_rate" < 0 THEN 'n' ELSE 'p' END || CASE
+        WHEN typeof("commission_max_change_rate") = 'integer' THEN printf('%046d', abs("commission_max_change_rate")) || '000000000000000000'
+        WHEN instr(CAST(abs("commission_max_change_rate") AS TEXT), 'e') = 0 THEN printf('%046d', substr(CAST(abs("commission_max_change_rate") AS TEXT), 1, instr(CAST(abs("commission_max_change_rate") AS TEXT), '.') - 1))
+            || substr(substr(CAST(abs("commission_max_change_rate") AS TEXT), instr(CAST(abs("commission_max_change_rate") AS TEXT), '.') + 1) || '000000000000000000', 1, 18)
+        ELSE replace(printf('%065.18f', abs("commission_max_change_rate")), '.', '')
+    END
+    WHERE "commission_max_change_rate" IS NOT NULL;
+UPDATE "validator_snapshots" SET "commission_max_change_rate_text" = 'n' || printf('%016d', 9999999999999999 - substr("commission_max_change_rate_text", 2, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_max_change_rate_text", 18, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_max_change_rate_text", 34, 16))
+    || printf('%016d', 9999999999999999 - substr("commission_max_change_rate_text", 50, 16))
+    WHERE "commission_max_change_rate_text" LIKE 'n%';
+ALTER TABLE "validator_snapshots" DROP COLUMN "tokens";
+ALTER TABLE "validator_snapshots" DROP COLUMN "delegator_shares";
+ALTER TABLE "validator_snapshots" DROP COLUMN "commission_rate";
+ALTER TABLE "validator_snapshots" DROP COLUMN "commission_max_rate";
+ALTER TABLE "validator_snapshots" DROP COLUMN "commission_max_change_rate";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "tokens_text" TO "tokens";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "delegator_shares_text" TO "delegator_shares";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "commission_rate_text" TO "commission_rate";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "commission_max_rate_text" TO "commission_max_rate";
+ALTER TABLE "validator_snapshots" RENAME COLUMN "commission_max_change_rate_text" TO "commission_max_change_rate";
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"time"
+
+	"cosmos-tracker/pkg/numeric"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// default database file when DB_DRIVER=sqlite and SQLITE_PATH is unset
+const DefaultSQLitePath = "cosmos-tracker.db"
+
+// returns the SQLite database file from SQLITE_PATH; ":memory:" keeps everything in memory
+func SQLitePath() string {
+	if path := os.Getenv("SQLITE_PATH"); path != "" {
+		return path
+	}
+	return DefaultSQLitePath
+}
+
+// opens a SQLite file or in-memory database with foreign keys enforced like on Postgres
+func openSQLite(path string, gormConfig *gorm.Config) (*gorm.DB, error) {
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)"
+	if path != ":memory:" {
+		dsn += "&_pragma=journal_mode(WAL)"
+	}
+
+	sqlDB, err := sql.Open(sqlite.DriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows one writer at a time, and every connection to ":memory:" is a separate
+	// database, so all work shares a single connection that is never recycled
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+
+	database, err := gorm.Open(&sqlite.Dialector{Conn: sqliteConnPool{sqlDB}}, gormConfig)
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return database, nil
+}
+
+// sqliteConnPool converts arguments into forms SQLite stores and compares correctly as text. Times are
+// passed in UTC, since times written in different zones, such as collection timestamps and day
+// boundaries in the aggregation timezone, would otherwise not order correctly. Amounts are passed as
+// sortable strings, since SQLite keeps numbers beyond 64-bit integers only as lossy floats.
+type sqliteConnPool struct {
+	gorm.ConnPool // *sql.DB, or *sql.Tx inside a transaction
+}
+
+// converts time and amount arguments, leaving everything else untouched
+func sqliteArgs(args []interface{}) []interface{} {
+	converted := args
+	copied := false
+	for i, arg := range args {
+		value, ok := sqliteArg(arg)
+		if !ok {
+			continue
+		}
+		if !copied {
+			converted, copied = append([]interface{}(nil), args...), true
+		}
+		converted[i] = value
+	}
+	return converted
+}
+
+// returns the stored form of one argument, or false when it passes through as is
+func sqliteArg(arg interface{}) (interface{}, bool) {
+	switch v := arg.(type) {
+	case time.Time:
+		return v.UTC(), true
+	case *time.Time:
+		if v != nil {
+			return v.UTC(), true
+		}
+	case numeric.Int:
+		return v.SortableString(), true
+	case *numeric.Int:
+		if v != nil {
+			return v.SortableString(), true
+		}
+	case numeric.Dec:
+		return v.SortableString(), true
+	case *numeric.Dec:
+		if v != nil {
+			return v.SortableString(), true
+		}
+	}
+	return nil, false
+}
+
+func (p sqliteConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.ConnPool.ExecContext(ctx, query, sqliteArgs(args)...)
+}
+
+func (p sqliteConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.ConnPool.QueryContext(ctx, query, sqliteArgs(args)...)
+}
+
+func (p sqliteConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.ConnPool.QueryRowContext(ctx, query, sqliteArgs(args)...)
+}
+
+// starts a transaction whose statements are converted the same way
+func (p sqliteConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := p.ConnPool.(*sql.DB).BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteTx{sqliteConnPool{tx}, tx}, nil
+}
+
+// exposes the underlying pool to gorm's DB()
+func (p sqliteConnPool) GetDBConn() (*sql.DB, error) {
+	sqlDB, _ := p.ConnPool.(*sql.DB)
+	return sqlDB, nil
+}
+
+// sqliteTx is a transaction started through sqliteConnPool
+type sqliteTx struct {
+	sqliteConnPool
+	tx *sql.Tx
+}
+
+func (t *sqliteTx) Commit() error   { return t.tx.Commit() }
+func (t *sqliteTx) Rollback() error { return t.tx.Rollback() }
@@ -0,0 +1,62 @@
+-- Schema and sample rows of a database created by the AutoMigrate boot path of the first release.
+
+CREATE TABLE "migration_histories" (
+    "id" bigserial,
+    "migrated_at" timestamptz,
+    "models" text,
+    "status" varchar(50),
+    "error_message" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_migration_histories_migrated_at" ON "migration_histories" ("migrated_at");
+CREATE INDEX "idx_migration_histories_status" ON "migration_histories" ("status");
+
+CREATE TABLE "watchlists" (
+    "id" bigserial,
+    "validator_address" text,
+    "validator_name" varchar(100),
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_watchlists_validator_address" ON "watchlists" ("validator_address");
+
+CREATE TABLE "hourly_delegations" (
+    "id" bigserial,
+    "watchlist_id" bigint,
+    "validator_address" text,
+    "delegator_address" text,
+    "delegation_amount" bigint,
+    "change_amount" bigint,
+    "shares" decimal,
+    "timestamp" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_hourly_delegations_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+);
+CREATE INDEX "idx_hourly_delegations_watchlist_id" ON "hourly_delegations" ("watchlist_id");
+CREATE INDEX "idx_hourly_delegations_validator_address" ON "hourly_delegations" ("validator_address");
+CREATE INDEX "idx_hourly_delegations_delegator_address" ON "hourly_delegations" ("delegator_address");
+CREATE INDEX "idx_hourly_delegations_timestamp" ON "hourly_delegations" ("timestamp");
+
+CREATE TABLE "daily_delegations" (
+    "id" bigserial,
+    "watchlist_id" bigint,
+    "validator_address" text,
+    "delegator_address" text,
+    "total_delegation" bigint,
+    "total_shares" decimal,
+    "date" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_daily_delegations_watchlist" FOREIGN KEY ("watchlist_id") REFERENCES "watchlists"("id")
+);
+CREATE INDEX "idx_daily_delegations_watchlist_id" ON "daily_delegations" ("watchlist_id");
+CREATE INDEX "idx_daily_delegations_validator_address" ON "daily_delegations" ("validator_address");
+CREATE INDEX "idx_daily_delegations_delegator_address" ON "daily_delegations" ("delegator_address");
+CREATE INDEX "idx_daily_delegations_date" ON "daily_delegations" ("date");
+
+INSERT INTO "migration_histories" ("migrated_at", "models", "status")
+VALUES ('2024-03-01 09:00:00+00', 'Watchlist, HourlyDelegation, DailyDelegation', 'success');
+INSERT INTO "watchlists" ("validator_address", "validator_name")
+VALUES ('cosmosvaloper1baseline', 'Baseline');
+INSERT INTO "hourly_delegations" ("watchlist_id", "validator_address", "delegator_address", "delegation_amount", "change_amount", "shares", "timestamp")
+VALUES (1, 'cosmosvaloper1baseline', 'cosmos1alice', 1500000000, 500000000, 1500000000.5, '2024-03-01 10:00:00+00');
+INSERT INTO "daily_delegations" ("watchlist_id", "validator_address", "delegator_address", "total_delegation", "total_shares", "date")
+VALUES (1, 'cosmosvaloper1baseline', 'cosmos1alice', 1500000000, 1500000000.5, '2024-03-01 00:00:00+00');
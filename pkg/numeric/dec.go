@@ -0,0 +1,170 @@
+package numeric
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Number of fractional digits carried by SDK decimals such as delegation shares
+const Precision = 18
+
+var precisionMultiplier = new(big.Int).Exp(big.NewInt(10), big.NewInt(Precision), nil)
+
+// Dec is a fixed-point decimal with 18 fractional digits, matching the SDK's
+// LegacyDec. It is stored in a numeric column and encoded in JSON as a string.
+type Dec struct {
+	i *big.Int // value scaled by 10^18; nil means zero
+}
+
+// parses a decimal string such as "1234.500000000000000000"
+func ParseDec(s string) (Dec, error) {
+	if s == "" {
+		return Dec{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(s, "-")
+
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	if len(fraction) > Precision {
+		return Dec{}, fmt.Errorf("decimal %q has more than %d fractional digits", s, Precision)
+	}
+	fraction += strings.Repeat("0", Precision-len(fraction))
+
+	scaled, ok := new(big.Int).SetString(whole+fraction, 10)
+	if !ok || strings.ContainsAny(whole+fraction, "+-") {
+		return Dec{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	if negative {
+		scaled.Neg(scaled)
+	}
+	return Dec{i: scaled}, nil
+}
+
+// returns the scaled value as a new big.Int
+func (x Dec) scaled() *big.Int {
+	if x.i == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(x.i)
+}
+
+// returns x + y
+func (x Dec) Add(y Dec) Dec {
+	return Dec{i: new(big.Int).Add(x.scaled(), y.scaled())}
+}
+
+// returns x - y
+func (x Dec) Sub(y Dec) Dec {
+	return Dec{i: new(big.Int).Sub(x.scaled(), y.scaled())}
+}
+
+// compares x and y, returning -1, 0 or +1
+func (x Dec) Cmp(y Dec) int {
+	return x.scaled().Cmp(y.scaled())
+}
+
+// reports whether x is zero
+func (x Dec) IsZero() bool {
+	return x.scaled().Sign() == 0
+}
+
+// drops the fractional part
+func (x Dec) TruncateInt() Int {
+	return Int{i: new(big.Int).Quo(x.scaled(), precisionMultiplier)}
+}
+
+// returns a float approximation, for logging and ratios only
+func (x Dec) Float64() float64 {
+	f, _ := strconv.ParseFloat(x.String(), 64)
+	return f
+}
+
+// returns the exact representation with all 18 fractional digits
+func (x Dec) String() string {
+	v := x.scaled()
+	sign := ""
+	if v.Sign() < 0 {
+		sign = "-"
+		v.Neg(v)
+	}
+
+	digits := v.String()
+	if len(digits) <= Precision {
+		digits = strings.Repeat("0", Precision-len(digits)+1) + digits
+	}
+	point := len(digits) - Precision
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// encodes the value as a JSON string so clients never lose precision
+func (x Dec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(x.String())
+}
+
+// accepts either a JSON string or a JSON number
+func (x *Dec) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+	parsed, err := ParseDec(s)
+	if err != nil {
+		return err
+	}
+	*x = parsed
+	return nil
+}
+
+// reads a numeric column from any driver representation
+func (x *Dec) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*x = Dec{}
+		return nil
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64) // lossy drivers only
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("cannot scan %T into numeric.Dec", src)
+	}
+
+	if v, ok := parseSortable(s); ok {
+		*x = Dec{i: v}
+		return nil
+	}
+	parsed, err := ParseDec(s)
+	if err != nil {
+		return err
+	}
+	*x = parsed
+	return nil
+}
+
+// writes the exact decimal string
+func (x Dec) Value() (driver.Value, error) {
+	return x.String(), nil
+}
+
+// returns text that sorts in numeric order, as stored in SQLite's text columns
+func (x Dec) SortableString() string {
+	return sortableString(x.scaled())
+}
+
+// declares the column type used by AutoMigrate
+func (Dec) GormDataType() string {
+	return "numeric"
+}
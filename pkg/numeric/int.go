@@ -0,0 +1,183 @@
+package numeric
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Int is an arbitrary-precision integer for token amounts. It is stored in a
+// numeric column and encoded in JSON as an exact decimal string.
+type Int struct {
+	i *big.Int // nil means zero; never mutated after construction
+}
+
+// creates an Int from an int64
+func NewInt(v int64) Int {
+	return Int{i: big.NewInt(v)}
+}
+
+// creates an Int from a big.Int, copying it
+func NewIntFromBig(v *big.Int) Int {
+	if v == nil {
+		return Int{}
+	}
+	return Int{i: new(big.Int).Set(v)}
+}
+
+// parses a base-10 integer string such as an SDK coin amount
+func ParseInt(s string) (Int, error) {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Int{}, fmt.Errorf("invalid integer %q", s)
+	}
+	return Int{i: v}, nil
+}
+
+// returns the value as a new big.Int
+func (x Int) BigInt() *big.Int {
+	if x.i == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(x.i)
+}
+
+// returns x + y
+func (x Int) Add(y Int) Int {
+	return Int{i: new(big.Int).Add(x.BigInt(), y.BigInt())}
+}
+
+// returns x - y
+func (x Int) Sub(y Int) Int {
+	return Int{i: new(big.Int).Sub(x.BigInt(), y.BigInt())}
+}
+
+// returns x * n
+func (x Int) MulInt64(n int64) Int {
+	return Int{i: new(big.Int).Mul(x.BigInt(), big.NewInt(n))}
+}
+
+// returns x truncated by 10^n, e.g. to turn base units into display units
+func (x Int) QuoPow10(n int) Int {
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	return Int{i: new(big.Int).Quo(x.BigInt(), divisor)}
+}
+
+// returns x / n truncated toward zero
+func (x Int) QuoInt64(n int64) Int {
+	return Int{i: new(big.Int).Quo(x.BigInt(), big.NewInt(n))}
+}
+
+// returns |x|
+func (x Int) Abs() Int {
+	return Int{i: new(big.Int).Abs(x.BigInt())}
+}
+
+// returns -x
+func (x Int) Neg() Int {
+	return Int{i: new(big.Int).Neg(x.BigInt())}
+}
+
+// compares x and y, returning -1, 0 or +1
+func (x Int) Cmp(y Int) int {
+	return x.BigInt().Cmp(y.BigInt())
+}
+
+// returns -1, 0 or +1 depending on the sign of x
+func (x Int) Sign() int {
+	if x.i == nil {
+		return 0
+	}
+	return x.i.Sign()
+}
+
+// reports whether x is zero
+func (x Int) IsZero() bool {
+	return x.Sign() == 0
+}
+
+// returns a float approximation, for logging and ratios only
+func (x Int) Float64() float64 {
+	f, _ := new(big.Float).SetInt(x.BigInt()).Float64()
+	return f
+}
+
+// returns the exact base-10 representation
+func (x Int) String() string {
+	return x.BigInt().String()
+}
+
+// encodes the value as a JSON string so clients never lose precision
+func (x Int) MarshalJSON() ([]byte, error) {
+	return json.Marshal(x.String())
+}
+
+// accepts either a JSON string or a JSON number
+func (x *Int) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+	parsed, err := ParseInt(s)
+	if err != nil {
+		return err
+	}
+	*x = parsed
+	return nil
+}
+
+// reads a numeric column from any driver representation
+func (x *Int) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*x = Int{}
+		return nil
+	case int64:
+		*x = NewInt(v)
+		return nil
+	case float64:
+		truncated, _ := new(big.Float).SetFloat64(v).Int(nil) // lossy drivers only
+		*x = NewIntFromBig(truncated)
+		return nil
+	case []byte:
+		return x.scanString(string(v))
+	case string:
+		return x.scanString(v)
+	default:
+		return fmt.Errorf("cannot scan %T into numeric.Int", src)
+	}
+}
+
+// parses a scanned string, tolerating a zero fractional part such as "12.000"
+func (x *Int) scanString(s string) error {
+	if v, ok := parseSortable(s); ok {
+		*x = Int{i: v}
+		return nil
+	}
+	if parsed, err := ParseInt(s); err == nil {
+		*x = parsed
+		return nil
+	}
+	d, err := ParseDec(s)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into numeric.Int", s)
+	}
+	*x = d.TruncateInt()
+	return nil
+}
+
+// writes the exact decimal string
+func (x Int) Value() (driver.Value, error) {
+	return x.String(), nil
+}
+
+// returns text that sorts in numeric order, as stored in SQLite's text columns
+func (x Int) SortableString() string {
+	return sortableString(x.BigInt())
+}
+
+// declares the column type used by AutoMigrate
+func (Int) GormDataType() string {
+	return "numeric"
+}
@@ -0,0 +1,104 @@
+package numeric
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntBeyondInt64(t *testing.T) {
+	// 10^30 base units, larger than any int64 and exact only as a big integer
+	x, err := ParseInt("1000000000000000000000000000000")
+	require.NoError(t, err)
+
+	sum := x.Add(NewInt(1))
+	assert.Equal(t, "1000000000000000000000000000001", sum.String())
+	assert.Equal(t, "-1", x.Sub(sum).String())
+	assert.Equal(t, 1, sum.Cmp(x))
+	assert.Equal(t, "1000000000000000000000000", x.QuoPow10(6).String())
+	assert.Equal(t, "1000000000000000000000000", x.QuoInt64(1_000_000).String())
+
+	_, err = ParseInt("12abc")
+	assert.Error(t, err)
+}
+
+func TestIntJSONAndScan(t *testing.T) {
+	x, _ := ParseInt("123456789012345678901234567890")
+
+	data, err := json.Marshal(x)
+	require.NoError(t, err)
+	assert.Equal(t, `"123456789012345678901234567890"`, string(data))
+
+	var decoded Int
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 0, decoded.Cmp(x))
+	require.NoError(t, json.Unmarshal([]byte("42"), &decoded))
+	assert.Equal(t, "42", decoded.String())
+
+	var scanned Int
+	require.NoError(t, scanned.Scan([]byte("123456789012345678901234567890")))
+	assert.Equal(t, 0, scanned.Cmp(x))
+	require.NoError(t, scanned.Scan("500.000000000000000000"))
+	assert.Equal(t, "500", scanned.String())
+	require.NoError(t, scanned.Scan(nil))
+	assert.True(t, scanned.IsZero())
+}
+
+func TestDecRoundTrip(t *testing.T) {
+	d, err := ParseDec("1234567890123456789012.123456789012345678")
+	require.NoError(t, err)
+	assert.Equal(t, "1234567890123456789012.123456789012345678", d.String())
+	assert.Equal(t, "1234567890123456789012", d.TruncateInt().String())
+
+	short, err := ParseDec("-0.5")
+	require.NoError(t, err)
+	assert.Equal(t, "-0.500000000000000000", short.String())
+	assert.Equal(t, "0.000000000000000000", Dec{}.String())
+
+	_, err = ParseDec("1.1234567890123456789")
+	assert.Error(t, err)
+	_, err = ParseDec("1.-5")
+	assert.Error(t, err)
+
+	var scanned Dec
+	require.NoError(t, scanned.Scan("42"))
+	assert.Equal(t, "42.000000000000000000", scanned.String())
+}
+
+func TestSortableStringsOrderLikeTheNumbers(t *testing.T) {
+	var values []Int
+	for _, s := range []string{"-123456789012345678901234567", "-1000", "-1", "0", "1", "999", "1000", "123456789012345678901234567"} {
+		v, err := ParseInt(s)
+		require.NoError(t, err)
+		values = append(values, v)
+	}
+
+	encoded := make([]string, len(values))
+	for i, v := range values {
+		encoded[i] = v.SortableString()
+		assert.Len(t, encoded[i], 65)
+
+		var scanned Int
+		require.NoError(t, scanned.Scan(encoded[i]))
+		assert.Equal(t, v.String(), scanned.String())
+	}
+	assert.True(t, sort.StringsAreSorted(encoded), "%v", encoded)
+
+	shares, _ := ParseDec("-123456789012345678901234567.123456789012345678")
+	var scanned Dec
+	require.NoError(t, scanned.Scan([]byte(shares.SortableString())))
+	assert.Equal(t, shares.String(), scanned.String())
+	assert.Less(t, shares.SortableString(), Dec{}.SortableString())
+
+	// beyond 64 digits the value is still exact
+	huge := NewInt(1).MulInt64(-7)
+	for range 70 {
+		huge = huge.MulInt64(10)
+	}
+	var decoded Int
+	require.NoError(t, decoded.Scan(huge.SortableString()))
+	assert.Equal(t, huge.String(), decoded.String())
+}
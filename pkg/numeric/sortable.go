@@ -0,0 +1,51 @@
+package numeric
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Minimum number of digits in a sortable string. Values below 10^64 compare as text
+// in numeric order; larger ones still round-trip exactly but sort by length first.
+const sortableDigits = 64
+
+// encodes v as text that orders like the number itself, for databases without an
+// exact numeric type. Values >= 0 become "p" and their zero-padded digits, negative
+// values "n" and the nines' complement of the padded digits of |v|, so that larger
+// magnitudes sort first.
+func sortableString(v *big.Int) string {
+	digits := new(big.Int).Abs(v).String()
+	width := max(sortableDigits, len(digits))
+	if v.Sign() >= 0 {
+		return "p" + strings.Repeat("0", width-len(digits)) + digits
+	}
+
+	complement := new(big.Int).Add(nines(width), v).String()
+	return "n" + strings.Repeat("0", width-len(complement)) + complement
+}
+
+// decodes a string written by sortableString; ok is false for any other text
+func parseSortable(s string) (v *big.Int, ok bool) {
+	if len(s) < 2 || (s[0] != 'p' && s[0] != 'n') {
+		return nil, false
+	}
+	digits := s[1:]
+	if strings.Trim(digits, "0123456789") != "" {
+		return nil, false
+	}
+
+	v, ok = new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, false
+	}
+	if s[0] == 'n' {
+		v.Sub(v, nines(len(digits)))
+	}
+	return v, true
+}
+
+// returns 10^width - 1
+func nines(width int) *big.Int {
+	n := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(width)), nil)
+	return n.Sub(n, big.NewInt(1))
+}